@@ -0,0 +1,135 @@
+package ghamon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// actionsAppSlug identifies check runs created by GitHub Actions, which are
+// already shown as workflow runs.
+const actionsAppSlug = "github-actions"
+
+// CommitCheck is a commit status or a third-party check run reported on a
+// commit, e.g. by an external CI service.
+type CommitCheck struct {
+	Name       string
+	Status     string
+	Conclusion string
+	StartedAt  time.Time
+	UpdatedAt  time.Time
+	SHA        string // the commit checked
+}
+
+type repositoryResponse struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+type combinedStatusResponse struct {
+	SHA      string `json:"sha"`
+	Statuses []struct {
+		Context   string    `json:"context"`
+		State     string    `json:"state"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	} `json:"statuses"`
+}
+
+type checkRunsResponse struct {
+	CheckRuns []struct {
+		Name        string    `json:"name"`
+		HeadSHA     string    `json:"head_sha"`
+		Status      string    `json:"status"`
+		Conclusion  string    `json:"conclusion"`
+		StartedAt   time.Time `json:"started_at"`
+		CompletedAt time.Time `json:"completed_at"`
+		App         struct {
+			Slug string `json:"slug"`
+			Name string `json:"name"`
+		} `json:"app"`
+	} `json:"check_runs"`
+}
+
+// defaultBranchRetry is how long a default branch that could not be fetched
+// is not asked for again, so that a repository whose details cannot be read
+// does not cost a request on every refresh.
+const defaultBranchRetry = 5 * time.Minute
+
+// cachedBranch is a default branch as fetched, or why it could not be.
+type cachedBranch struct {
+	name      string
+	err       error
+	fetchedAt time.Time
+}
+
+// FetchDefaultBranch returns the default branch of a repository. The result
+// is cached for the lifetime of the client, and an error for
+// defaultBranchRetry; a cancelled or timed-out lookup is not cached.
+func (c *GitHubClient) FetchDefaultBranch(ctx context.Context, repo string) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.defaultBranches[repo]
+	c.mu.Unlock()
+	if ok && (cached.err == nil || time.Since(cached.fetchedAt) < defaultBranchRetry) {
+		return cached.name, cached.err
+	}
+
+	var result repositoryResponse
+	err := c.get(ctx, fmt.Sprintf("%s/repos/%s", c.BaseURL, repo), repo, &result)
+	if err != nil && ctx.Err() != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	if c.defaultBranches == nil {
+		c.defaultBranches = make(map[string]cachedBranch)
+	}
+	c.defaultBranches[repo] = cachedBranch{name: result.DefaultBranch, err: err, fetchedAt: time.Now()}
+	c.mu.Unlock()
+	return result.DefaultBranch, err
+}
+
+// FetchCommitChecks fetches the commit statuses and the check runs of apps
+// other than GitHub Actions for the head of ref. Statuses are named by their
+// context, check runs by their app and check name.
+func (c *GitHubClient) FetchCommitChecks(ctx context.Context, repo, ref string) ([]CommitCheck, error) {
+	ref = url.PathEscape(ref)
+
+	var statuses combinedStatusResponse
+	if err := c.get(ctx, fmt.Sprintf("%s/repos/%s/commits/%s/status?per_page=100", c.BaseURL, repo, ref), repo, &statuses); err != nil {
+		return nil, err
+	}
+	var checks []CommitCheck
+	for _, s := range statuses.Statuses {
+		check := CommitCheck{Name: s.Context, StartedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt, SHA: statuses.SHA}
+		if s.State == "pending" {
+			check.Status = "pending"
+		} else {
+			check.Status, check.Conclusion = "completed", s.State
+		}
+		checks = append(checks, check)
+	}
+
+	var runs checkRunsResponse
+	if err := c.get(ctx, fmt.Sprintf("%s/repos/%s/commits/%s/check-runs?per_page=100", c.BaseURL, repo, ref), repo, &runs); err != nil {
+		return nil, err
+	}
+	for _, r := range runs.CheckRuns {
+		if r.App.Slug == actionsAppSlug {
+			continue
+		}
+		updated := r.CompletedAt
+		if updated.IsZero() {
+			updated = r.StartedAt
+		}
+		checks = append(checks, CommitCheck{
+			Name:       r.App.Name + ": " + r.Name,
+			Status:     r.Status,
+			Conclusion: r.Conclusion,
+			StartedAt:  r.StartedAt,
+			UpdatedAt:  updated,
+			SHA:        r.HeadSHA,
+		})
+	}
+	return checks, nil
+}
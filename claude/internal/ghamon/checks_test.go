@@ -0,0 +1,125 @@
+package ghamon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchDefaultBranch(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/repos/owner/repo", r.URL.Path)
+		w.Write([]byte(`{"default_branch": "main"}`))
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
+	for range 2 {
+		branch, err := client.FetchDefaultBranch(context.Background(), "owner/repo")
+		require.NoError(t, err)
+		assert.Equal(t, "main", branch)
+	}
+	assert.Equal(t, 1, calls, "the default branch is cached")
+}
+
+func TestFetchDefaultBranch_Error(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
+	for range 2 {
+		_, err := client.FetchDefaultBranch(context.Background(), "owner/repo")
+		assert.ErrorContains(t, err, "404")
+	}
+	assert.Equal(t, 1, calls, "a 404 is not asked again within the retry window")
+
+	// Once the window is over, the branch is looked up again.
+	cached := client.defaultBranches["owner/repo"]
+	cached.fetchedAt = cached.fetchedAt.Add(-defaultBranchRetry)
+	client.defaultBranches["owner/repo"] = cached
+	_, err := client.FetchDefaultBranch(context.Background(), "owner/repo")
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFetchCommitChecks(t *testing.T) {
+	t.Run("returns statuses and third-party check runs", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/commits/main/status", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"statuses": [
+				{"context": "ci/jenkins", "state": "failure", "created_at": "2024-05-01T12:00:00Z", "updated_at": "2024-05-01T12:05:00Z"},
+				{"context": "deploy/preview", "state": "pending"}
+			]}`))
+		})
+		mux.HandleFunc("/repos/owner/repo/commits/main/check-runs", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"check_runs": [
+				{"name": "build", "status": "completed", "conclusion": "success", "app": {"slug": "buildkite", "name": "Buildkite"},
+				 "started_at": "2024-05-01T12:00:00Z", "completed_at": "2024-05-01T12:03:00Z"},
+				{"name": "test", "status": "in_progress", "app": {"slug": "github-actions", "name": "GitHub Actions"}}
+			]}`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
+		checks, err := client.FetchCommitChecks(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		require.Len(t, checks, 3)
+
+		assert.Equal(t, "ci/jenkins", checks[0].Name)
+		assert.Equal(t, "failure", formatStatus(checks[0].Status, checks[0].Conclusion))
+		assert.Equal(t, "pending", formatStatus(checks[1].Status, checks[1].Conclusion))
+		assert.Equal(t, "Buildkite: build", checks[2].Name)
+		assert.Equal(t, "success", formatStatus(checks[2].Status, checks[2].Conclusion))
+		assert.Equal(t, 3*60.0, checks[2].UpdatedAt.Sub(checks[2].StartedAt).Seconds())
+	})
+
+	t.Run("returns error on non-200 status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
+		_, err := client.FetchCommitChecks(context.Background(), "owner/repo", "main")
+		assert.Error(t, err)
+	})
+}
+
+func TestFetchRepo_ChecksErrorKeepsWorkflows(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"workflow_runs": [{"name": "CI", "status": "completed", "conclusion": "success", "head_branch": "main"}]}`))
+	})
+	mux.HandleFunc("/repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"default_branch": "main"}`))
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/main/status", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	m := newModel(Options{Repos: []string{"owner/repo"}, Rate: 30, Checks: true}, DefaultSettings())
+	m.client = &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
+	msg := m.fetchRepo(0, true)().(fetchedRepoMsg)
+
+	require.Len(t, msg.infos, 2, "the workflow row and one error row for the checks")
+	assert.Equal(t, "CI", msg.infos[0].Workflow)
+	assert.Equal(t, "success", msg.infos[0].Status)
+	assert.Equal(t, "checks", msg.infos[1].Workflow)
+	require.NotNil(t, msg.infos[1].Err)
+	assert.Equal(t, http.StatusForbidden, msg.infos[1].Err.StatusCode)
+}
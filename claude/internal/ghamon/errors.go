@@ -0,0 +1,185 @@
+package ghamon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchError describes why a repository or workflow could not be fetched.
+type FetchError struct {
+	// StatusCode is the HTTP status of GitHub's response, or zero if no
+	// response was received, e.g. on a network error.
+	StatusCode int
+	Message    string
+	// Retryable is set if the same request may succeed later, e.g. after a
+	// server error or rate limit, rather than needing a fix such as a new
+	// token.
+	Retryable bool
+
+	// retryAfter is how long a 429 response asked to wait before retrying.
+	retryAfter time.Duration
+}
+
+// Error returns the status and message, e.g. "404 Not Found".
+func (e *FetchError) Error() string {
+	if e.StatusCode == 0 {
+		return e.Message
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, e.Message)
+}
+
+// responseError returns the FetchError for a response other than 200 OK,
+// taking the message from GitHub's JSON error body if there is one.
+func responseError(resp *http.Response, body []byte) *FetchError {
+	e := &FetchError{StatusCode: resp.StatusCode}
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil {
+		e.Message = apiErr.Message
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Retryable = true
+		e.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	case resp.StatusCode >= 500:
+		e.Retryable = true
+	case resp.StatusCode == http.StatusForbidden:
+		// GitHub answers 403 when a rate limit is exceeded.
+		e.Retryable = resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			strings.Contains(strings.ToLower(e.Message), "rate limit")
+	}
+	return e
+}
+
+// asFetchError describes err, as returned by a GitHubClient method. Errors
+// without a response are retryable unless the request was cancelled.
+func asFetchError(err error) *FetchError {
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		return fe
+	}
+	return &FetchError{Message: err.Error(), Retryable: !errors.Is(err, context.Canceled)}
+}
+
+// hint suggests what the error means for the user.
+func (e *FetchError) hint() string {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return "the token is missing, invalid or expired; check GITHUB_TOKEN"
+	case e.StatusCode == http.StatusForbidden && e.Retryable:
+		return "rate limited; retrying on the next refresh"
+	case e.StatusCode == http.StatusForbidden:
+		return "the token is not allowed to read this repository's Actions"
+	case e.StatusCode == http.StatusNotFound:
+		return "the repository or workflow does not exist, or the token cannot see it"
+	case e.StatusCode == http.StatusTooManyRequests:
+		return "rate limited; retrying on the next refresh"
+	case e.StatusCode >= 500:
+		return "GitHub had a server error; retrying on the next refresh"
+	case e.StatusCode == 0 && e.Retryable:
+		return "GitHub could not be reached; retrying on the next refresh"
+	}
+	return ""
+}
+
+// errorInfo returns the row standing in for what could not be fetched: a
+// whole repository if workflow is empty, else one of its workflows.
+func errorInfo(repo, workflow string, err error) workflowInfo {
+	return workflowInfo{Repo: repo, Workflow: workflow, Status: "error", Err: asFetchError(err)}
+}
+
+// errorSummary returns the inline text of an error row's workflow cell, e.g.
+// "checks: 404 Not Found".
+func errorSummary(r workflowInfo) string {
+	if r.Workflow == "" {
+		return r.Err.Error()
+	}
+	return r.Workflow + ": " + r.Err.Error()
+}
+
+// selectedError returns the error row under the cursor, if it is one.
+func (m model) selectedError() (workflowInfo, bool) {
+	rows := m.visibleRows()
+	if m.cursor >= len(rows) || rows[m.cursor].header || rows[m.cursor].info.Err == nil {
+		return workflowInfo{}, false
+	}
+	return rows[m.cursor].info, true
+}
+
+// selectRow acts on the selected row: an error row shows its details, and a
+// group header folds or unfolds its group.
+func (m *model) selectRow() {
+	if _, ok := m.selectedError(); ok {
+		m.showDetail = true
+		return
+	}
+	if m.grouped {
+		m.toggleGroup()
+	}
+}
+
+// updateDetail handles key presses while an error's details are shown.
+func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Clear, m.keys.Fold):
+		m.showDetail = false
+	}
+	return m, nil
+}
+
+// detailOverlay renders the details of the selected error row in place of
+// the table, with the footer at the bottom.
+func (m model) detailOverlay() string {
+	detail := m.errorDetail()
+	// The header and blank line above the overlay, and the footer below it.
+	if pad := m.windowHeight - m.headerLines() + 1 - strings.Count(detail, "\n") - footerLines; pad > 0 {
+		detail += strings.Repeat("\n", pad)
+	}
+	hint := fmt.Sprintf("%s/%s: close", m.keys.Fold.Help().Key, m.keys.Clear.Help().Key)
+	return detail + "\n" + footerStyle.Render(hint)
+}
+
+// errorDetail renders what could not be fetched, GitHub's answer and what it
+// means.
+func (m model) errorDetail() string {
+	r, ok := m.selectedError()
+	if !ok {
+		return "The error has cleared.\n"
+	}
+	e := r.Err
+	status := "no response"
+	if e.StatusCode != 0 {
+		status = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	retry := "no, it needs fixing"
+	if e.Retryable {
+		retry = "yes, on the next refresh"
+	}
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Error") + "\n\n")
+	b.WriteString(fmt.Sprintf("Repository:  %s\n", r.Repo))
+	if r.Workflow != "" {
+		b.WriteString(fmt.Sprintf("Workflow:    %s\n", r.Workflow))
+	}
+	b.WriteString(fmt.Sprintf("HTTP status: %s\n", status))
+	b.WriteString(fmt.Sprintf("Message:     %s\n", e.Message))
+	b.WriteString(fmt.Sprintf("Retried:     %s\n", retry))
+	if hint := e.hint(); hint != "" {
+		b.WriteString("\n" + footerStyle.Render(hint) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package ghamon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchErrorFromResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		header    string
+		body      string
+		retryable bool
+		text      string
+	}{
+		{"not found", http.StatusNotFound, "", `{"message": "Not Found"}`, false, "404 Not Found"},
+		{"bad token", http.StatusUnauthorized, "", `{"message": "Bad credentials"}`, false, "401 Bad credentials"},
+		{"forbidden", http.StatusForbidden, "", `{"message": "Resource not accessible"}`, false, "403 Resource not accessible"},
+		{"rate limited", http.StatusForbidden, "0", `{"message": "API rate limit exceeded"}`, true, "403 API rate limit exceeded"},
+		{"too many requests", http.StatusTooManyRequests, "", ``, true, "429 Too Many Requests"},
+		{"server error", http.StatusBadGateway, "", `<html>`, true, "502 Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != "" {
+					w.Header().Set("X-RateLimit-Remaining", tt.header)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &GitHubClient{HTTPClient: server.Client(), BaseURL: server.URL}
+			_, err := client.FetchWorkflowRuns(context.Background(), "owner/repo")
+			var fe *FetchError
+			require.ErrorAs(t, err, &fe)
+			assert.Equal(t, tt.status, fe.StatusCode)
+			assert.Equal(t, tt.retryable, fe.Retryable)
+			assert.Equal(t, tt.text, fe.Error())
+		})
+	}
+}
+
+func TestAsFetchError(t *testing.T) {
+	fe := asFetchError(errors.New("fetching owner/repo: connection refused"))
+	assert.Equal(t, 0, fe.StatusCode)
+	assert.True(t, fe.Retryable, "network errors are retryable")
+	assert.Contains(t, fe.hint(), "could not be reached")
+
+	assert.False(t, asFetchError(context.Canceled).Retryable)
+	assert.Contains(t, (&FetchError{StatusCode: 401}).hint(), "GITHUB_TOKEN")
+}
+
+func TestErrorRows(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/gone", "owner/ok"}, Rate: 30}, DefaultSettings())
+	m.windowWidth, m.windowHeight = 120, 30
+
+	tm, cmd := m.Update(fetchedRepoMsg{index: 0, infos: []workflowInfo{
+		errorInfo("owner/gone", "", &FetchError{StatusCode: 404, Message: "Not Found"}),
+	}})
+	require.NotNil(t, cmd, "the other repositories are still fetched")
+	assert.Equal(t, fetchNextMsg{index: 1}, cmd())
+	tm, _ = tm.Update(fetchedRepoMsg{index: 1, infos: []workflowInfo{
+		{Repo: "owner/ok", Workflow: "ci", Status: "success"},
+	}})
+	m = tm.(model)
+	assert.True(t, m.lastRefresh.IsZero(), "a refresh with errors is not complete")
+
+	v := m.View()
+	assert.Contains(t, v, "404 Not Found", "the error is shown inline")
+	assert.Contains(t, v, "owner/ok")
+	assert.Contains(t, v, "1 ok · 1 error")
+
+	m.setCursor(0)
+	tm, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	require.True(t, m.showDetail)
+	v = m.View()
+	assert.Contains(t, v, "HTTP status: 404 Not Found")
+	assert.Contains(t, v, "Retried:     no")
+	assert.Contains(t, v, "the token cannot see it")
+
+	tm, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, tm.(model).showDetail)
+}
+
+func TestRetryableErrorsKeepAdaptivePolling(t *testing.T) {
+	m := scheduleModel()
+	m.adaptive = true
+	m.runs[1] = []workflowInfo{errorInfo("owner/idle", "", &FetchError{StatusCode: 502, Retryable: true})}
+	assert.Equal(t, 30*time.Second, m.interval(1))
+	m.runs[1] = []workflowInfo{errorInfo("owner/idle", "", &FetchError{StatusCode: 404})}
+	assert.Equal(t, 5*time.Minute, m.interval(1))
+}
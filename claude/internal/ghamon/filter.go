@@ -0,0 +1,107 @@
+package ghamon
+
+import (
+	"strings"
+	"unicode"
+)
+
+// statusFilter is a quick filter restricting the table to rows in a particular state.
+type statusFilter int
+
+const (
+	showAll statusFilter = iota
+	showFailing
+	showRunning
+	hideSuccessful
+)
+
+var failingStatuses = map[string]bool{
+	"failure":         true,
+	"timed_out":       true,
+	"startup_failure": true,
+	"error":           true,
+}
+
+var runningStatuses = map[string]bool{
+	"in_progress": true,
+	"queued":      true,
+	"requested":   true,
+	"waiting":     true,
+	"pending":     true,
+}
+
+func (f statusFilter) String() string {
+	switch f {
+	case showFailing:
+		return "failing only"
+	case showRunning:
+		return "running only"
+	case hideSuccessful:
+		return "hiding successful"
+	}
+	return ""
+}
+
+func (f statusFilter) matches(status string) bool {
+	switch f {
+	case showFailing:
+		return failingStatuses[status]
+	case showRunning:
+		return runningStatuses[status]
+	case hideSuccessful:
+		return status != "success"
+	}
+	return true
+}
+
+// rowFilter combines the search query and the quick filter.
+type rowFilter struct {
+	query  string
+	status statusFilter
+}
+
+func (f rowFilter) active() bool {
+	return f.query != "" || f.status != showAll
+}
+
+func (f rowFilter) matches(info workflowInfo) bool {
+	return f.status.matches(info.Status) && fuzzyMatch(f.query, info.Repo+" "+info.Workflow)
+}
+
+// String describes the active filter for the footer.
+func (f rowFilter) String() string {
+	var parts []string
+	if f.query != "" {
+		parts = append(parts, "/"+f.query)
+	}
+	if f.status != showAll {
+		parts = append(parts, f.status.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fuzzyMatch reports whether each whitespace-separated term of pattern
+// appears in s as a subsequence, ignoring case. An empty pattern matches
+// everything.
+func fuzzyMatch(pattern, s string) bool {
+	target := []rune(strings.ToLower(s))
+	for _, term := range strings.Fields(strings.ToLower(pattern)) {
+		if !isSubsequence([]rune(term), target) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSubsequence(needle, haystack []rune) bool {
+	i := 0
+	for _, r := range haystack {
+		if i == len(needle) {
+			break
+		}
+		if unicode.ToLower(r) == needle[i] {
+			i++
+		}
+	}
+	return i == len(needle)
+}
@@ -0,0 +1,52 @@
+package ghamon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"empty pattern", "", "owner/repo CI", true},
+		{"substring", "repo", "owner/repo CI", true},
+		{"subsequence", "orc", "owner/repo CI", true},
+		{"case insensitive", "OWNci", "owner/repo CI", true},
+		{"out of order", "ico", "owner/repo CI", false},
+		{"multiple terms", "own dep", "owner/repo Deploy", true},
+		{"one term misses", "own lint", "owner/repo Deploy", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fuzzyMatch(tt.pattern, tt.s))
+		})
+	}
+}
+
+func TestStatusFilter(t *testing.T) {
+	assert.True(t, showAll.matches("success"))
+	assert.True(t, showFailing.matches("failure"))
+	assert.True(t, showFailing.matches("timed_out"))
+	assert.False(t, showFailing.matches("success"))
+	assert.True(t, showRunning.matches("in_progress"))
+	assert.True(t, showRunning.matches("queued"))
+	assert.False(t, showRunning.matches("failure"))
+	assert.False(t, hideSuccessful.matches("success"))
+	assert.True(t, hideSuccessful.matches("cancelled"))
+}
+
+func TestRowFilter(t *testing.T) {
+	info := workflowInfo{Repo: "owner/repo", Workflow: "Deploy", Status: "failure"}
+
+	assert.False(t, rowFilter{}.active())
+	assert.True(t, rowFilter{}.matches(info))
+	assert.True(t, rowFilter{query: "dep", status: showFailing}.matches(info))
+	assert.False(t, rowFilter{query: "dep", status: showRunning}.matches(info))
+	assert.False(t, rowFilter{query: "lint"}.matches(info))
+	assert.Equal(t, "/dep, failing only", rowFilter{query: "dep", status: showFailing}.String())
+}
@@ -12,6 +12,10 @@ func Run() {
 		help     bool
 		rate     int
 		workflow string
+		checks   bool
+		branch   string
+		theme    string
+		tabbed   bool
 	)
 
 	flag.BoolVar(&help, "h", false, "Show help message and exit")
@@ -20,6 +24,14 @@ func Run() {
 	flag.IntVar(&rate, "rate", 30, "Refresh rate in seconds")
 	flag.StringVar(&workflow, "w", "", "GitHub Actions workflow to monitor (default: all)")
 	flag.StringVar(&workflow, "workflow", "", "GitHub Actions workflow to monitor (default: all)")
+	flag.BoolVar(&checks, "c", false, "Also show commit statuses and third-party check runs")
+	flag.BoolVar(&checks, "checks", false, "Also show commit statuses and third-party check runs")
+	flag.StringVar(&branch, "b", "", "Branch whose head commit's checks are shown (default: default branch)")
+	flag.StringVar(&branch, "branch", "", "Branch whose head commit's checks are shown (default: default branch)")
+	flag.StringVar(&theme, "T", "", "Colour theme (default: dark)")
+	flag.StringVar(&theme, "theme", "", "Colour theme (default: dark)")
+	flag.BoolVar(&tabbed, "d", false, "Show each repository argument as its own dashboard tab")
+	flag.BoolVar(&tabbed, "dashboards", false, "Show each repository argument as its own dashboard tab")
 	flag.Usage = printUsage
 	flag.Parse()
 
@@ -34,14 +46,33 @@ func Run() {
 		os.Exit(1)
 	}
 
-	repos, err := ResolveRepos(flag.Args())
+	var (
+		repos []string
+		tabs  []Tab
+		err   error
+	)
+	if tabbed {
+		tabs, err = ResolveTabs(flag.Args())
+	} else {
+		repos, err = ResolveRepos(flag.Args())
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		printUsage()
 		os.Exit(1)
 	}
 
-	if err := RunTUI(workflow, repos, rate, token); err != nil {
+	opts := Options{
+		Workflow: workflow,
+		Repos:    repos,
+		Rate:     rate,
+		Token:    token,
+		Checks:   checks,
+		Branch:   branch,
+		Theme:    theme,
+		Tabs:     tabs,
+	}
+	if err := RunTUI(opts); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -56,6 +87,13 @@ func printUsage() {
 	fmt.Println("  -h, --help       Show help message and exit")
 	fmt.Println("  -r, --rate       Refresh rate in seconds (default: 30)")
 	fmt.Println("  -w, --workflow   GitHub Actions workflow to monitor (default: all)")
+	fmt.Println("  -c, --checks     Also show commit statuses and third-party check runs")
+	fmt.Println("  -b, --branch     Branch whose head commit's checks are shown")
+	fmt.Println("                   (default: the repository's default branch)")
+	fmt.Println("  -T, --theme      Colour theme: dark, light, high-contrast, deuteranopia,")
+	fmt.Println("                   or a file in ~/.ghamon/themes (default: dark)")
+	fmt.Println("  -d, --dashboards Show each repository argument as its own tab")
+	fmt.Println("                   (1-9 or tab switches tabs)")
 	fmt.Println()
 	fmt.Println("Arguments:")
 	fmt.Println("  repository       owner/repo, or @file (file in ~/.ghamon) containing")
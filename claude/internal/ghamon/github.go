@@ -1,20 +1,40 @@
 package ghamon
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 const defaultBaseURL = "https://api.github.com"
 
 // WorkflowRun represents a GitHub Actions workflow run.
 type WorkflowRun struct {
-	WorkflowID int    `json:"workflow_id"`
-	Name       string `json:"name"`
-	Status     string `json:"status"`
-	Conclusion string `json:"conclusion"`
+	WorkflowID   int       `json:"workflow_id"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RunStartedAt time.Time `json:"run_started_at"`
+	HeadBranch   string    `json:"head_branch"`
+	HeadSHA      string    `json:"head_sha"`
+	Actor        Actor     `json:"actor"`
+
+	// MedianDuration is the median duration of the workflow's recent
+	// successful runs, computed from the same API response. It is zero when
+	// there are too few runs to tell.
+	MedianDuration time.Duration `json:"-"`
+}
+
+// Actor is the user who triggered a workflow run.
+type Actor struct {
+	Login string `json:"login"`
 }
 
 type workflowRunsResponse struct {
@@ -26,6 +46,17 @@ type GitHubClient struct {
 	HTTPClient *http.Client
 	Token      string
 	BaseURL    string
+
+	// Timeout bounds each request, and Retries is how many times a request
+	// that failed for a reason that may pass is made again, waiting about
+	// Backoff before the first retry and twice as long before each next
+	// one (see retry.go). Zero values disable them.
+	Timeout time.Duration
+	Retries int
+	Backoff time.Duration
+
+	mu              sync.Mutex
+	defaultBranches map[string]cachedBranch
 }
 
 // NewGitHubClient creates a new GitHubClient with default settings.
@@ -34,38 +65,24 @@ func NewGitHubClient(token string) *GitHubClient {
 		HTTPClient: http.DefaultClient,
 		Token:      token,
 		BaseURL:    defaultBaseURL,
+		Timeout:    defaultTimeout,
+		Retries:    defaultRetries,
+		Backoff:    defaultBackoff,
 	}
 }
 
 // FetchWorkflowRun fetches the most recent run of the named workflow for a repository.
-func (c *GitHubClient) FetchWorkflowRun(repo, workflow string) (*WorkflowRun, error) {
-	url := fmt.Sprintf("%s/repos/%s/actions/runs?per_page=50", c.BaseURL, repo)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Authorization", "token "+c.Token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fetching runs for %s: %w", repo, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned %d for %s", resp.StatusCode, repo)
-	}
-
+func (c *GitHubClient) FetchWorkflowRun(ctx context.Context, repo, workflow string) (*WorkflowRun, error) {
 	var result workflowRunsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding response for %s: %w", repo, err)
+	if err := c.get(ctx, fmt.Sprintf("%s/repos/%s/actions/runs?per_page=50", c.BaseURL, repo), repo, &result); err != nil {
+		return nil, err
 	}
 
 	// Return the most recent run matching the workflow name.
+	medians := medianDurations(result.WorkflowRuns)
 	for _, run := range result.WorkflowRuns {
 		if run.Name == workflow {
+			run.MedianDuration = medians[run.WorkflowID]
 			return &run, nil
 		}
 	}
@@ -74,29 +91,10 @@ func (c *GitHubClient) FetchWorkflowRun(repo, workflow string) (*WorkflowRun, er
 }
 
 // FetchWorkflowRuns fetches the most recent run of each distinct workflow for a repository.
-func (c *GitHubClient) FetchWorkflowRuns(repo string) ([]WorkflowRun, error) {
-	url := fmt.Sprintf("%s/repos/%s/actions/runs?per_page=50", c.BaseURL, repo)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Authorization", "token "+c.Token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fetching runs for %s: %w", repo, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned %d for %s", resp.StatusCode, repo)
-	}
-
+func (c *GitHubClient) FetchWorkflowRuns(ctx context.Context, repo string) ([]WorkflowRun, error) {
 	var result workflowRunsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding response for %s: %w", repo, err)
+	if err := c.get(ctx, fmt.Sprintf("%s/repos/%s/actions/runs?per_page=50", c.BaseURL, repo), repo, &result); err != nil {
+		return nil, err
 	}
 
 	// Collect the most recent run per distinct workflow.
@@ -105,11 +103,13 @@ func (c *GitHubClient) FetchWorkflowRuns(repo string) ([]WorkflowRun, error) {
 	// workflow_id because the same workflow can appear under different
 	// names (e.g. "Build" vs ".github/workflows/build.yaml").
 	seen := make(map[int]bool)
+	medians := medianDurations(result.WorkflowRuns)
 	var runs []WorkflowRun
 	for _, run := range result.WorkflowRuns {
 		if !seen[run.WorkflowID] {
 			seen[run.WorkflowID] = true
 			run.Name = cleanWorkflowName(run.Name)
+			run.MedianDuration = medians[run.WorkflowID]
 			runs = append(runs, run)
 		}
 	}
@@ -117,6 +117,51 @@ func (c *GitHubClient) FetchWorkflowRuns(repo string) ([]WorkflowRun, error) {
 	return runs, nil
 }
 
+// get fetches url and decodes the JSON response into v, retrying as
+// configured. repo is used in error messages.
+func (c *GitHubClient) get(ctx context.Context, url, repo string, v any) error {
+	for attempt := 0; ; attempt++ {
+		err := c.getOnce(ctx, url, repo, v)
+		if err == nil || attempt >= c.Retries || !retryable(ctx, err) {
+			return err
+		}
+		if err := sleep(ctx, backoff(c.Backoff, attempt, retryAfter(err))); err != nil {
+			return fmt.Errorf("fetching %s: %w", repo, err)
+		}
+	}
+}
+
+// getOnce makes a single request for get, bounded by the client's timeout.
+func (c *GitHubClient) getOnce(ctx context.Context, url, repo string, v any) error {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+c.Token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return responseError(resp, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response for %s: %w", repo, err)
+	}
+	return nil
+}
+
 // cleanWorkflowName strips the ".github/workflows/" prefix from a workflow name.
 func cleanWorkflowName(name string) string {
 	return strings.TrimPrefix(name, ".github/workflows/")
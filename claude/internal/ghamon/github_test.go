@@ -1,10 +1,12 @@
 package ghamon
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,7 +16,8 @@ func TestFetchWorkflowRuns(t *testing.T) {
 	t.Run("returns most recent run per distinct workflow", func(t *testing.T) {
 		response := workflowRunsResponse{
 			WorkflowRuns: []WorkflowRun{
-				{WorkflowID: 1, Name: "CI", Status: "completed", Conclusion: "success"},
+				{WorkflowID: 1, Name: "CI", Status: "completed", Conclusion: "success",
+					HeadBranch: "main", HeadSHA: "abc1234", Actor: Actor{Login: "octocat"}},
 				{WorkflowID: 2, Name: "Deploy", Status: "in_progress", Conclusion: ""},
 				{WorkflowID: 1, Name: "CI", Status: "completed", Conclusion: "failure"},
 				{WorkflowID: 3, Name: "Lint", Status: "completed", Conclusion: "success"},
@@ -28,11 +31,13 @@ func TestFetchWorkflowRuns(t *testing.T) {
 		defer server.Close()
 
 		client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
-		runs, err := client.FetchWorkflowRuns("owner/repo")
+		runs, err := client.FetchWorkflowRuns(context.Background(), "owner/repo")
 		require.NoError(t, err)
 		require.Len(t, runs, 3)
 		assert.Equal(t, "CI", runs[0].Name)
 		assert.Equal(t, "success", runs[0].Conclusion)
+		assert.Equal(t, "main", runs[0].HeadBranch)
+		assert.Equal(t, "octocat", runs[0].Actor.Login)
 		assert.Equal(t, "Deploy", runs[1].Name)
 		assert.Equal(t, "Lint", runs[2].Name)
 	})
@@ -52,13 +57,36 @@ func TestFetchWorkflowRuns(t *testing.T) {
 		defer server.Close()
 
 		client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
-		runs, err := client.FetchWorkflowRuns("owner/repo")
+		runs, err := client.FetchWorkflowRuns(context.Background(), "owner/repo")
 		require.NoError(t, err)
 		require.Len(t, runs, 1)
 		assert.Equal(t, "Build", runs[0].Name)
 		assert.Equal(t, "success", runs[0].Conclusion)
 	})
 
+	t.Run("sets the median duration of recent successful runs", func(t *testing.T) {
+		base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		var response workflowRunsResponse
+		for _, d := range []time.Duration{2 * time.Minute, time.Minute, 3 * time.Minute} {
+			response.WorkflowRuns = append(response.WorkflowRuns, WorkflowRun{
+				WorkflowID: 1, Name: "CI", Status: "completed", Conclusion: "success",
+				RunStartedAt: base, UpdatedAt: base.Add(d),
+			})
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer server.Close()
+
+		client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
+		runs, err := client.FetchWorkflowRuns(context.Background(), "owner/repo")
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, 2*time.Minute, runs[0].MedianDuration)
+	})
+
 	t.Run("returns empty slice when no runs exist", func(t *testing.T) {
 		response := workflowRunsResponse{WorkflowRuns: []WorkflowRun{}}
 
@@ -69,7 +97,7 @@ func TestFetchWorkflowRuns(t *testing.T) {
 		defer server.Close()
 
 		client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
-		runs, err := client.FetchWorkflowRuns("owner/repo")
+		runs, err := client.FetchWorkflowRuns(context.Background(), "owner/repo")
 		require.NoError(t, err)
 		assert.Empty(t, runs)
 	})
@@ -81,7 +109,7 @@ func TestFetchWorkflowRuns(t *testing.T) {
 		defer server.Close()
 
 		client := &GitHubClient{HTTPClient: server.Client(), Token: "test-token", BaseURL: server.URL}
-		_, err := client.FetchWorkflowRuns("owner/repo")
+		_, err := client.FetchWorkflowRuns(context.Background(), "owner/repo")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "404")
 	})
@@ -112,7 +140,7 @@ func TestFetchWorkflowRun(t *testing.T) {
 			BaseURL:    server.URL,
 		}
 
-		run, err := client.FetchWorkflowRun("owner/repo", "CI")
+		run, err := client.FetchWorkflowRun(context.Background(), "owner/repo", "CI")
 		require.NoError(t, err)
 		require.NotNil(t, run)
 		assert.Equal(t, "CI", run.Name)
@@ -138,7 +166,7 @@ func TestFetchWorkflowRun(t *testing.T) {
 			BaseURL:    server.URL,
 		}
 
-		run, err := client.FetchWorkflowRun("owner/repo", "Deploy")
+		run, err := client.FetchWorkflowRun(context.Background(), "owner/repo", "Deploy")
 		require.NoError(t, err)
 		assert.Nil(t, run)
 	})
@@ -155,7 +183,7 @@ func TestFetchWorkflowRun(t *testing.T) {
 			BaseURL:    server.URL,
 		}
 
-		_, err := client.FetchWorkflowRun("owner/repo", "CI")
+		_, err := client.FetchWorkflowRun(context.Background(), "owner/repo", "CI")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "404")
 	})
@@ -177,7 +205,7 @@ func TestFetchWorkflowRun(t *testing.T) {
 			BaseURL:    server.URL,
 		}
 
-		run, err := client.FetchWorkflowRun("owner/repo", "CI")
+		run, err := client.FetchWorkflowRun(context.Background(), "owner/repo", "CI")
 		require.NoError(t, err)
 		assert.Nil(t, run)
 	})
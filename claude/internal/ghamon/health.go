@@ -0,0 +1,168 @@
+package ghamon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overallState sums up the health of everything monitored, from the best to
+// the worst.
+type overallState int
+
+const (
+	statePending overallState = iota // nothing fetched yet
+	statePassing
+	stateRunning
+	stateWarning // failures off the default branch, or fetch errors
+	stateFailing // failures on a default branch
+)
+
+var stateNames = [...]string{"pending", "passing", "running", "warning", "failing"}
+
+func (s overallState) String() string {
+	return stateNames[s]
+}
+
+// stateIcons mark the overall state in the header and window title.
+var stateIcons = [...]string{"○", "✓", "●", "!", "✗"}
+
+// health counts rows by outcome.
+type health struct {
+	success, failure, running, queued, errors int
+	// failingDefault counts the failures on a repository's default branch.
+	failingDefault int
+}
+
+// countHealth counts rows by outcome. Rows not fetched yet are left out.
+func countHealth(rows []workflowInfo) health {
+	var h health
+	for _, r := range rows {
+		switch {
+		case r.Status == "success":
+			h.success++
+		case r.Status == "error":
+			h.errors++
+		case failingStatuses[r.Status]:
+			h.failure++
+			if r.onDefaultBranch() {
+				h.failingDefault++
+			}
+		case r.Status == "in_progress":
+			h.running++
+		case runningStatuses[r.Status]:
+			h.queued++
+		}
+	}
+	return h
+}
+
+// onDefaultBranch reports whether a row is for its repository's default
+// branch. Rows whose branch is not known count as on the default branch.
+func (w workflowInfo) onDefaultBranch() bool {
+	return w.Branch == "" || w.DefaultBranch == "" || w.Branch == w.DefaultBranch
+}
+
+// state returns the overall state of the counted rows.
+func (h health) state() overallState {
+	switch {
+	case h.failingDefault > 0:
+		return stateFailing
+	case h.failure > 0 || h.errors > 0:
+		return stateWarning
+	case h.running > 0 || h.queued > 0:
+		return stateRunning
+	case h.success > 0:
+		return statePassing
+	}
+	return statePending
+}
+
+// String lists the non-zero counts, e.g. "12 ok · 2 failing · 1 queued".
+func (h health) String() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{{h.success, "ok"}, {h.failure, "failing"}, {h.running, "running"}, {h.queued, "queued"}, {h.errors, "error"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// health returns the counts and overall state of every row, whatever the
+// filter.
+func (m model) health() (health, overallState) {
+	h := countHealth(m.flatRuns())
+	return h, h.state()
+}
+
+// healthBar renders the overall state, the counts and when the data was last
+// refreshed and will be next.
+func (m model) healthBar(now time.Time) string {
+	h, state := m.health()
+	style := map[overallState]string{
+		statePassing: "success", stateRunning: "in_progress", stateWarning: "cancelled", stateFailing: "failure",
+	}[state]
+	label := stateIcons[state] + " " + strings.ToUpper(state.String())
+	bar := statusStyles[style].Bold(true).Render(label)
+	if counts := h.String(); counts != "" {
+		bar += "  " + counts
+	}
+	return bar + footerStyle.Render("  "+m.refreshStatus(now))
+}
+
+// refreshStatus describes the last successful refresh, or since when GitHub
+// has not answered, how old the rows kept from before are, and the next
+// refresh.
+func (m model) refreshStatus(now time.Time) string {
+	var last string
+	switch {
+	case !m.offlineSince.IsZero():
+		last = "offline since " + formatClock(m.offlineSince)
+	case m.lastRefresh.IsZero():
+		last = "not refreshed yet"
+	default:
+		last = "refreshed " + formatClock(m.lastRefresh)
+	}
+	if stale := m.staleSince(); !stale.IsZero() {
+		last += fmt.Sprintf(", stale data from %s ago", formatDuration(max(time.Second, now.Sub(stale))))
+	}
+	if m.fetching {
+		return last + ", refreshing…"
+	}
+	if m.paused {
+		return last + ", paused"
+	}
+	return fmt.Sprintf("%s, next in %s", last, formatDuration(max(time.Second, m.nextRefresh.Sub(now))))
+}
+
+// windowTitle returns the terminal window title for the overall state and
+// the number of failing rows, e.g. "✗ ghamon: failing (2)".
+func windowTitle(state overallState, failing int) string {
+	title := fmt.Sprintf("%s ghamon: %s", stateIcons[state], state)
+	if failing > 0 {
+		title += fmt.Sprintf(" (%d)", failing)
+	}
+	return title
+}
+
+// setTitle returns a command setting the window title to title, unless it is
+// already *last.
+func setTitle(last *string, title string) tea.Cmd {
+	if title == *last {
+		return nil
+	}
+	*last = title
+	return tea.SetWindowTitle(title)
+}
+
+// updateTitle returns a command setting the window title if it changed.
+func (m *model) updateTitle() tea.Cmd {
+	h, state := m.health()
+	return setTitle(&m.title, windowTitle(state, h.failure+h.errors))
+}
@@ -0,0 +1,62 @@
+package ghamon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthState(t *testing.T) {
+	rows := func(rs ...workflowInfo) []workflowInfo { return rs }
+	tests := []struct {
+		name string
+		rows []workflowInfo
+		want overallState
+	}{
+		{"nothing fetched", rows(workflowInfo{Status: "..."}), statePending},
+		{"all passing", rows(workflowInfo{Status: "success"}, workflowInfo{Status: "skipped"}), statePassing},
+		{"running", rows(workflowInfo{Status: "success"}, workflowInfo{Status: "queued"}), stateRunning},
+		{"failing on default branch", rows(workflowInfo{Status: "failure", Branch: "main", DefaultBranch: "main"}), stateFailing},
+		{"failing on unknown branch", rows(workflowInfo{Status: "timed_out"}), stateFailing},
+		{"failing off default branch", rows(workflowInfo{Status: "failure", Branch: "feature", DefaultBranch: "main"}), stateWarning},
+		{"fetch error", rows(workflowInfo{Status: "success"}, workflowInfo{Status: "error"}), stateWarning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, countHealth(tt.rows).state())
+		})
+	}
+}
+
+func TestHealthString(t *testing.T) {
+	h := countHealth([]workflowInfo{
+		{Status: "success"}, {Status: "success"}, {Status: "failure"},
+		{Status: "in_progress"}, {Status: "waiting"}, {Status: "error"}, {Status: "cancelled"},
+	})
+	assert.Equal(t, "2 ok · 1 failing · 1 running · 1 queued · 1 error", h.String())
+	assert.Equal(t, health{}.String(), "")
+}
+
+func TestHealthBar(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/repo"}, Rate: 30}, DefaultSettings())
+	now := time.Now()
+	assert.Contains(t, m.healthBar(now), "PENDING")
+	assert.Contains(t, m.healthBar(now), "not refreshed yet, refreshing…")
+
+	tm, cmd := m.Update(fetchedRepoMsg{index: 0, infos: []workflowInfo{
+		{Repo: "owner/repo", Workflow: "ci", Status: "failure"},
+		{Repo: "owner/repo", Workflow: "lint", Status: "success"},
+	}})
+	m = tm.(model)
+	m.nextRefresh = now.Add(25 * time.Second)
+	bar := m.healthBar(now)
+	assert.Contains(t, bar, "✗ FAILING")
+	assert.Contains(t, bar, "1 ok · 1 failing")
+	assert.Contains(t, bar, "refreshed "+formatClock(m.lastRefresh)+", next in 25s")
+	assert.Contains(t, m.View(), "FAILING", "the health bar is part of the header")
+
+	assert.Equal(t, "✗ ghamon: failing (1)", m.title)
+	assert.NotNil(t, cmd)
+	assert.Nil(t, m.updateTitle(), "the title is only set when it changes")
+}
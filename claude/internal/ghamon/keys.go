@@ -0,0 +1,220 @@
+package ghamon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keysFile is the name of the key bindings file in ~/.ghamon.
+const keysFile = "keys.json"
+
+// KeyConfig remaps key bindings. Preset picks a base keymap ("default", "vim"
+// or "emacs"); Bindings then replaces the keys of individual actions, e.g.
+// {"quit": ["q", "ctrl+c"]}.
+type KeyConfig struct {
+	Preset   string              `json:"preset,omitempty"`
+	Bindings map[string][]string `json:"bindings,omitempty"`
+}
+
+// LoadKeyConfig reads key bindings from a JSON file. A missing file leaves the
+// default bindings.
+func LoadKeyConfig(path string) (KeyConfig, error) {
+	var c KeyConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return KeyConfig{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// keyMap holds the key bindings of the table. The search prompt keeps fixed
+// keys.
+type keyMap struct {
+	Quit, Refresh, Help                   key.Binding
+	Up, Down, PageUp, PageDown, Home, End key.Binding
+	Search, Failing, Running, HideOK      key.Binding
+	Clear, Sort, Reverse, Group, Timing   key.Binding
+	Fold, CollapseAll, ExpandAll          key.Binding
+	Pause, Faster, Slower, Adaptive       key.Binding
+	NextTab, PrevTab, GotoTab             key.Binding
+}
+
+// keyPresets are the base keymaps, as changes to the default one by action
+// name.
+var keyPresets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"page_up":   {"pgup", "ctrl+b", "ctrl+u"},
+		"page_down": {"pgdown", "ctrl+f", "ctrl+d"},
+	},
+	"emacs": {
+		"up":        {"up", "ctrl+p"},
+		"down":      {"down", "ctrl+n"},
+		"page_up":   {"pgup", "alt+v"},
+		"page_down": {"pgdown", "ctrl+v"},
+		"home":      {"home", "alt+<"},
+		"end":       {"end", "alt+>"},
+		"back":      {"esc", "ctrl+g"},
+		"search":    {"/", "ctrl+s"},
+	},
+}
+
+func newBinding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKeys(keys), desc))
+}
+
+// helpKeys describes keys for the help.
+func helpKeys(keys []string) string {
+	shown := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		shown[i] = k
+	}
+	return strings.Join(shown, "/")
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:        newBinding("quit", "q", "ctrl+c"),
+		Refresh:     newBinding("refresh", "r"),
+		Help:        newBinding("help", "?"),
+		Up:          newBinding("up", "up", "k"),
+		Down:        newBinding("down", "down", "j"),
+		PageUp:      newBinding("page up", "pgup"),
+		PageDown:    newBinding("page down", "pgdown"),
+		Home:        newBinding("first row", "home", "g"),
+		End:         newBinding("last row", "end", "G"),
+		Search:      newBinding("search", "/"),
+		Failing:     newBinding("failing only", "f"),
+		Running:     newBinding("running only", "p"),
+		HideOK:      newBinding("hide successful", "s"),
+		Clear:       newBinding("clear filter", "esc"),
+		Sort:        newBinding("sort", "o"),
+		Reverse:     newBinding("reverse sort", "O"),
+		Group:       newBinding("group", "t"),
+		Timing:      newBinding("timing", "d"),
+		Fold:        newBinding("details/fold", "enter", " "),
+		CollapseAll: newBinding("fold all", "["),
+		ExpandAll:   newBinding("unfold all", "]"),
+		Pause:       newBinding("pause refresh", "P"),
+		Faster:      newBinding("refresh faster", "+"),
+		Slower:      newBinding("refresh slower", "-"),
+		Adaptive:    newBinding("adaptive refresh", "~"),
+		NextTab:     newBinding("next tab", "tab"),
+		PrevTab:     newBinding("previous tab", "shift+tab"),
+		GotoTab: key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "go to tab")),
+	}
+}
+
+// actions names the bindings for the key bindings file. The names are shared
+// with the other ghamon implementation.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit": &k.Quit, "refresh": &k.Refresh, "help": &k.Help,
+		"up": &k.Up, "down": &k.Down, "page_up": &k.PageUp, "page_down": &k.PageDown,
+		"home": &k.Home, "end": &k.End,
+		"search": &k.Search, "failing": &k.Failing, "running": &k.Running, "hide_successful": &k.HideOK,
+		"back": &k.Clear, "sort": &k.Sort, "reverse": &k.Reverse, "group": &k.Group, "timing": &k.Timing,
+		"select": &k.Fold, "collapse_all": &k.CollapseAll, "expand_all": &k.ExpandAll,
+		"pause": &k.Pause, "faster": &k.Faster, "slower": &k.Slower, "adaptive": &k.Adaptive,
+		"next_tab": &k.NextTab, "prev_tab": &k.PrevTab, "goto_tab": &k.GotoTab,
+	}
+}
+
+// newKeyMap returns the key bindings configured by c: its preset, then its
+// remapped actions. An action remapped to no keys is switched off. Unknown
+// actions are reported but do not stop the others from being remapped.
+func newKeyMap(c KeyConfig) (keyMap, error) {
+	k := defaultKeyMap()
+	actions := k.actions()
+	name := c.Preset
+	if name == "" {
+		name = "default"
+	}
+	preset, ok := keyPresets[name]
+	if !ok {
+		return k, fmt.Errorf("unknown key preset %q (presets: default, emacs, vim)", name)
+	}
+	var unknown []string
+	for _, bindings := range []map[string][]string{preset, c.Bindings} {
+		for action, keys := range bindings {
+			b, ok := actions[action]
+			if !ok {
+				unknown = append(unknown, action)
+				continue
+			}
+			b.SetKeys(keys...)
+			b.SetHelp(helpKeys(keys), b.Help().Desc)
+			b.SetEnabled(len(keys) > 0)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return k, fmt.Errorf("unknown actions in key bindings: %s", strings.Join(unknown, ", "))
+	}
+	return k, nil
+}
+
+// ShortHelp returns the bindings shown in the footer.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Quit, k.Help, k.Refresh, k.Search, k.Failing, k.Running, k.HideOK,
+		k.Sort, k.Reverse, k.Group, k.Timing}
+}
+
+// FullHelp returns every binding, grouped for the help overlay.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.PageUp, k.PageDown, k.Home, k.End},
+		{k.Search, k.Failing, k.Running, k.HideOK, k.Clear},
+		{k.Sort, k.Reverse, k.Group, k.Fold, k.CollapseAll, k.ExpandAll, k.Timing},
+		{k.Refresh, k.Pause, k.Faster, k.Slower, k.Adaptive},
+		{k.NextTab, k.PrevTab, k.GotoTab, k.Help, k.Quit},
+	}
+}
+
+// withoutTabs returns k with the tab bindings switched off, for a single
+// dashboard.
+func (k keyMap) withoutTabs() keyMap {
+	k.NextTab.SetEnabled(false)
+	k.PrevTab.SetEnabled(false)
+	k.GotoTab.SetEnabled(false)
+	return k
+}
+
+// keyHints renders bindings as footer hints, e.g. "q: quit | r: refresh".
+func keyHints(bindings []key.Binding) string {
+	var hints []string
+	for _, b := range bindings {
+		if b.Enabled() {
+			hints = append(hints, b.Help().Key+": "+b.Help().Desc)
+		}
+	}
+	return strings.Join(hints, " | ")
+}
+
+// helpView renders every binding in columns for the help overlay.
+func (k keyMap) helpView(width int) string {
+	h := help.New()
+	h.Width = width
+	h.Styles.FullKey = columnStyle.Bold(true)
+	h.Styles.FullDesc = lipgloss.NewStyle()
+	h.Styles.FullSeparator = footerStyle
+	h.FullSeparator = "    "
+	return h.FullHelpView(k.FullHelp())
+}
@@ -0,0 +1,70 @@
+package ghamon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestLoadKeyConfig(t *testing.T) {
+	t.Run("returns no changes for missing file", func(t *testing.T) {
+		c, err := LoadKeyConfig(filepath.Join(t.TempDir(), "keys.json"))
+		require.NoError(t, err)
+		assert.Equal(t, KeyConfig{}, c)
+	})
+
+	t.Run("reads preset and bindings", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"preset": "vim", "bindings": {"quit": ["ctrl+x"]}}`), 0644))
+		c, err := LoadKeyConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "vim", c.Preset)
+		assert.Equal(t, []string{"ctrl+x"}, c.Bindings["quit"])
+	})
+}
+
+func TestNewKeyMap(t *testing.T) {
+	t.Run("applies preset then bindings", func(t *testing.T) {
+		k, err := newKeyMap(KeyConfig{Preset: "emacs", Bindings: map[string][]string{"failing": {"F"}, "timing": {}}})
+		require.NoError(t, err)
+		assert.True(t, key.Matches(tea.KeyMsg{Type: tea.KeyCtrlN}, k.Down))
+		assert.True(t, key.Matches(runes("F"), k.Failing))
+		assert.False(t, key.Matches(runes("f"), k.Failing))
+		assert.False(t, k.Timing.Enabled())
+		assert.Equal(t, "down/ctrl+n", k.Down.Help().Key)
+	})
+
+	t.Run("rejects unknown preset", func(t *testing.T) {
+		_, err := newKeyMap(KeyConfig{Preset: "nano"})
+		assert.ErrorContains(t, err, `unknown key preset "nano"`)
+	})
+
+	t.Run("rejects unknown action", func(t *testing.T) {
+		_, err := newKeyMap(KeyConfig{Bindings: map[string][]string{"explode": {"x"}}})
+		assert.ErrorContains(t, err, `unknown actions in key bindings: explode`)
+	})
+}
+
+func TestHelpOverlay(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/repo"}, Rate: 30}, DefaultSettings())
+	m.windowWidth, m.windowHeight = 100, 30
+
+	tm, _ := m.Update(runes("?"))
+	v := tm.View()
+	assert.Contains(t, v, "Key bindings")
+	assert.Contains(t, v, "hide successful")
+	assert.Equal(t, 30, strings.Count(v, "\n")+1)
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.NotContains(t, tm.View(), "Key bindings")
+}
@@ -0,0 +1,191 @@
+package ghamon
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ellipsis marks text cut short to fit its column.
+const ellipsis = "…"
+
+// columnID identifies a column of the workflow table.
+type columnID int
+
+const (
+	colRepo columnID = iota
+	colWorkflow
+	colStatus
+	colStarted
+	colDuration
+	colQueued
+	colUpdated
+	colBranch
+	colSHA
+	colActor
+)
+
+// column describes a column of the workflow table.
+type column struct {
+	id    columnID
+	title string
+	field sortField // the sort field of a sortable column, else sortDefault
+
+	// width is the column's preferred width. A column sized to its content
+	// is as narrow as its cells allow, and only wider than width if the
+	// terminal has room to spare.
+	width int
+	// min is the narrowest a flexible column is shrunk to on a narrow
+	// terminal. Columns with no min keep their width.
+	min int
+	// hide orders the optional columns for hiding: when the table is too
+	// wide, the column with the highest hide goes first. Columns with no
+	// hide are always shown.
+	hide int
+	// fit sizes the column to its widest cell, up to width.
+	fit bool
+}
+
+// tableColumns lists the columns of the table, the timing ones only if timing
+// is set.
+func tableColumns(timing bool) []column {
+	cols := []column{
+		{id: colRepo, title: "REPOSITORY", field: sortRepo, width: 40, min: 12, fit: true},
+		{id: colWorkflow, title: "WORKFLOW", field: sortWorkflow, width: 40, min: 12, fit: true},
+		{id: colStatus, title: "STATUS", field: sortStatus, width: 18},
+	}
+	if timing {
+		cols = append(cols,
+			column{id: colStarted, title: "STARTED", width: 8, hide: 4},
+			column{id: colDuration, title: "DURATION", field: sortDuration, width: 10, hide: 1},
+			column{id: colQueued, title: "QUEUED", width: 8, hide: 5},
+			column{id: colUpdated, title: "UPDATED", field: sortUpdated, width: 10, hide: 2},
+		)
+	}
+	return append(cols,
+		column{id: colBranch, title: "BRANCH", width: 20, min: 8, hide: 3, fit: true},
+		column{id: colSHA, title: "SHA", width: 7, hide: 6},
+		column{id: colActor, title: "ACTOR", width: 15, hide: 7, fit: true},
+	)
+}
+
+// placedColumn is a column as laid out on a line: it starts at x and is
+// width wide.
+type placedColumn struct {
+	column
+	x int
+}
+
+// layoutColumns fits cols into width, separated by a space. Optional columns
+// with no cells to show are left out, and columns sized to their content are
+// narrowed to their widest cell or title. If the table is still too wide,
+// optional columns are hidden in order, and then flexible columns are shrunk,
+// the widest first; if there is room left, columns sized to their content
+// grow to show it in full. A width of 0 leaves the table unbounded. cellWidth
+// returns the width of a column's widest cell.
+func layoutColumns(cols []column, width int, cellWidth func(columnID) int) []placedColumn {
+	var kept []column
+	natural := make(map[columnID]int)
+	for _, c := range cols {
+		w := cellWidth(c.id)
+		if w == 0 && c.hide > 0 {
+			continue
+		}
+		if c.fit {
+			natural[c.id] = max(w, lipgloss.Width(c.title), c.min)
+			c.width = min(natural[c.id], c.width)
+		}
+		kept = append(kept, c)
+	}
+	cols = kept
+
+	total := func() int {
+		t := len(cols) - 1
+		for _, c := range cols {
+			t += c.width
+		}
+		return t
+	}
+	if width > 0 {
+		for total() > width {
+			i := hideNext(cols)
+			if i < 0 {
+				break
+			}
+			cols = append(cols[:i], cols[i+1:]...)
+		}
+		for over := total() - width; over > 0; over-- {
+			i := widestFlexible(cols)
+			if i < 0 {
+				break
+			}
+			cols[i].width--
+		}
+		spare := width - total()
+		for i, c := range cols {
+			if grow := min(spare, natural[c.id]-c.width); c.fit && grow > 0 {
+				cols[i].width += grow
+				spare -= grow
+			}
+		}
+	}
+
+	placed := make([]placedColumn, len(cols))
+	x := 0
+	for i, c := range cols {
+		placed[i] = placedColumn{column: c, x: x}
+		x += c.width + 1
+	}
+	return placed
+}
+
+// hideNext returns the index of the optional column to hide first, or -1.
+func hideNext(cols []column) int {
+	next := -1
+	for i, c := range cols {
+		if c.hide > 0 && (next < 0 || c.hide > cols[next].hide) {
+			next = i
+		}
+	}
+	return next
+}
+
+// widestFlexible returns the index of the widest column that can still be
+// shrunk, or -1.
+func widestFlexible(cols []column) int {
+	widest := -1
+	for i, c := range cols {
+		if c.min > 0 && c.width > c.min && (widest < 0 || c.width > cols[widest].width) {
+			widest = i
+		}
+	}
+	return widest
+}
+
+// truncate shortens s to at most width cells, marking the cut with an
+// ellipsis. Wide characters such as CJK and emoji count as two cells.
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	w := lipgloss.Width(ellipsis)
+	for _, r := range s {
+		rw := lipgloss.Width(string(r))
+		if w+rw > width {
+			break
+		}
+		b.WriteRune(r)
+		w += rw
+	}
+	return b.String() + ellipsis
+}
+
+// fitCell truncates s to width cells and pads it with spaces to exactly width.
+func fitCell(s string, width int) string {
+	s = truncate(s, width)
+	return s + strings.Repeat(" ", max(0, width-lipgloss.Width(s)))
+}
@@ -0,0 +1,94 @@
+package ghamon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "build", truncate("build", 5))
+	assert.Equal(t, "buil…", truncate("build and test", 5))
+	assert.Equal(t, "デプ…", truncate("デプロイ", 6), "wide characters take two cells")
+	assert.Equal(t, "デ…", truncate("デプロイ", 4))
+	assert.Equal(t, "", truncate("build", 0))
+	assert.Equal(t, "デ… ", fitCell("デプロイ", 4))
+	assert.Equal(t, 6, lipgloss.Width(fitCell("デ", 6)))
+}
+
+func TestLayoutColumns(t *testing.T) {
+	cells := map[columnID]int{colRepo: 30, colWorkflow: 50, colStatus: 10, colStarted: 8, colDuration: 5,
+		colQueued: 3, colUpdated: 7, colBranch: 4, colSHA: 7, colActor: 8}
+	cellWidth := func(id columnID) int { return cells[id] }
+	ids := func(cols []placedColumn) []columnID {
+		var ids []columnID
+		for _, c := range cols {
+			ids = append(ids, c.id)
+		}
+		return ids
+	}
+
+	t.Run("fits columns to their content", func(t *testing.T) {
+		cols := layoutColumns(tableColumns(false), 0, cellWidth)
+		assert.Equal(t, []columnID{colRepo, colWorkflow, colStatus, colBranch, colSHA, colActor}, ids(cols))
+		assert.Equal(t, 30, cols[0].width)
+		assert.Equal(t, 40, cols[1].width, "capped at the preferred width")
+		assert.Equal(t, 8, cols[3].width, "no narrower than the minimum")
+		assert.Equal(t, 31, cols[1].x)
+	})
+
+	t.Run("hides optional columns first", func(t *testing.T) {
+		cols := layoutColumns(tableColumns(false), 100, cellWidth)
+		assert.Equal(t, []columnID{colRepo, colWorkflow, colStatus, colBranch}, ids(cols))
+		assert.Equal(t, 100, cols[3].x+cols[3].width)
+		assert.Equal(t, 41, cols[1].width, "the room left goes to the truncated column")
+	})
+
+	t.Run("keeps duration longest", func(t *testing.T) {
+		cols := layoutColumns(tableColumns(true), 105, cellWidth)
+		assert.Equal(t, []columnID{colRepo, colWorkflow, colStatus, colDuration}, ids(cols))
+	})
+
+	t.Run("then shrinks the widest flexible column", func(t *testing.T) {
+		cols := layoutColumns(tableColumns(false), 60, cellWidth)
+		assert.Equal(t, []columnID{colRepo, colWorkflow, colStatus}, ids(cols))
+		assert.Equal(t, 20, cols[0].width)
+		assert.Equal(t, 20, cols[1].width)
+	})
+
+	t.Run("leaves out empty optional columns", func(t *testing.T) {
+		cols := layoutColumns(tableColumns(false), 0, func(id columnID) int {
+			if id == colActor {
+				return 0
+			}
+			return cells[id]
+		})
+		assert.NotContains(t, ids(cols), colActor)
+	})
+}
+
+func TestViewFitsNarrowTerminal(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/repo"}, Rate: 30}, DefaultSettings())
+	m.windowWidth, m.windowHeight = 50, 12
+	m.timing = true
+	m.runs = [][]workflowInfo{{
+		{Repo: "owner/repo", Workflow: "a very long workflow name that would wrap", Status: "success",
+			Branch: "main", SHA: "0123456789abcdef", Actor: "octocat", StartedAt: time.Now()},
+		{Repo: "owner/repo", Workflow: "ビルドとテスト", Status: "in_progress", Branch: "feature/x"},
+	}}
+	view := m.View()
+	for _, line := range strings.Split(view, "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 50, line)
+	}
+	assert.Contains(t, view, "…")
+	assert.NotContains(t, view, "octocat", "optional columns are hidden first")
+
+	m.windowWidth = 200
+	view = m.View()
+	assert.Contains(t, view, "a very long workflow name that would wrap")
+	assert.Contains(t, view, "0123456")
+	assert.Contains(t, view, "octocat")
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package ghamon
+
+// lockFile does nothing where flock is not available: dashboards saving the
+// snapshot at the same moment may then drop each other's rows, which the
+// next save puts back.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,74 @@
+package ghamon
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// doubleClickTime is the longest gap between the clicks of a double-click.
+	doubleClickTime = 400 * time.Millisecond
+	// wheelRows is how many rows a notch of the mouse wheel scrolls.
+	wheelRows = 3
+)
+
+// updateMouse handles mouse events: the wheel scrolls the table, a click
+// selects a row or sorts by a column header, and a double-click acts on the
+// row like enter.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.searching || m.showHelp || m.showDetail {
+		return m, nil
+	}
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.scroll(-wheelRows)
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.scroll(wheelRows)
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		top := m.headerLines()
+		if msg.Y == top-1 {
+			m.sortByColumnAt(msg.X)
+			break
+		}
+		i := m.scrollOffset + msg.Y - top
+		if msg.Y < top || msg.Y >= top+m.contentHeight() || i >= len(m.visibleRows()) {
+			break
+		}
+		now := time.Now()
+		double := i == m.cursor && i == m.lastClickRow && now.Sub(m.lastClick) <= doubleClickTime
+		m.setCursor(i)
+		if double {
+			m.lastClick = time.Time{}
+			m.selectRow()
+		} else {
+			m.lastClick, m.lastClickRow = now, i
+		}
+	}
+	return m, nil
+}
+
+// scroll moves the table by n rows, keeping the cursor on screen.
+func (m *model) scroll(n int) {
+	h := m.contentHeight()
+	m.scrollOffset = max(0, min(m.scrollOffset+n, m.totalRows()-h))
+	m.setCursor(max(m.scrollOffset, min(m.cursor, m.scrollOffset+h-1)))
+}
+
+// sortByColumnAt sorts by the column whose header is at x, reversing the
+// order if the table is already sorted by it.
+func (m *model) sortByColumnAt(x int) {
+	for _, c := range m.columns(time.Now()) {
+		if c.field == sortDefault || x < c.x || x >= c.x+c.width {
+			continue
+		}
+		if m.sort == c.field {
+			m.sortDesc = !m.sortDesc
+		} else {
+			m.sort = c.field
+			m.sortDesc = false
+		}
+		m.followSelection()
+		return
+	}
+}
@@ -0,0 +1,67 @@
+package ghamon
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func mouseModel(n int) model {
+	m := newModel(Options{Repos: []string{"owner/repo"}, Rate: 30}, DefaultSettings())
+	m.windowWidth, m.windowHeight = 120, 20
+	m.runs = [][]workflowInfo{nil}
+	for i := range n {
+		m.runs[0] = append(m.runs[0], workflowInfo{Repo: "owner/repo", Workflow: fmt.Sprintf("wf-%02d", i), Status: "success"})
+	}
+	return m
+}
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+}
+
+func TestMouseClickSelectsRow(t *testing.T) {
+	m := mouseModel(5)
+	tm, _ := m.Update(click(10, m.headerLines()+2))
+	assert.Equal(t, 2, tm.(model).cursor)
+	assert.Equal(t, "wf-02", tm.(model).selected.workflow)
+
+	tm, _ = tm.Update(click(10, m.headerLines()+10))
+	assert.Equal(t, 2, tm.(model).cursor, "clicks below the last row are ignored")
+}
+
+func TestMouseDoubleClickFoldsGroup(t *testing.T) {
+	m := mouseModel(3)
+	m.grouped = true
+	top := m.headerLines()
+	tm, _ := m.Update(click(5, top))
+	tm, _ = tm.Update(click(5, top))
+	assert.True(t, tm.(model).collapsed["owner/repo"])
+	assert.Len(t, tm.(model).visibleRows(), 1)
+}
+
+func TestMouseClickHeaderSorts(t *testing.T) {
+	m := mouseModel(3)
+	tm, _ := m.Update(click(15, m.headerLines()-1))
+	assert.Equal(t, sortWorkflow, tm.(model).sort)
+	assert.False(t, tm.(model).sortDesc)
+
+	tm, _ = tm.Update(click(15, m.headerLines()-1))
+	assert.True(t, tm.(model).sortDesc, "clicking the sorted column reverses the order")
+
+	tm, _ = tm.Update(click(100, m.headerLines()-1))
+	assert.Equal(t, sortWorkflow, tm.(model).sort, "duration is only sortable with the timing columns")
+}
+
+func TestMouseWheelScrolls(t *testing.T) {
+	m := mouseModel(40)
+	tm, _ := m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	assert.Equal(t, 3, tm.(model).scrollOffset)
+	assert.Equal(t, 3, tm.(model).cursor, "the cursor stays on screen")
+
+	tm, _ = tm.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	assert.Equal(t, 0, tm.(model).scrollOffset)
+	assert.Equal(t, 3, tm.(model).cursor)
+}
@@ -22,20 +22,49 @@ func ResolveRepos(args []string) ([]string, error) {
 
 	var repos []string
 	for _, arg := range args {
-		if strings.HasPrefix(arg, "@") {
-			name := arg[1:]
-			path := ghamonFilePath(name)
-			fileRepos, err := LoadReposFromFile(path)
-			if err != nil {
-				if os.IsNotExist(err) {
-					return nil, fmt.Errorf("repository file not found: ~/.ghamon/%s", name)
-				}
-				return nil, fmt.Errorf("reading ~/.ghamon/%s: %w", name, err)
-			}
-			repos = append(repos, fileRepos...)
-		} else {
-			repos = append(repos, arg)
+		argRepos, err := resolveArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, argRepos...)
+	}
+	return repos, nil
+}
+
+// ResolveTabs resolves repository arguments like ResolveRepos, but into a
+// dashboard tab per argument: an @file tab is named after the file, an
+// owner/repo tab after the repository.
+func ResolveTabs(args []string) ([]Tab, error) {
+	if len(args) == 0 {
+		repos, err := ResolveRepos(args)
+		if err != nil {
+			return nil, err
+		}
+		return []Tab{{Name: repos[0], Repos: repos}}, nil
+	}
+	var tabs []Tab
+	for _, arg := range args {
+		repos, err := resolveArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		tabs = append(tabs, Tab{Name: strings.TrimPrefix(arg, "@"), Repos: repos})
+	}
+	return tabs, nil
+}
+
+// resolveArg resolves a single owner/repo or @file argument.
+func resolveArg(arg string) ([]string, error) {
+	if !strings.HasPrefix(arg, "@") {
+		return []string{arg}, nil
+	}
+	name := arg[1:]
+	repos, err := LoadReposFromFile(ghamonFilePath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("repository file not found: ~/.ghamon/%s", name)
 		}
+		return nil, fmt.Errorf("reading ~/.ghamon/%s: %w", name, err)
 	}
 	return repos, nil
 }
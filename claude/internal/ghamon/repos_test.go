@@ -146,3 +146,27 @@ func writeTempFile(t *testing.T, content string) string {
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 	return path
 }
+
+func TestResolveTabs(t *testing.T) {
+	t.Run("makes a tab per argument", func(t *testing.T) {
+		home, err := os.UserHomeDir()
+		require.NoError(t, err)
+		dir := filepath.Join(home, ".ghamon")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		path := filepath.Join(dir, "test-repos-tab")
+		require.NoError(t, os.WriteFile(path, []byte("owner2/repo2\nowner3/repo3\n"), 0644))
+		t.Cleanup(func() { os.Remove(path) })
+
+		tabs, err := ResolveTabs([]string{"owner1/repo1", "@test-repos-tab"})
+		require.NoError(t, err)
+		assert.Equal(t, []Tab{
+			{Name: "owner1/repo1", Repos: []string{"owner1/repo1"}},
+			{Name: "test-repos-tab", Repos: []string{"owner2/repo2", "owner3/repo3"}},
+		}, tabs)
+	})
+
+	t.Run("returns error for missing @file", func(t *testing.T) {
+		_, err := ResolveTabs([]string{"@nonexistent-file"})
+		assert.Error(t, err)
+	})
+}
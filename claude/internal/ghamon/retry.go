@@ -0,0 +1,81 @@
+package ghamon
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultTimeout = 15 * time.Second
+	defaultRetries = 3
+	defaultBackoff = time.Second
+	// maxBackoff caps the wait before a retry, including one asked for by
+	// a Retry-After header.
+	maxBackoff = 30 * time.Second
+)
+
+// retryable reports whether a request that failed with err is worth making
+// again: after a network error or timeout, a server error or 429 Too Many
+// Requests. Other 4xx responses would fail the same way, and nothing is
+// retried once ctx is done, e.g. when the user quits.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		return fe.StatusCode == http.StatusTooManyRequests || fe.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// backoff returns how long to wait before retry attempt+1: base doubled for
+// each earlier attempt, with jitter so that clients failing together do not
+// retry together, or what the server asked for if it said.
+func backoff(base time.Duration, attempt int, asked time.Duration) time.Duration {
+	if asked > 0 {
+		return min(asked, maxBackoff)
+	}
+	d := min(base<<attempt, maxBackoff)
+	if d <= 0 {
+		return 0
+	}
+	// Wait between half and all of d.
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter returns the wait asked for by a 429 response's Retry-After
+// header, or zero.
+func retryAfter(err error) time.Duration {
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		return fe.retryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds.
+func parseRetryAfter(h string) time.Duration {
+	if secs, err := strconv.Atoi(h); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
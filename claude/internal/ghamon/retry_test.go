@@ -0,0 +1,191 @@
+package ghamon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// faultServer answers with faults[i] for the i-th request, and with an empty
+// list of runs once they run out. It returns the server and the number of
+// requests made.
+func faultServer(t *testing.T, faults ...http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(faults) {
+			faults[n-1](w, r)
+			return
+		}
+		w.Write([]byte(`{"workflow_runs": []}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}
+}
+
+// hangUp closes the connection without answering.
+func hangUp(w http.ResponseWriter, r *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+// hang answers only once the request is given up on.
+func hang(w http.ResponseWriter, r *http.Request) {
+	<-r.Context().Done()
+}
+
+func retryClient(server *httptest.Server) *GitHubClient {
+	return &GitHubClient{
+		HTTPClient: server.Client(),
+		BaseURL:    server.URL,
+		Timeout:    time.Second,
+		Retries:    3,
+		Backoff:    time.Millisecond,
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		faults []http.HandlerFunc
+		status int // of the error returned, or 0 for success
+		calls  int32
+	}{
+		{"server errors", []http.HandlerFunc{status(500), status(502)}, 0, 3},
+		{"too many requests", []http.HandlerFunc{status(429)}, 0, 2},
+		{"network error", []http.HandlerFunc{hangUp}, 0, 2},
+		{"gives up", []http.HandlerFunc{status(503), status(503), status(503), status(503)}, 503, 4},
+		{"not found", []http.HandlerFunc{status(404)}, 404, 1},
+		{"unauthorized", []http.HandlerFunc{status(401)}, 401, 1},
+		{"rate limited", []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		}}, 403, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := faultServer(t, tt.faults...)
+			_, err := retryClient(server).FetchWorkflowRuns(context.Background(), "owner/repo")
+			if tt.status == 0 {
+				assert.NoError(t, err)
+			} else {
+				var fe *FetchError
+				require.ErrorAs(t, err, &fe)
+				assert.Equal(t, tt.status, fe.StatusCode)
+			}
+			assert.Equal(t, tt.calls, calls.Load())
+		})
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	server, calls := faultServer(t, hang)
+	client := retryClient(server)
+	client.Timeout = 50 * time.Millisecond
+
+	_, err := client.FetchWorkflowRuns(context.Background(), "owner/repo")
+	assert.NoError(t, err, "a request that hangs is made again")
+	assert.Equal(t, int32(2), calls.Load())
+
+	server, calls = faultServer(t, hang, hang, hang, hang)
+	client = retryClient(server)
+	client.Timeout = 20 * time.Millisecond
+	_, err = client.FetchWorkflowRuns(context.Background(), "owner/repo")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, asFetchError(err).Retryable)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestRetryCancel(t *testing.T) {
+	server, calls := faultServer(t, hang, hang)
+	client := retryClient(server)
+	client.Timeout = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := client.FetchWorkflowRuns(ctx, "owner/repo")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, asFetchError(err).Retryable)
+	assert.Less(t, time.Since(start), time.Second, "cancelling ends the request")
+	assert.Equal(t, int32(1), calls.Load(), "a cancelled request is not retried")
+
+	// Cancelling while waiting to retry ends the wait.
+	server, _ = faultServer(t, status(500))
+	client = retryClient(server)
+	client.Backoff = time.Minute
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start = time.Now()
+	_, err = client.FetchWorkflowRuns(ctx, "owner/repo")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := range 4 {
+		d := backoff(time.Second, attempt, 0)
+		limit := time.Second << attempt
+		assert.GreaterOrEqual(t, d, limit/2)
+		assert.LessOrEqual(t, d, limit)
+	}
+	assert.LessOrEqual(t, backoff(time.Second, 10, 0), maxBackoff)
+	assert.Equal(t, 5*time.Second, backoff(time.Second, 0, 5*time.Second), "Retry-After is honoured")
+	assert.Equal(t, maxBackoff, backoff(time.Second, 0, time.Hour))
+	assert.Equal(t, time.Duration(0), backoff(0, 2, 0))
+
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+	assert.Equal(t, 2*time.Second, retryAfter(errors.Join(&FetchError{StatusCode: 429, retryAfter: 2 * time.Second})))
+}
+
+func TestRefreshAgainCancelsRefresh(t *testing.T) {
+	m := scheduleModel()
+	tm, cmd := m.Update(runes("r"))
+	m = tm.(model)
+	require.NotNil(t, cmd)
+	assert.Equal(t, fetchNextMsg{index: 0, refresh: 1}, cmd())
+	first := m.refreshCtx
+
+	tm, cmd = m.Update(runes("r"))
+	m = tm.(model)
+	assert.Equal(t, fetchNextMsg{index: 0, refresh: 2}, cmd())
+	assert.ErrorIs(t, first.Err(), context.Canceled, "refreshing again cancels the refresh running")
+	assert.NoError(t, m.refreshCtx.Err())
+
+	tm, cmd = m.Update(fetchedRepoMsg{index: 0, refresh: 1, infos: []workflowInfo{
+		{Repo: "owner/busy", Workflow: "ci", Status: "failure"},
+	}})
+	m = tm.(model)
+	assert.Nil(t, cmd)
+	assert.Equal(t, "in_progress", m.runs[0][0].Status, "results of the cancelled refresh are dropped")
+
+	tm, _ = m.Update(fetchedRepoMsg{index: 0, refresh: 2, infos: []workflowInfo{
+		{Repo: "owner/busy", Workflow: "ci", Status: "success"},
+	}})
+	assert.Equal(t, "success", tm.(model).runs[0][0].Status)
+}
+
+func TestSessionContextCancelsFetches(t *testing.T) {
+	m := scheduleModel()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.withContext(ctx)
+	cancel()
+	assert.Error(t, m.refreshCtx.Err(), "ending the session cancels the refresh")
+}
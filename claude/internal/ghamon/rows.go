@@ -0,0 +1,183 @@
+package ghamon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sortField is a column the table can be sorted by.
+type sortField int
+
+const (
+	sortDefault sortField = iota // repositories in the order given, workflows alphabetically
+	sortRepo
+	sortWorkflow
+	sortStatus
+	sortUpdated
+	sortDuration
+	numSortFields
+)
+
+var sortFieldNames = [numSortFields]string{"default", "repo", "workflow", "status", "updated", "duration"}
+
+func (f sortField) String() string {
+	return sortFieldNames[f]
+}
+
+// parseSortField returns the sort field with the given name, or sortDefault.
+func parseSortField(name string) sortField {
+	for i, n := range sortFieldNames {
+		if n == name {
+			return sortField(i)
+		}
+	}
+	return sortDefault
+}
+
+// severity ranks statuses from most to least in need of attention.
+func severity(status string) int {
+	switch {
+	case failingStatuses[status]:
+		return 0
+	case status == "cancelled" || status == "action_required":
+		return 1
+	case runningStatuses[status]:
+		return 2
+	case status == "success":
+		return 5
+	case status == "skipped" || status == "neutral" || status == "" || status == "...":
+		return 4
+	}
+	return 3
+}
+
+// duration returns how long a run took, or has been running so far.
+func (w workflowInfo) duration(now time.Time) time.Duration {
+	if w.StartedAt.IsZero() {
+		return 0
+	}
+	if runningStatuses[w.Status] {
+		return now.Sub(w.StartedAt)
+	}
+	return w.UpdatedAt.Sub(w.StartedAt)
+}
+
+// sortRows sorts rows in place by field. Ties keep their existing order.
+func sortRows(rows []workflowInfo, field sortField, desc bool, now time.Time) {
+	var less func(a, b workflowInfo) int
+	switch field {
+	case sortRepo:
+		less = func(a, b workflowInfo) int {
+			if c := strings.Compare(strings.ToLower(a.Repo), strings.ToLower(b.Repo)); c != 0 {
+				return c
+			}
+			return strings.Compare(strings.ToLower(a.Workflow), strings.ToLower(b.Workflow))
+		}
+	case sortWorkflow:
+		less = func(a, b workflowInfo) int {
+			return strings.Compare(strings.ToLower(a.Workflow), strings.ToLower(b.Workflow))
+		}
+	case sortStatus:
+		less = func(a, b workflowInfo) int { return severity(a.Status) - severity(b.Status) }
+	case sortUpdated:
+		less = func(a, b workflowInfo) int { return a.UpdatedAt.Compare(b.UpdatedAt) }
+	case sortDuration:
+		less = func(a, b workflowInfo) int { return cmpDuration(a.duration(now), b.duration(now)) }
+	default:
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[i], rows[j]) > 0
+		}
+		return less(rows[i], rows[j]) < 0
+	})
+}
+
+func cmpDuration(a, b time.Duration) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// tableRow is one line of the table: either a workflow or, when grouping by
+// repository, a group header.
+type tableRow struct {
+	header  bool
+	summary string
+	info    workflowInfo
+}
+
+func (r tableRow) key() rowKey {
+	k := r.info.key()
+	k.header = r.header
+	return k
+}
+
+// groupRows arranges rows under a header per repository. Groups appear in the
+// order of their first row, and the rows of collapsed groups are omitted.
+// Summaries count every run of the repository, not just the visible ones.
+func groupRows(rows, all []workflowInfo, collapsed map[string]bool) []tableRow {
+	var order []string
+	byRepo := make(map[string][]workflowInfo)
+	for _, r := range rows {
+		if _, ok := byRepo[r.Repo]; !ok {
+			order = append(order, r.Repo)
+		}
+		byRepo[r.Repo] = append(byRepo[r.Repo], r)
+	}
+	allByRepo := make(map[string][]workflowInfo)
+	for _, r := range all {
+		allByRepo[r.Repo] = append(allByRepo[r.Repo], r)
+	}
+
+	var out []tableRow
+	for _, repo := range order {
+		out = append(out, tableRow{
+			header:  true,
+			summary: summarize(allByRepo[repo]),
+			info:    workflowInfo{Repo: repo},
+		})
+		if collapsed[repo] {
+			continue
+		}
+		for _, r := range byRepo[repo] {
+			out = append(out, tableRow{info: r})
+		}
+	}
+	return out
+}
+
+// summarize describes the health of a group of runs, e.g. "3 ok, 1 failing".
+func summarize(runs []workflowInfo) string {
+	var ok, failing, running, other int
+	for _, r := range runs {
+		switch {
+		case r.Status == "success":
+			ok++
+		case failingStatuses[r.Status]:
+			failing++
+		case runningStatuses[r.Status]:
+			running++
+		case r.Status == "...":
+		default:
+			other++
+		}
+	}
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{{ok, "ok"}, {failing, "failing"}, {running, "running"}, {other, "other"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,112 @@
+package ghamon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func workflowNames(rows []workflowInfo) []string {
+	var names []string
+	for _, r := range rows {
+		names = append(names, r.Workflow)
+	}
+	return names
+}
+
+func TestParseSortField(t *testing.T) {
+	assert.Equal(t, sortStatus, parseSortField("status"))
+	assert.Equal(t, sortDuration, parseSortField("duration"))
+	assert.Equal(t, sortDefault, parseSortField(""))
+	assert.Equal(t, sortDefault, parseSortField("bogus"))
+	for f := sortDefault; f < numSortFields; f++ {
+		assert.Equal(t, f, parseSortField(f.String()))
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := func() []workflowInfo {
+		return []workflowInfo{
+			{Repo: "b/repo", Workflow: "Lint", Status: "success", StartedAt: base, UpdatedAt: base.Add(time.Minute)},
+			{Repo: "a/repo", Workflow: "Deploy", Status: "in_progress", StartedAt: base, UpdatedAt: base.Add(3 * time.Minute)},
+			{Repo: "a/repo", Workflow: "CI", Status: "failure", StartedAt: base, UpdatedAt: base.Add(2 * time.Minute)},
+		}
+	}
+	now := base.Add(10 * time.Minute)
+
+	t.Run("default keeps order", func(t *testing.T) {
+		r := rows()
+		sortRows(r, sortDefault, false, now)
+		assert.Equal(t, []string{"Lint", "Deploy", "CI"}, workflowNames(r))
+	})
+
+	t.Run("by repo then workflow", func(t *testing.T) {
+		r := rows()
+		sortRows(r, sortRepo, false, now)
+		assert.Equal(t, []string{"CI", "Deploy", "Lint"}, workflowNames(r))
+	})
+
+	t.Run("by status severity", func(t *testing.T) {
+		r := rows()
+		sortRows(r, sortStatus, false, now)
+		assert.Equal(t, []string{"CI", "Deploy", "Lint"}, workflowNames(r))
+	})
+
+	t.Run("by last updated descending", func(t *testing.T) {
+		r := rows()
+		sortRows(r, sortUpdated, true, now)
+		assert.Equal(t, []string{"Deploy", "CI", "Lint"}, workflowNames(r))
+	})
+
+	t.Run("by duration counts running time so far", func(t *testing.T) {
+		r := rows()
+		sortRows(r, sortDuration, true, now)
+		assert.Equal(t, []string{"Deploy", "CI", "Lint"}, workflowNames(r))
+	})
+}
+
+func TestGroupRows(t *testing.T) {
+	all := []workflowInfo{
+		{Repo: "a/repo", Workflow: "CI", Status: "failure"},
+		{Repo: "b/repo", Workflow: "Lint", Status: "success"},
+		{Repo: "a/repo", Workflow: "Deploy", Status: "success"},
+	}
+
+	t.Run("groups under headers in order of first row", func(t *testing.T) {
+		rows := groupRows(all, all, map[string]bool{})
+		assert.Len(t, rows, 5)
+		assert.True(t, rows[0].header)
+		assert.Equal(t, "a/repo", rows[0].info.Repo)
+		assert.Equal(t, "1 ok, 1 failing", rows[0].summary)
+		assert.Equal(t, "CI", rows[1].info.Workflow)
+		assert.Equal(t, "Deploy", rows[2].info.Workflow)
+		assert.True(t, rows[3].header)
+		assert.Equal(t, "b/repo", rows[3].info.Repo)
+	})
+
+	t.Run("omits rows of collapsed groups", func(t *testing.T) {
+		rows := groupRows(all, all, map[string]bool{"a/repo": true})
+		assert.Len(t, rows, 3)
+		assert.True(t, rows[0].header)
+		assert.True(t, rows[1].header)
+		assert.Equal(t, "Lint", rows[2].info.Workflow)
+	})
+
+	t.Run("summary counts filtered-out rows", func(t *testing.T) {
+		rows := groupRows(all[:1], all, map[string]bool{})
+		assert.Len(t, rows, 2)
+		assert.Equal(t, "1 ok, 1 failing", rows[0].summary)
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, "3 ok, 1 failing", summarize([]workflowInfo{
+		{Status: "success"}, {Status: "success"}, {Status: "success"}, {Status: "failure"},
+	}))
+	assert.Equal(t, "1 running, 1 other", summarize([]workflowInfo{
+		{Status: "queued"}, {Status: "cancelled"},
+	}))
+	assert.Equal(t, "", summarize([]workflowInfo{{Status: "..."}}))
+}
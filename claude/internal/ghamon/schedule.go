@@ -0,0 +1,145 @@
+package ghamon
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rateSteps are the refresh rates, in seconds, that the rate keys step
+// through.
+var rateSteps = []int{5, 10, 15, 30, 60, 120, 300, 600}
+
+const (
+	// idleFactor is how much less often adaptive mode polls a repository
+	// with nothing running, up to maxIdleInterval.
+	idleFactor      = 10
+	maxIdleInterval = 10 * time.Minute
+	// minTickInterval is the shortest wait between two scheduling ticks.
+	minTickInterval = time.Second
+)
+
+// tickMsg is sent when the refresh schedule may be due. Ticks of an older
+// schedule, before a pause or rate change, carry an outdated id and are
+// dropped.
+type tickMsg struct {
+	at time.Time
+	id int
+}
+
+// fasterRate returns the next rate step below rate, or rate if there is none.
+func fasterRate(rate int) int {
+	for _, r := range slices.Backward(rateSteps) {
+		if r < rate {
+			return r
+		}
+	}
+	return rate
+}
+
+// slowerRate returns the next rate step above rate, or rate if there is none.
+func slowerRate(rate int) int {
+	for _, r := range rateSteps {
+		if r > rate {
+			return r
+		}
+	}
+	return rate
+}
+
+// busy reports whether repository i has runs in progress or queued, has not
+// been fetched yet, or failed to fetch for a reason that may pass.
+func (m model) busy(i int) bool {
+	for _, r := range m.runs[i] {
+		if r.Status == "..." || runningStatuses[r.Status] || r.Err != nil && r.Err.Retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// interval returns how often repository i is fetched: at the refresh rate,
+// or in adaptive mode, idleFactor times less often if nothing is running.
+func (m model) interval(i int) time.Duration {
+	rate := time.Duration(m.rate) * time.Second
+	if !m.adaptive || m.busy(i) {
+		return rate
+	}
+	return max(rate, min(idleFactor*rate, maxIdleInterval))
+}
+
+// due returns when repository i is next to be fetched in adaptive mode.
+func (m model) due(i int) time.Time {
+	return m.fetched[i].Add(m.interval(i))
+}
+
+// schedule starts a new refresh schedule from now, superseding any earlier
+// one. Normally every repository is fetched at the refresh rate; in adaptive
+// mode the tick comes when the next repository is due. A paused model is not
+// scheduled.
+func (m *model) schedule(now time.Time) tea.Cmd {
+	m.tickID++
+	if m.paused {
+		m.nextRefresh = time.Time{}
+		return nil
+	}
+	m.nextRefresh = now.Add(time.Duration(m.rate) * time.Second)
+	if m.adaptive {
+		next := time.Time{}
+		for i := range m.repos {
+			if !m.pending[i] && (next.IsZero() || m.due(i).Before(next)) {
+				next = m.due(i)
+			}
+		}
+		if !next.IsZero() {
+			m.nextRefresh = next
+		}
+		if earliest := now.Add(minTickInterval); m.nextRefresh.Before(earliest) {
+			m.nextRefresh = earliest
+		}
+	}
+	id := m.tickID
+	return tea.Tick(m.nextRefresh.Sub(now), func(t time.Time) tea.Msg {
+		return tickMsg{at: t, id: id}
+	})
+}
+
+// fetchDue starts fetching the repositories that are due in adaptive mode,
+// each on its own, and schedules the next tick. Nothing is started while a
+// full refresh is running.
+func (m *model) fetchDue(now time.Time) tea.Cmd {
+	var cmds []tea.Cmd
+	if !m.fetching {
+		for i := range m.repos {
+			if !m.pending[i] && !now.Before(m.due(i)) {
+				m.pending[i] = true
+				cmds = append(cmds, m.fetchRepo(i, true))
+			}
+		}
+	}
+	return tea.Batch(append(cmds, m.schedule(now))...)
+}
+
+// fetchedAlone records a repository fetched on its own schedule.
+func (m *model) fetchedAlone(msg fetchedRepoMsg) tea.Cmd {
+	delete(m.pending, msg.index)
+	if m.upToDate() {
+		m.lastRefresh = m.fetched[msg.index]
+	}
+	m.followSelection()
+	return tea.Batch(m.updateTitle(), m.saveSnapshot())
+}
+
+// rateLabel describes the refresh schedule for the header, e.g. "30s",
+// "30s adaptive" or "paused".
+func (m model) rateLabel() string {
+	switch {
+	case m.paused:
+		return "paused"
+	case m.adaptive:
+		return fmt.Sprintf("%ds adaptive", m.rate)
+	}
+	return fmt.Sprintf("%ds", m.rate)
+}
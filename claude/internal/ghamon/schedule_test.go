@@ -0,0 +1,102 @@
+package ghamon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateSteps(t *testing.T) {
+	assert.Equal(t, 15, fasterRate(30))
+	assert.Equal(t, 30, fasterRate(45))
+	assert.Equal(t, 5, fasterRate(5))
+	assert.Equal(t, 60, slowerRate(30))
+	assert.Equal(t, 60, slowerRate(45))
+	assert.Equal(t, 600, slowerRate(600))
+}
+
+func scheduleModel() model {
+	m := newModel(Options{Repos: []string{"owner/busy", "owner/idle"}, Rate: 30}, DefaultSettings())
+	m.runs = [][]workflowInfo{
+		{{Repo: "owner/busy", Workflow: "ci", Status: "in_progress"}},
+		{{Repo: "owner/idle", Workflow: "ci", Status: "success"}},
+	}
+	m.fetching = false
+	return m
+}
+
+func TestRefreshInterval(t *testing.T) {
+	m := scheduleModel()
+	assert.Equal(t, 30*time.Second, m.interval(0))
+	assert.Equal(t, 30*time.Second, m.interval(1), "every repository at the rate unless adaptive")
+
+	m.adaptive = true
+	assert.Equal(t, 30*time.Second, m.interval(0))
+	assert.Equal(t, 5*time.Minute, m.interval(1))
+	m.rate = 120
+	assert.Equal(t, 10*time.Minute, m.interval(1), "idle interval is capped")
+	m.rate = 600
+	assert.Equal(t, 10*time.Minute, m.interval(1), "idle interval is never below the rate")
+}
+
+func TestPauseDropsTicks(t *testing.T) {
+	m := scheduleModel()
+	tm, cmd := m.Update(runes("P"))
+	m = tm.(model)
+	assert.True(t, m.paused)
+	assert.Nil(t, cmd, "a paused model schedules no tick")
+	assert.Contains(t, m.header(), "Refresh: paused")
+	assert.Contains(t, m.refreshStatus(time.Now()), "paused")
+
+	tm, cmd = m.Update(tickMsg{at: time.Now(), id: m.tickID - 1})
+	m = tm.(model)
+	assert.Nil(t, cmd)
+	assert.False(t, m.fetching, "ticks from before the pause are dropped")
+
+	tm, cmd = m.Update(runes("P"))
+	m = tm.(model)
+	assert.False(t, m.paused)
+	assert.NotNil(t, cmd)
+	tm, _ = m.Update(tickMsg{at: time.Now(), id: m.tickID})
+	assert.True(t, tm.(model).fetching, "the resumed schedule refreshes")
+}
+
+func TestChangeRate(t *testing.T) {
+	m := scheduleModel()
+	now := time.Now()
+	tm, _ := m.Update(runes("-"))
+	m = tm.(model)
+	assert.Equal(t, 60, m.rate)
+	assert.WithinDuration(t, now.Add(time.Minute), m.nextRefresh, time.Second)
+	tm, _ = m.Update(runes("+"))
+	tm, _ = tm.Update(runes("+"))
+	m = tm.(model)
+	assert.Equal(t, 15, m.rate)
+	assert.Contains(t, m.header(), "Refresh: 15s")
+}
+
+func TestAdaptiveFetchesDueRepos(t *testing.T) {
+	m := scheduleModel()
+	tm, _ := m.Update(runes("~"))
+	m = tm.(model)
+	assert.True(t, m.adaptive)
+	assert.Contains(t, m.header(), "Refresh: 30s adaptive")
+
+	now := time.Now()
+	m.fetched = []time.Time{now.Add(-time.Minute), now.Add(-time.Minute)}
+	cmd := m.fetchDue(now)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, map[int]bool{0: true}, m.pending, "only the busy repository is due")
+	assert.Equal(t, now.Add(4*time.Minute), m.nextRefresh, "next tick when the idle repository is due")
+
+	tm, _ = m.Update(fetchedRepoMsg{index: 0, alone: true, infos: []workflowInfo{
+		{Repo: "owner/busy", Workflow: "ci", Status: "success"},
+	}})
+	m = tm.(model)
+	assert.Empty(t, m.pending)
+	assert.False(t, m.fetching, "a repository fetched alone does not start a full refresh")
+	assert.Equal(t, "success", m.runs[0][0].Status)
+	assert.False(t, m.lastRefresh.IsZero())
+	assert.Equal(t, "✓ ghamon: passing", m.title)
+}
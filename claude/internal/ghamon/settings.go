@@ -0,0 +1,67 @@
+package ghamon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// settingsFile is the name of the preferences file in ~/.ghamon.
+const settingsFile = "settings.json"
+
+// Settings holds user preferences that are remembered between sessions.
+type Settings struct {
+	Sort     string `json:"sort,omitempty"`
+	SortDesc bool   `json:"sort_desc,omitempty"`
+	Grouped  bool   `json:"grouped,omitempty"`
+	Timing   bool   `json:"timing,omitempty"`
+
+	// Collapsed lists the repositories whose group is folded, in order.
+	Collapsed []string `json:"collapsed,omitempty"`
+
+	// SlowFactor flags runs taking longer than this multiple of their
+	// workflow's median duration. Zero disables the check.
+	SlowFactor float64 `json:"slow_factor"`
+
+	// Theme names the colour theme: a built-in one or a file in
+	// ~/.ghamon/themes.
+	Theme string `json:"theme,omitempty"`
+
+	// Adaptive polls repositories with nothing running less often.
+	Adaptive bool `json:"adaptive,omitempty"`
+}
+
+// DefaultSettings returns the settings used when none are saved.
+func DefaultSettings() Settings {
+	return Settings{SlowFactor: 2}
+}
+
+// LoadSettings reads settings from a JSON file. Fields missing from the file,
+// or a missing file, take their default values.
+func LoadSettings(path string) (Settings, error) {
+	s := DefaultSettings()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveSettings writes settings to a JSON file, creating its directory if needed.
+func SaveSettings(path string, s Settings) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,54 @@
+package ghamon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSettings(t *testing.T) {
+	t.Run("returns defaults for missing file", func(t *testing.T) {
+		s, err := LoadSettings(filepath.Join(t.TempDir(), "settings.json"))
+		require.NoError(t, err)
+		assert.Equal(t, DefaultSettings(), s)
+	})
+
+	t.Run("keeps defaults for missing fields", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "settings.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"sort": "status"}`), 0644))
+		s, err := LoadSettings(path)
+		require.NoError(t, err)
+		assert.Equal(t, "status", s.Sort)
+		assert.Equal(t, 2.0, s.SlowFactor)
+	})
+
+	t.Run("returns error for invalid JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "settings.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+		_, err := LoadSettings(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "settings.json")
+	want := Settings{Sort: "status", SortDesc: true, Grouped: true, Collapsed: []string{"owner/repo"}, Timing: true, SlowFactor: 3}
+	require.NoError(t, SaveSettings(path, want))
+
+	got, err := LoadSettings(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestModelSettings_Collapsed(t *testing.T) {
+	s := DefaultSettings()
+	s.Grouped, s.Collapsed = true, []string{"owner/b"}
+	m := newModel(Options{Repos: []string{"owner/a", "owner/b"}, Rate: 30}, s)
+	assert.True(t, m.collapsed["owner/b"], "folded groups are restored")
+
+	m.collapsed["owner/a"], m.collapsed["owner/b"] = true, false
+	assert.Equal(t, []string{"owner/a"}, m.settings().Collapsed)
+}
@@ -0,0 +1,201 @@
+package ghamon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snapshotFile is the name of the file in ~/.ghamon holding the last rows
+// fetched for each repository, shown on the next start until they are
+// fetched again.
+const snapshotFile = "snapshot.json"
+
+// snapshot holds the last rows fetched without error, by snapshotKey.
+type snapshot struct {
+	Repos map[string]repoSnapshot `json:"repos"`
+}
+
+type repoSnapshot struct {
+	FetchedAt time.Time      `json:"fetched_at"`
+	Rows      []workflowInfo `json:"rows"`
+}
+
+// snapshotMu serializes saving the snapshot within this process; a lock on
+// the snapshot's lock file does so across dashboards in several tabs.
+var snapshotMu sync.Mutex
+
+// loadSnapshot reads a snapshot from a JSON file. A missing file is an empty
+// snapshot.
+func loadSnapshot(path string) (snapshot, error) {
+	s := snapshot{Repos: make(map[string]repoSnapshot)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return snapshot{Repos: make(map[string]repoSnapshot)}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if s.Repos == nil {
+		s.Repos = make(map[string]repoSnapshot)
+	}
+	return s, nil
+}
+
+// saveSnapshot adds repos to the snapshot in a JSON file, keeping the other
+// repositories in it. The file is locked while it is read and written back,
+// and replaced by renaming a temporary file over it, so that a reader never
+// sees it half written.
+func saveSnapshot(path string, repos map[string]repoSnapshot) error {
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Rows that cannot be read are lost anyway; saving over them keeps
+	// the snapshot working.
+	s, _ := loadSnapshot(path)
+	for key, r := range repos {
+		s.Repos[key] = r
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, snapshotFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// snapshotKey identifies repository index in the snapshot. What is fetched
+// for it depends on the workflow and checks options too.
+func (m model) snapshotKey(index int) string {
+	key := m.repos[index]
+	if m.workflow != "" {
+		key += " workflow:" + m.workflow
+	}
+	if m.checks {
+		key += " checks:" + m.branch
+	}
+	return key
+}
+
+// restore shows the rows of s in place of the placeholders, as stale until
+// their repositories are fetched again.
+func (m *model) restore(s snapshot) {
+	for i := range m.repos {
+		if r, ok := s.Repos[m.snapshotKey(i)]; ok && len(r.Rows) > 0 {
+			m.runs[i] = r.Rows
+			m.stale[i] = r.FetchedAt
+		}
+	}
+	m.followSelection()
+}
+
+// offline reports whether infos stands for a repository that could not be
+// fetched because GitHub could not be reached.
+func offline(infos []workflowInfo) bool {
+	return len(infos) == 1 && infos[0].Err != nil &&
+		infos[0].Err.StatusCode == 0 && infos[0].Err.Retryable
+}
+
+// fetchable reports whether rows are ones fetched from GitHub, rather than a
+// placeholder or an error.
+func fetchable(rows []workflowInfo) bool {
+	return len(rows) > 0 && !slices.ContainsFunc(rows, func(r workflowInfo) bool {
+		return r.Status == "..." || r.Err != nil
+	})
+}
+
+// storeRepo shows the rows fetched for repository index at now. While
+// GitHub cannot be reached, the rows shown before are kept, as stale, rather
+// than replaced by the error.
+func (m *model) storeRepo(index int, infos []workflowInfo, now time.Time) {
+	if !offline(infos) {
+		m.offlineSince = time.Time{}
+		m.runs[index] = infos
+		m.stale[index] = time.Time{}
+		return
+	}
+	if m.offlineSince.IsZero() {
+		m.offlineSince = now
+	}
+	if !fetchable(m.runs[index]) {
+		m.runs[index] = infos
+		return
+	}
+	if m.stale[index].IsZero() {
+		m.stale[index] = m.fetched[index]
+	}
+}
+
+// staleSince returns when the oldest rows kept from before were fetched, or
+// zero if every repository shows what it was last fetched.
+func (m model) staleSince() time.Time {
+	var oldest time.Time
+	for _, t := range m.stale {
+		if !t.IsZero() && (oldest.IsZero() || t.Before(oldest)) {
+			oldest = t
+		}
+	}
+	return oldest
+}
+
+// upToDate reports whether every repository shows rows fetched without
+// error.
+func (m model) upToDate() bool {
+	return countHealth(m.flatRuns()).errors == 0 && m.staleSince().IsZero()
+}
+
+// saveSnapshot returns a command saving the rows of the repositories fetched
+// without error, or nil if the model has no snapshot file.
+func (m model) saveSnapshot() tea.Cmd {
+	if m.snapshotPath == "" {
+		return nil
+	}
+	repos := make(map[string]repoSnapshot)
+	for i, rows := range m.runs {
+		if m.stale[i].IsZero() && !m.fetched[i].IsZero() && fetchable(rows) {
+			repos[m.snapshotKey(i)] = repoSnapshot{FetchedAt: m.fetched[i], Rows: slices.Clone(rows)}
+		}
+	}
+	if len(repos) == 0 {
+		return nil
+	}
+	path := m.snapshotPath
+	return func() tea.Msg {
+		// The snapshot only spares waiting on the next start; a failure to
+		// save it is not worth interrupting the session for.
+		_ = saveSnapshot(path, repos)
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package ghamon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ghamon", snapshotFile)
+	s, err := loadSnapshot(path)
+	require.NoError(t, err, "a missing snapshot is empty")
+	assert.Empty(t, s.Repos)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, saveSnapshot(path, map[string]repoSnapshot{
+		"owner/a": {FetchedAt: at, Rows: []workflowInfo{{Repo: "owner/a", Workflow: "ci", Status: "success"}}},
+		"owner/b": {FetchedAt: at, Rows: []workflowInfo{{Repo: "owner/b", Workflow: "ci", Status: "failure"}}},
+	}))
+	require.NoError(t, saveSnapshot(path, map[string]repoSnapshot{
+		"owner/b": {FetchedAt: at.Add(time.Minute), Rows: []workflowInfo{{Repo: "owner/b", Workflow: "ci", Status: "success"}}},
+	}))
+
+	s, err = loadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, "success", s.Repos["owner/a"].Rows[0].Status, "other repositories are kept")
+	assert.Equal(t, "success", s.Repos["owner/b"].Rows[0].Status)
+	assert.True(t, at.Add(time.Minute).Equal(s.Repos["owner/b"].FetchedAt))
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err = loadSnapshot(path)
+	assert.Error(t, err)
+	require.NoError(t, saveSnapshot(path, map[string]repoSnapshot{"owner/c": {FetchedAt: at}}), "a corrupt snapshot is replaced")
+	s, err = loadSnapshot(path)
+	require.NoError(t, err)
+	assert.Contains(t, s.Repos, "owner/c")
+}
+
+func TestSaveSnapshot_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, snapshotFile)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo := fmt.Sprintf("owner/r%d", i)
+			assert.NoError(t, saveSnapshot(path, map[string]repoSnapshot{repo: {Rows: []workflowInfo{{Repo: repo}}}}))
+		}()
+	}
+	wg.Wait()
+
+	s, err := loadSnapshot(path)
+	require.NoError(t, err)
+	assert.Len(t, s.Repos, 8, "no save loses another's rows")
+	tmps, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, tmps, "temporary files are cleaned up")
+}
+
+func TestSnapshotKey(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/repo"}}, DefaultSettings())
+	assert.Equal(t, "owner/repo", m.snapshotKey(0))
+	m = newModel(Options{Repos: []string{"owner/repo"}, Workflow: "ci", Checks: true, Branch: "main"}, DefaultSettings())
+	assert.Equal(t, "owner/repo workflow:ci checks:main", m.snapshotKey(0))
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/known", "owner/new"}, Rate: 30}, DefaultSettings())
+	now := time.Now()
+	m.restore(snapshot{Repos: map[string]repoSnapshot{
+		"owner/known": {FetchedAt: now.Add(-2 * time.Hour), Rows: []workflowInfo{
+			{Repo: "owner/known", Workflow: "ci", Status: "failure"},
+		}},
+		"owner/other": {FetchedAt: now, Rows: []workflowInfo{{Repo: "owner/other", Status: "success"}}},
+	}})
+	assert.Equal(t, "failure", m.runs[0][0].Status, "the last known rows are shown")
+	assert.Equal(t, "...", m.runs[1][0].Status)
+	assert.Contains(t, m.healthBar(now), "stale data from 2h00m ago")
+	assert.Contains(t, m.View(), "owner/known")
+
+	tm, _ := m.Update(fetchedRepoMsg{index: 0, infos: []workflowInfo{{Repo: "owner/known", Workflow: "ci", Status: "success"}}})
+	tm, _ = tm.Update(fetchedRepoMsg{index: 1, infos: []workflowInfo{{Repo: "owner/new", Workflow: "ci", Status: "success"}}})
+	m = tm.(model)
+	assert.Equal(t, "success", m.runs[0][0].Status)
+	assert.True(t, m.staleSince().IsZero())
+	assert.NotContains(t, m.healthBar(now), "stale")
+	assert.False(t, m.lastRefresh.IsZero())
+}
+
+func TestOffline(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/repo", "owner/new"}, Rate: 30}, DefaultSettings())
+	tm, _ := m.Update(fetchedRepoMsg{index: 0, infos: []workflowInfo{{Repo: "owner/repo", Workflow: "ci", Status: "success"}}})
+	tm, _ = tm.Update(fetchedRepoMsg{index: 1, infos: []workflowInfo{{Repo: "owner/new", Workflow: "ci", Status: "success"}}})
+	m = tm.(model)
+	fetchedAt, lastRefresh := m.fetched[0], m.lastRefresh
+
+	unreachable := errors.New("fetching owner/repo: dial tcp: no such host")
+	tm, _ = m.Update(tickMsg{at: time.Now(), id: m.tickID})
+	m = tm.(model)
+	tm, _ = m.Update(fetchedRepoMsg{index: 0, refresh: m.refreshID, infos: []workflowInfo{errorInfo("owner/repo", "", unreachable)}})
+	m = tm.(model)
+	m.runs[1] = placeholderRuns([]string{"owner/new"})[0]
+	tm, _ = m.Update(fetchedRepoMsg{index: 1, refresh: m.refreshID, infos: []workflowInfo{errorInfo("owner/new", "", unreachable)}})
+	m = tm.(model)
+
+	assert.Equal(t, "success", m.runs[0][0].Status, "the rows are kept while offline")
+	assert.Equal(t, "error", m.runs[1][0].Status, "with no rows to keep the error is shown")
+	assert.Equal(t, fetchedAt, m.stale[0])
+	assert.False(t, m.offlineSince.IsZero())
+	bar := m.healthBar(time.Now())
+	assert.Contains(t, bar, "offline since "+formatClock(m.offlineSince))
+	assert.Contains(t, bar, "stale data from")
+	assert.Equal(t, lastRefresh, m.lastRefresh)
+
+	offlineSince := m.offlineSince
+	tm, _ = m.Update(fetchedRepoMsg{index: 0, alone: true, infos: []workflowInfo{errorInfo("owner/repo", "", unreachable)}})
+	m = tm.(model)
+	assert.Equal(t, offlineSince, m.offlineSince, "offline since the first failure")
+	assert.Equal(t, fetchedAt, m.stale[0], "the rows are as old as when they were fetched")
+
+	tm, _ = m.Update(fetchedRepoMsg{index: 0, alone: true, infos: []workflowInfo{errorInfo("owner/repo", "", &FetchError{StatusCode: 404})}})
+	m = tm.(model)
+	assert.True(t, m.offlineSince.IsZero(), "any answer from GitHub ends the offline state")
+	assert.Equal(t, "error", m.runs[0][0].Status, "errors from GitHub are shown")
+	assert.True(t, m.staleSince().IsZero())
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	m := newModel(Options{Repos: []string{"owner/ok", "owner/broken", "owner/pending"}, Rate: 30}, DefaultSettings())
+	assert.Nil(t, m.saveSnapshot(), "nothing is saved without a snapshot file")
+
+	m.snapshotPath = filepath.Join(t.TempDir(), snapshotFile)
+	tm, _ := m.Update(fetchedRepoMsg{index: 0, infos: []workflowInfo{{Repo: "owner/ok", Workflow: "ci", Status: "success"}}})
+	tm, _ = tm.Update(fetchedRepoMsg{index: 1, infos: []workflowInfo{errorInfo("owner/broken", "", &FetchError{StatusCode: 404})}})
+	tm, _ = tm.Update(fetchedRepoMsg{index: 2})
+	m = tm.(model)
+	cmd := m.saveSnapshot()
+	require.NotNil(t, cmd)
+	cmd()
+
+	s, err := loadSnapshot(m.snapshotPath)
+	require.NoError(t, err)
+	assert.Len(t, s.Repos, 1, "only rows fetched without error are saved")
+	assert.Equal(t, "success", s.Repos["owner/ok"].Rows[0].Status)
+}
@@ -0,0 +1,210 @@
+package ghamon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Tab is a dashboard: a named set of repositories shown in its own tab.
+type Tab struct {
+	Name  string
+	Repos []string
+}
+
+var (
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1)
+	activeTabStyle = tabStyle.Reverse(true).Bold(true)
+)
+
+// tabMsg carries a message for the dashboard in tab index, so that each
+// dashboard's fetches and ticks reach it and no other.
+type tabMsg struct {
+	index int
+	msg   tea.Msg
+}
+
+// dashboards shows one dashboard per tab. Each tab is a full model with its
+// own repositories, filters, sort and fetch schedule; only the active one is
+// drawn, below a tab bar.
+type dashboards struct {
+	names  []string
+	tabs   []model
+	active int
+	keys   keyMap
+	title  string // the window title last set
+}
+
+func newDashboards(opts Options, settings Settings, keys keyMap) dashboards {
+	d := dashboards{keys: keys}
+	for _, tab := range opts.Tabs {
+		tabOpts := opts
+		tabOpts.Repos = tab.Repos
+		m := newModel(tabOpts, settings)
+		m.keys = keys
+		d.names = append(d.names, tab.Name)
+		d.tabs = append(d.tabs, m)
+	}
+	return d
+}
+
+// tagCmd returns cmd with its messages addressed to tab index. Batches are
+// tagged command by command; quitting is left alone.
+func tagCmd(index int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			cmds := make([]tea.Cmd, len(msg))
+			for i, c := range msg {
+				cmds[i] = tagCmd(index, c)
+			}
+			return tea.BatchMsg(cmds)
+		case tea.QuitMsg, nil:
+			return msg
+		default:
+			return tabMsg{index: index, msg: msg}
+		}
+	}
+}
+
+func (d dashboards) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, len(d.tabs))
+	for i, m := range d.tabs {
+		cmds[i] = tagCmd(i, m.Init())
+	}
+	return tea.Batch(cmds...)
+}
+
+// update passes msg to the dashboard in tab index. The window title sums up
+// every tab, so the titles the dashboards set are dropped with their other
+// untagged messages.
+func (d dashboards) update(index int, msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := d.tabs[index].Update(msg)
+	d.tabs[index] = m.(model)
+	title := d.updateTitle()
+	return d, tea.Batch(tagCmd(index, cmd), title)
+}
+
+// updateTitle returns a command setting the window title to the worst state
+// of any tab and the failing count of all of them, if it changed.
+func (d *dashboards) updateTitle() tea.Cmd {
+	worst, failing := statePending, 0
+	for _, m := range d.tabs {
+		h, state := m.health()
+		worst = max(worst, state)
+		failing += h.failure + h.errors
+	}
+	return setTitle(&d.title, windowTitle(worst, failing))
+}
+
+func (d dashboards) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tabMsg:
+		return d.update(msg.index, msg.msg)
+	case tea.WindowSizeMsg:
+		// The tab bar takes the top line from every dashboard.
+		msg.Height -= d.barLines()
+		var cmds []tea.Cmd
+		for i := range d.tabs {
+			m, cmd := d.tabs[i].Update(msg)
+			d.tabs[i] = m.(model)
+			cmds = append(cmds, tagCmd(i, cmd))
+		}
+		return d, tea.Batch(cmds...)
+	case tea.KeyMsg:
+		active := d.tabs[d.active]
+		if active.searching || active.showHelp {
+			break
+		}
+		switch {
+		case key.Matches(msg, d.keys.NextTab):
+			d.active = (d.active + 1) % len(d.tabs)
+			return d, nil
+		case key.Matches(msg, d.keys.PrevTab):
+			d.active = (d.active + len(d.tabs) - 1) % len(d.tabs)
+			return d, nil
+		case key.Matches(msg, d.keys.GotoTab):
+			if i := slices.Index(d.keys.GotoTab.Keys(), msg.String()); i >= 0 && i < len(d.tabs) {
+				d.active = i
+			}
+			return d, nil
+		}
+	case tea.MouseMsg:
+		msg.Y -= d.barLines()
+		if msg.Y < 0 {
+			if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
+				if i, ok := d.tabAt(msg.X); ok {
+					d.active = i
+				}
+			}
+			return d, nil
+		}
+		return d.update(d.active, msg)
+	}
+	return d.update(d.active, msg)
+}
+
+// barLines returns the height of the tab bar.
+func (d dashboards) barLines() int {
+	return lipgloss.Height(d.tabBar())
+}
+
+// tabLabel returns the label of tab i, e.g. "2 mobile", and its failing
+// count, e.g. " (3 failing)", if any runs fail.
+func (d dashboards) tabLabel(i int) (label, failing string) {
+	label = fmt.Sprintf("%d %s", i+1, d.names[i])
+	if n := d.tabs[i].failingCount(); n > 0 {
+		failing = fmt.Sprintf(" (%d failing)", n)
+	}
+	return label, failing
+}
+
+// tabBar renders the tabs with the active one highlighted.
+func (d dashboards) tabBar() string {
+	var b strings.Builder
+	for i := range d.tabs {
+		label, failing := d.tabLabel(i)
+		if i == d.active {
+			b.WriteString(activeTabStyle.Render(label + failing))
+		} else {
+			b.WriteString(tabStyle.Render(label + statusStyles["failure"].Render(failing)))
+		}
+	}
+	return b.String()
+}
+
+// tabAt returns the tab whose label is at column x of the tab bar.
+func (d dashboards) tabAt(x int) (int, bool) {
+	start := 0
+	for i := range d.tabs {
+		label, failing := d.tabLabel(i)
+		end := start + lipgloss.Width(tabStyle.Render(label+failing))
+		if x >= start && x < end {
+			return i, true
+		}
+		start = end
+	}
+	return 0, false
+}
+
+func (d dashboards) View() string {
+	return d.tabBar() + "\n" + d.tabs[d.active].View()
+}
+
+// failingCount returns the number of failing rows, whatever the filter.
+func (m model) failingCount() int {
+	n := 0
+	for _, r := range m.flatRuns() {
+		if failingStatuses[r.Status] {
+			n++
+		}
+	}
+	return n
+}
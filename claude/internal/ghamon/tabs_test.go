@@ -0,0 +1,92 @@
+package ghamon
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDashboards() dashboards {
+	opts := Options{Rate: 30, Tabs: []Tab{
+		{Name: "platform", Repos: []string{"owner/api"}},
+		{Name: "mobile", Repos: []string{"owner/ios", "owner/android"}},
+	}}
+	d := newDashboards(opts, DefaultSettings(), defaultKeyMap())
+	dm, _ := d.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return dm.(dashboards)
+}
+
+func TestDashboardsSwitchTabs(t *testing.T) {
+	d := testDashboards()
+	assert.Equal(t, 0, d.active)
+
+	dm, _ := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	assert.Equal(t, 1, dm.(dashboards).active)
+
+	dm, _ = dm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, 0, dm.(dashboards).active, "tab wraps around")
+
+	dm, _ = dm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	assert.Equal(t, 1, dm.(dashboards).active)
+
+	dm, _ = dm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9")})
+	assert.Equal(t, 1, dm.(dashboards).active, "missing tabs are ignored")
+}
+
+func TestDashboardsKeepStatePerTab(t *testing.T) {
+	d := testDashboards()
+	dm, _ := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	dm, _ = dm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	d = dm.(dashboards)
+	assert.True(t, d.tabs[0].filter.active())
+	assert.False(t, d.tabs[1].filter.active())
+	assert.Equal(t, 29, d.tabs[1].windowHeight, "the tab bar takes a line")
+}
+
+func TestDashboardsRouteMessages(t *testing.T) {
+	d := testDashboards()
+	infos := []workflowInfo{{Repo: "owner/android", Workflow: "build", Status: "failure"}}
+	dm, _ := d.Update(tabMsg{index: 1, msg: fetchedRepoMsg{index: 1, infos: infos}})
+	d = dm.(dashboards)
+	assert.Equal(t, infos, d.tabs[1].runs[1])
+	assert.NotEqual(t, infos, d.tabs[0].runs[0], "other tabs are left alone")
+
+	assert.Equal(t, 0, d.tabs[0].failingCount())
+	assert.Equal(t, 1, d.tabs[1].failingCount())
+	assert.Contains(t, d.View(), "2 mobile (1 failing)")
+}
+
+func TestTagCmd(t *testing.T) {
+	assert.Nil(t, tagCmd(0, nil))
+
+	msg := tagCmd(2, func() tea.Msg { return fetchNextMsg{index: 1} })()
+	assert.Equal(t, tabMsg{index: 2, msg: fetchNextMsg{index: 1}}, msg)
+
+	assert.Equal(t, tea.QuitMsg{}, tagCmd(2, tea.Quit)())
+
+	batch, ok := tagCmd(1, tea.Batch(
+		func() tea.Msg { return resetProgressMsg{} },
+		func() tea.Msg { return animationTickMsg{} },
+	))().(tea.BatchMsg)
+	require.True(t, ok)
+	require.Len(t, batch, 2)
+	assert.Equal(t, tabMsg{index: 1, msg: resetProgressMsg{}}, batch[0]())
+}
+
+func TestDashboardsMouse(t *testing.T) {
+	d := testDashboards()
+	label, failing := d.tabLabel(0)
+	x := tabStyle.GetHorizontalPadding() + len(label+failing) + 2
+	dm, _ := d.Update(click(x, 0))
+	assert.Equal(t, 1, dm.(dashboards).active, "clicking a tab selects it")
+
+	d = dm.(dashboards)
+	d.tabs[1].runs = [][]workflowInfo{
+		{{Repo: "owner/ios", Workflow: "a", Status: "success"}},
+		{{Repo: "owner/android", Workflow: "b", Status: "success"}},
+	}
+	dm, _ = d.Update(click(10, d.barLines()+d.tabs[1].headerLines()+1))
+	assert.Equal(t, 1, dm.(dashboards).tabs[1].cursor, "clicks below the tab bar reach the tab")
+}
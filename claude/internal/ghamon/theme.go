@@ -0,0 +1,181 @@
+package ghamon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// themesDir is the directory in ~/.ghamon holding user-defined themes.
+const themesDir = "themes"
+
+// defaultTheme is the theme used when none is chosen.
+const defaultTheme = "dark"
+
+// Theme is a colour palette. Colours are ANSI 256 colour numbers (e.g. "196")
+// or hex values (e.g. "#d55e00"); an empty colour leaves the terminal's
+// default.
+type Theme struct {
+	Title   string `json:"title"`
+	Muted   string `json:"muted"`  // footer and skipped rows
+	Accent  string `json:"accent"` // column headers
+	Text    string `json:"text"`   // statuses without a colour of their own
+	Success string `json:"success"`
+	Failure string `json:"failure"`
+	Running string `json:"running"` // in progress, queued and pending
+	Warning string `json:"warning"` // cancelled and slow runs
+
+	// Icons prefixes statuses with ✓ ✗ ● ○ so they can be told apart
+	// without colour.
+	Icons bool `json:"icons"`
+}
+
+var builtinThemes = map[string]Theme{
+	"dark": {
+		Title: "205", Muted: "241", Accent: "33", Text: "252",
+		Success: "40", Failure: "196", Running: "220", Warning: "208", Icons: true,
+	},
+	"light": {
+		Title: "127", Muted: "244", Accent: "25", Text: "235",
+		Success: "28", Failure: "160", Running: "136", Warning: "166", Icons: true,
+	},
+	"high-contrast": {
+		Title: "15", Muted: "250", Accent: "14", Text: "15",
+		Success: "10", Failure: "9", Running: "11", Warning: "13", Icons: true,
+	},
+	// Blue and orange from the Okabe-Ito palette, which stay distinct for
+	// red-green colour blindness.
+	"deuteranopia": {
+		Title: "#cc79a7", Muted: "244", Accent: "#56b4e9", Text: "252",
+		Success: "#0072b2", Failure: "#d55e00", Running: "#f0e442", Warning: "#e69f00", Icons: true,
+	},
+}
+
+// Styles coloured by the theme. applyTheme assigns them.
+var (
+	titleStyle         lipgloss.Style
+	footerStyle        lipgloss.Style
+	columnStyle        lipgloss.Style
+	slowStyle          lipgloss.Style
+	statusStyles       map[string]lipgloss.Style
+	defaultStatusStyle lipgloss.Style
+	showIcons          bool
+)
+
+func init() {
+	applyTheme(builtinThemes[defaultTheme])
+}
+
+// themeNames returns the names of the built-in themes, sorted.
+func themeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTheme returns the named theme: a user-defined theme from dir/<name>.json
+// if there is one, else a built-in theme. Colours missing from a user-defined
+// theme are taken from the dark theme.
+func LoadTheme(dir, name string) (Theme, error) {
+	if name == "" {
+		name = defaultTheme
+	}
+	t := builtinThemes[defaultTheme]
+	path := filepath.Join(dir, name+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return t, fmt.Errorf("reading theme file %s: %w", path, err)
+		}
+		builtin, ok := builtinThemes[name]
+		if !ok {
+			return t, fmt.Errorf("unknown theme %q (built-in themes: %v)", name, themeNames())
+		}
+		return builtin, nil
+	}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return builtinThemes[defaultTheme], fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// noColorTheme is a theme without colours that relies on status icons, for
+// users who set NO_COLOR (see https://no-color.org).
+func noColorTheme() Theme {
+	return Theme{Icons: true}
+}
+
+// applyTheme colours the TUI with t.
+func applyTheme(t Theme) {
+	fg := func(c string) lipgloss.Style {
+		if c == "" {
+			return lipgloss.NewStyle()
+		}
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+	}
+	success, failure, running, warning, muted := fg(t.Success), fg(t.Failure), fg(t.Running), fg(t.Warning), fg(t.Muted)
+
+	titleStyle = fg(t.Title).Bold(true)
+	footerStyle = muted.Faint(t.Muted == "")
+	columnStyle = fg(t.Accent)
+	slowStyle = warning.Bold(true)
+
+	statusStyles = map[string]lipgloss.Style{
+		"success":   success,
+		"cancelled": warning,
+		"skipped":   muted,
+		"neutral":   muted,
+	}
+	for s := range failingStatuses {
+		statusStyles[s] = failure
+	}
+	for s := range runningStatuses {
+		statusStyles[s] = running
+	}
+	defaultStatusStyle = fg(t.Text)
+	showIcons = t.Icons
+}
+
+// statusIcon returns the symbol that tells a status apart without colour, or
+// a space for statuses without one.
+func statusIcon(status string) string {
+	switch {
+	case status == "success":
+		return "✓"
+	case failingStatuses[status]:
+		return "✗"
+	case status == "in_progress":
+		return "●"
+	case runningStatuses[status]:
+		return "○"
+	}
+	return " "
+}
+
+// renderStatus renders a status cell padded to width, with its icon if the
+// theme shows icons. suffix (the running animation) is appended to the text.
+// The colour is left off the selected row so the selection stays readable.
+func renderStatus(status, suffix string, width int, selected bool) string {
+	text := status + suffix
+	if showIcons {
+		text = statusIcon(status) + " " + text
+	}
+	if width > 0 {
+		text = fitCell(text, width)
+	}
+	if selected {
+		return text
+	}
+	style, ok := statusStyles[status]
+	if !ok {
+		style = defaultStatusStyle
+	}
+	return style.Render(text)
+}
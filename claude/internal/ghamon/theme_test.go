@@ -0,0 +1,50 @@
+package ghamon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTheme(t *testing.T) {
+	t.Run("returns built-in theme", func(t *testing.T) {
+		theme, err := LoadTheme(t.TempDir(), "deuteranopia")
+		require.NoError(t, err)
+		assert.Equal(t, builtinThemes["deuteranopia"], theme)
+	})
+
+	t.Run("defaults to dark", func(t *testing.T) {
+		theme, err := LoadTheme(t.TempDir(), "")
+		require.NoError(t, err)
+		assert.Equal(t, builtinThemes["dark"], theme)
+	})
+
+	t.Run("fills user-defined theme from dark", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "mine.json"), []byte(`{"success": "#00ff00", "icons": false}`), 0644))
+		theme, err := LoadTheme(dir, "mine")
+		require.NoError(t, err)
+		assert.Equal(t, "#00ff00", theme.Success)
+		assert.Equal(t, builtinThemes["dark"].Failure, theme.Failure)
+		assert.False(t, theme.Icons)
+	})
+
+	t.Run("returns error for unknown theme", func(t *testing.T) {
+		_, err := LoadTheme(t.TempDir(), "nope")
+		assert.ErrorContains(t, err, `unknown theme "nope"`)
+	})
+}
+
+func TestRenderStatus(t *testing.T) {
+	t.Cleanup(func() { applyTheme(builtinThemes[defaultTheme]) })
+	applyTheme(noColorTheme())
+
+	assert.Equal(t, "✓ success", renderStatus("success", "", 0, false))
+	assert.Equal(t, "✗ timed_out ", renderStatus("timed_out", "", 12, false))
+	assert.Equal(t, "● in_progress ..", renderStatus("in_progress", " ..", 0, true))
+	assert.Equal(t, "○ queued", renderStatus("queued", "", 0, false))
+	assert.Equal(t, "  cancelled", renderStatus("cancelled", "", 0, false))
+}
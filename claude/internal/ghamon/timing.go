@@ -0,0 +1,107 @@
+package ghamon
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// minMedianSamples is the number of completed runs needed before a
+// workflow's median duration is considered meaningful.
+const minMedianSamples = 3
+
+// runDuration returns how long a completed run took.
+func runDuration(run WorkflowRun) time.Duration {
+	if run.RunStartedAt.IsZero() || run.UpdatedAt.Before(run.RunStartedAt) {
+		return 0
+	}
+	return run.UpdatedAt.Sub(run.RunStartedAt)
+}
+
+// medianDurations returns the median duration of the successful runs of each
+// workflow in runs, keyed by workflow ID. Workflows with fewer than
+// minMedianSamples successful runs are omitted.
+func medianDurations(runs []WorkflowRun) map[int]time.Duration {
+	samples := make(map[int][]time.Duration)
+	for _, run := range runs {
+		if run.Status != "completed" || run.Conclusion != "success" {
+			continue
+		}
+		if d := runDuration(run); d > 0 {
+			samples[run.WorkflowID] = append(samples[run.WorkflowID], d)
+		}
+	}
+	medians := make(map[int]time.Duration)
+	for id, ds := range samples {
+		if len(ds) < minMedianSamples {
+			continue
+		}
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		mid := len(ds) / 2
+		if len(ds)%2 == 0 {
+			medians[id] = (ds[mid-1] + ds[mid]) / 2
+		} else {
+			medians[id] = ds[mid]
+		}
+	}
+	return medians
+}
+
+// formatDuration renders a duration compactly, e.g. "45s", "4m12s", "1h02m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// formatAge renders how long ago t was, e.g. "12s ago", "4m ago", "3d ago".
+// A t after now, from clock skew against GitHub, counts as now.
+func formatAge(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := max(0, now.Sub(t))
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+// formatClock renders a time of day in the local time zone.
+func formatClock(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("15:04:05")
+}
+
+// queueWait returns how long a run waited between being created and starting.
+func (w workflowInfo) queueWait() time.Duration {
+	if w.CreatedAt.IsZero() || w.StartedAt.IsZero() {
+		return 0
+	}
+	return w.StartedAt.Sub(w.CreatedAt)
+}
+
+// slow reports whether a run is taking longer than factor times its
+// workflow's median duration. A factor of zero or less disables the check.
+func (w workflowInfo) slow(factor float64, now time.Time) bool {
+	if factor <= 0 || w.Median <= 0 {
+		return false
+	}
+	return w.duration(now) > time.Duration(factor*float64(w.Median))
+}
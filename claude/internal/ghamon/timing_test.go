@@ -0,0 +1,67 @@
+package ghamon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedianDurations(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	run := func(id int, conclusion string, d time.Duration) WorkflowRun {
+		return WorkflowRun{WorkflowID: id, Status: "completed", Conclusion: conclusion, RunStartedAt: base, UpdatedAt: base.Add(d)}
+	}
+	runs := []WorkflowRun{
+		run(1, "success", 3*time.Minute),
+		run(1, "success", time.Minute),
+		run(1, "failure", 30*time.Minute),
+		run(1, "success", 2*time.Minute),
+		run(2, "success", 4*time.Minute),
+		run(2, "success", 2*time.Minute),
+		run(2, "success", 8*time.Minute),
+		run(2, "success", 6*time.Minute),
+		run(3, "success", time.Minute),
+		{WorkflowID: 3, Status: "in_progress", RunStartedAt: base},
+	}
+
+	medians := medianDurations(runs)
+	assert.Equal(t, 2*time.Minute, medians[1], "failed runs are ignored")
+	assert.Equal(t, 5*time.Minute, medians[2], "even count averages the middle pair")
+	assert.NotContains(t, medians, 3, "too few samples")
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "-", formatDuration(0))
+	assert.Equal(t, "45s", formatDuration(45*time.Second))
+	assert.Equal(t, "4m12s", formatDuration(4*time.Minute+12*time.Second))
+	assert.Equal(t, "1h02m", formatDuration(time.Hour+2*time.Minute+30*time.Second))
+}
+
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "-", formatAge(time.Time{}, now))
+	assert.Equal(t, "12s ago", formatAge(now.Add(-12*time.Second), now))
+	assert.Equal(t, "just now", formatAge(now.Add(3*time.Second), now), "clock skew")
+	assert.Equal(t, "4m ago", formatAge(now.Add(-4*time.Minute-30*time.Second), now))
+	assert.Equal(t, "3h ago", formatAge(now.Add(-3*time.Hour), now))
+	assert.Equal(t, "2d ago", formatAge(now.Add(-50*time.Hour), now))
+}
+
+func TestWorkflowInfoTiming(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	w := workflowInfo{
+		Status:    "in_progress",
+		CreatedAt: base,
+		StartedAt: base.Add(30 * time.Second),
+		Median:    5 * time.Minute,
+	}
+
+	assert.Equal(t, 30*time.Second, w.queueWait())
+	assert.False(t, w.slow(2, base.Add(8*time.Minute)))
+	assert.True(t, w.slow(2, base.Add(12*time.Minute)))
+	assert.False(t, w.slow(0, base.Add(12*time.Minute)), "zero factor disables the check")
+
+	w.Median = 0
+	assert.False(t, w.slow(2, base.Add(12*time.Minute)), "no median, no flag")
+}
@@ -1,71 +1,216 @@
 package ghamon
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 var (
-	titleStyle  = lipgloss.NewStyle().Bold(true)
-	footerStyle = lipgloss.NewStyle().Faint(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	groupStyle    = lipgloss.NewStyle().Bold(true)
 )
 
-// Lines used by the fixed header and footer.
-// Header: title line + blank line + column header line = 3
-// Footer: blank line + instruction line = 2
-const headerLines = 3
+// Lines used by the fixed footer: blank line + instruction line = 2. The
+// header's height is given by headerLines.
 const footerLines = 2
 
 type workflowInfo struct {
-	Repo     string
+	Repo      string
+	Workflow  string
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	StartedAt time.Time
+	Median    time.Duration // median duration of recent successful runs
+	Branch    string
+	SHA       string
+	Actor     string
+
+	// DefaultBranch is the repository's default branch, if known.
+	DefaultBranch string
+
+	// Err is why the row's repository or workflow could not be fetched, on
+	// an error row. Its Status is "error".
+	Err *FetchError
+}
+
+// rowKey identifies a row across refreshes so the selection can follow it.
+type rowKey struct {
+	repo     string
+	workflow string
+	header   bool
+}
+
+func (w workflowInfo) key() rowKey {
+	return rowKey{repo: w.Repo, workflow: w.Workflow}
+}
+
+// Options configures a monitoring session.
+type Options struct {
 	Workflow string
-	Status   string
+	Repos    []string
+	Rate     int
+	Token    string
+
+	// Checks adds rows for the commit statuses and third-party check runs on
+	// the head of Branch, or of each repository's default branch if Branch
+	// is empty.
+	Checks bool
+	Branch string
+
+	// Theme names the colour theme, overriding the one in the settings.
+	Theme string
+
+	// Tabs shows a dashboard per tab instead of one for Repos.
+	Tabs []Tab
 }
 
 type model struct {
 	workflow       string
 	repos          []string
 	rate           int
+	checks         bool
+	branch         string
 	client         *GitHubClient
 	runs           [][]workflowInfo
-	err            error
 	fetching       bool
 	fetchProgress  int
 	windowWidth    int
 	windowHeight   int
 	scrollOffset   int
+	cursor         int
+	selected       rowKey
+	filter         rowFilter
+	searching      bool
+	sort           sortField
+	sortDesc       bool
+	grouped        bool
+	collapsed      map[string]bool
+	timing         bool
+	slowFactor     float64
+	theme          string
+	keys           keyMap
+	showHelp       bool
+	showDetail     bool
+	lastClick      time.Time
+	lastClickRow   int
 	animationFrame int
+
+	// lastRefresh is when every repository was last fetched without error
+	// (see upToDate), nextRefresh when the next fetch is due, and title the
+	// window title last set.
+	lastRefresh time.Time
+	nextRefresh time.Time
+	title       string
+
+	// Refresh schedule (see schedule.go): fetched holds when each
+	// repository was last fetched, and pending the repositories being
+	// fetched on their own in adaptive mode.
+	paused   bool
+	adaptive bool
+	tickID   int
+	fetched  []time.Time
+	pending  map[int]bool
+
+	// Last known state (see snapshot.go): snapshotPath is where it is
+	// saved after each refresh, stale holds when each repository's rows
+	// were fetched if they are kept from before, from the snapshot or
+	// while offline, and offlineSince when GitHub stopped answering.
+	snapshotPath string
+	stale        []time.Time
+	offlineSince time.Time
+
+	// ctx ends with the session, cancelling any fetch in flight.
+	// refreshCtx is cancelled when a refresh is superseded by the next,
+	// and refreshID tells the current refresh's messages from those of
+	// earlier ones.
+	ctx           context.Context
+	refreshCtx    context.Context
+	cancelRefresh context.CancelFunc
+	refreshID     int
 }
 
 type fetchNextMsg struct {
-	index int
+	index   int
+	refresh int
 }
 
 type fetchedRepoMsg struct {
-	index int
-	infos []workflowInfo
-	err   error
+	index   int
+	refresh int
+	infos   []workflowInfo
+	alone   bool // fetched on its own schedule, not as part of a full refresh
 }
 
 type resetProgressMsg struct{}
 
 type animationTickMsg struct{}
 
-type tickMsg time.Time
+func newModel(opts Options, settings Settings) model {
+	m := model{
+		workflow:   opts.Workflow,
+		repos:      opts.Repos,
+		rate:       opts.Rate,
+		checks:     opts.Checks,
+		branch:     opts.Branch,
+		client:     NewGitHubClient(opts.Token),
+		runs:       placeholderRuns(opts.Repos),
+		fetching:   true,
+		sort:       parseSortField(settings.Sort),
+		sortDesc:   settings.SortDesc,
+		grouped:    settings.Grouped,
+		collapsed:  make(map[string]bool, len(settings.Collapsed)),
+		timing:     settings.Timing,
+		slowFactor: settings.SlowFactor,
+		theme:      settings.Theme,
+		keys:       defaultKeyMap(),
+		adaptive:   settings.Adaptive,
+		fetched:    make([]time.Time, len(opts.Repos)),
+		stale:      make([]time.Time, len(opts.Repos)),
+		pending:    make(map[int]bool),
+
+		nextRefresh: time.Now().Add(time.Duration(opts.Rate) * time.Second),
+	}
+	for _, repo := range settings.Collapsed {
+		m.collapsed[repo] = true
+	}
+	m.withContext(context.Background())
+	return m
+}
+
+// withContext makes ctx the session's context, so that the fetches end
+// when it does. The first refresh, started by Init, runs under it.
+func (m *model) withContext(ctx context.Context) {
+	m.ctx = ctx
+	m.refreshCtx, m.cancelRefresh = context.WithCancel(ctx)
+}
 
-func newModel(workflow string, repos []string, rate int, token string) model {
-	return model{
-		workflow:  workflow,
-		repos:    repos,
-		rate:     rate,
-		client:   NewGitHubClient(token),
-		runs:     placeholderRuns(repos),
-		fetching: true,
+// settings returns the model's preferences to remember for the next session.
+func (m model) settings() Settings {
+	var collapsed []string
+	for repo, folded := range m.collapsed {
+		if folded {
+			collapsed = append(collapsed, repo)
+		}
+	}
+	sort.Strings(collapsed)
+	return Settings{
+		Sort:       m.sort.String(),
+		SortDesc:   m.sortDesc,
+		Grouped:    m.grouped,
+		Collapsed:  collapsed,
+		Timing:     m.timing,
+		SlowFactor: m.slowFactor,
+		Theme:      m.theme,
+		Adaptive:   m.adaptive,
 	}
 }
 
@@ -78,7 +223,7 @@ func placeholderRuns(repos []string) [][]workflowInfo {
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(m.startFetch(), m.tick(), m.animationTick())
+	return tea.Batch(startFetch(m.refreshID), m.tick(), m.animationTick())
 }
 
 func (m model) animationTick() tea.Cmd {
@@ -87,60 +232,138 @@ func (m model) animationTick() tea.Cmd {
 	})
 }
 
+// tick starts the first refresh schedule (see schedule).
 func (m model) tick() tea.Cmd {
+	id := m.tickID
 	return tea.Tick(time.Duration(m.rate)*time.Second, func(t time.Time) tea.Msg {
-		return tickMsg(t)
+		return tickMsg{at: t, id: id}
 	})
 }
 
-func (m model) startFetch() tea.Cmd {
+// startFetch returns the command starting the refresh with id refresh.
+func startFetch(refresh int) tea.Cmd {
 	return func() tea.Msg {
-		return fetchNextMsg{index: 0}
+		return fetchNextMsg{index: 0, refresh: refresh}
 	}
 }
 
-func (m model) fetchRepo(index int) tea.Cmd {
+// refresh starts a full refresh, cancelling the one running, if any.
+func (m *model) refresh() tea.Cmd {
+	m.cancelRefresh()
+	m.refreshCtx, m.cancelRefresh = context.WithCancel(m.ctx)
+	m.refreshID++
+	m.fetching = true
+	m.fetchProgress = 0
+	return startFetch(m.refreshID)
+}
+
+// fetchRepo fetches repository index, on its own if alone is set or else as
+// part of the current full refresh.
+func (m model) fetchRepo(index int, alone bool) tea.Cmd {
 	repo := m.repos[index]
 	workflow := m.workflow
 	client := m.client
+	checks, branch := m.checks, m.branch
+	ctx, refresh := m.refreshCtx, m.refreshID
+	if alone {
+		ctx = m.ctx
+	}
 	return func() tea.Msg {
-		if workflow != "" {
-			// Single-workflow mode.
-			run, err := client.FetchWorkflowRun(repo, workflow)
+		infos, err := fetchWorkflowInfos(ctx, client, repo, workflow)
+		if err != nil {
+			return fetchedRepoMsg{index: index, refresh: refresh, infos: []workflowInfo{errorInfo(repo, workflow, err)}, alone: alone}
+		}
+		if checks {
+			extra, err := fetchCheckInfos(ctx, client, repo, branch)
 			if err != nil {
-				return fetchedRepoMsg{index: index, err: err}
+				extra = []workflowInfo{errorInfo(repo, "checks", err)}
 			}
-			var infos []workflowInfo
-			if run != nil {
-				infos = []workflowInfo{{
-					Repo:     repo,
-					Workflow: run.Name,
-					Status:   formatStatus(run.Status, run.Conclusion),
-				}}
+			infos = append(infos, extra...)
+		}
+		// The default branch only tells failures that matter from others,
+		// so the rows are shown even if it cannot be fetched.
+		if def, err := client.FetchDefaultBranch(ctx, repo); err == nil {
+			for i := range infos {
+				infos[i].DefaultBranch = def
 			}
-			return fetchedRepoMsg{index: index, infos: infos}
 		}
+		return fetchedRepoMsg{index: index, refresh: refresh, infos: infos, alone: alone}
+	}
+}
 
-		// All-workflows mode.
-		runs, err := client.FetchWorkflowRuns(repo)
-		if err != nil {
-			return fetchedRepoMsg{index: index, err: err}
+// fetchWorkflowInfos returns the rows for the latest run of workflow, or of
+// every workflow if workflow is empty, sorted by name.
+func fetchWorkflowInfos(ctx context.Context, client *GitHubClient, repo, workflow string) ([]workflowInfo, error) {
+	if workflow != "" {
+		// Single-workflow mode.
+		run, err := client.FetchWorkflowRun(ctx, repo, workflow)
+		if err != nil || run == nil {
+			return nil, err
 		}
-		var infos []workflowInfo
-		for _, run := range runs {
-			if strings.HasPrefix(run.Name, "Graph Update") || strings.HasPrefix(run.Name, "go_modules") {
-				continue
-			}
-			infos = append(infos, workflowInfo{
-				Repo:     repo,
-				Workflow: run.Name,
-				Status:   formatStatus(run.Status, run.Conclusion),
-			})
+		return []workflowInfo{newWorkflowInfo(repo, *run)}, nil
+	}
+
+	// All-workflows mode.
+	runs, err := client.FetchWorkflowRuns(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	var infos []workflowInfo
+	for _, run := range runs {
+		if strings.HasPrefix(run.Name, "Graph Update") || strings.HasPrefix(run.Name, "go_modules") {
+			continue
+		}
+		infos = append(infos, newWorkflowInfo(repo, run))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Workflow < infos[j].Workflow
+	})
+	return infos, nil
+}
+
+// fetchCheckInfos returns rows for the commit statuses and third-party check
+// runs on the head of branch, or of the default branch if branch is empty.
+func fetchCheckInfos(ctx context.Context, client *GitHubClient, repo, branch string) ([]workflowInfo, error) {
+	if branch == "" {
+		var err error
+		if branch, err = client.FetchDefaultBranch(ctx, repo); err != nil {
+			return nil, err
+		}
+	}
+	checks, err := client.FetchCommitChecks(ctx, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]workflowInfo, len(checks))
+	for i, c := range checks {
+		infos[i] = workflowInfo{
+			Repo:      repo,
+			Workflow:  c.Name,
+			Status:    formatStatus(c.Status, c.Conclusion),
+			UpdatedAt: c.UpdatedAt,
+			StartedAt: c.StartedAt,
+			Branch:    branch,
+			SHA:       c.SHA,
 		}
-		sort.Slice(infos, func(i, j int) bool {
-			return infos[i].Workflow < infos[j].Workflow
-		})
-		return fetchedRepoMsg{index: index, infos: infos}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Workflow < infos[j].Workflow
+	})
+	return infos, nil
+}
+
+func newWorkflowInfo(repo string, run WorkflowRun) workflowInfo {
+	return workflowInfo{
+		Repo:      repo,
+		Workflow:  run.Name,
+		Status:    formatStatus(run.Status, run.Conclusion),
+		CreatedAt: run.CreatedAt,
+		UpdatedAt: run.UpdatedAt,
+		StartedAt: run.RunStartedAt,
+		Median:    run.MedianDuration,
+		Branch:    run.HeadBranch,
+		SHA:       run.HeadSHA,
+		Actor:     run.Actor.Login,
 	}
 }
 
@@ -160,17 +383,73 @@ func (m model) flatRuns() []workflowInfo {
 	return flat
 }
 
+// visibleRows returns the table rows that pass the active filter, sorted
+// and, if enabled, grouped by repository.
+func (m model) visibleRows() []tableRow {
+	flat := m.flatRuns()
+	var rows []workflowInfo
+	for _, r := range flat {
+		if m.filter.matches(r) {
+			rows = append(rows, r)
+		}
+	}
+	sortRows(rows, m.sort, m.sortDesc, time.Now())
+
+	if m.grouped {
+		return groupRows(rows, flat, m.collapsed)
+	}
+	table := make([]tableRow, len(rows))
+	for i, r := range rows {
+		table[i] = tableRow{info: r}
+	}
+	return table
+}
+
 // totalRows returns the total number of display rows across all repos.
 func (m model) totalRows() int {
-	total := 0
-	for _, repoRuns := range m.runs {
-		total += len(repoRuns)
+	return len(m.visibleRows())
+}
+
+// followSelection keeps the cursor on the selected row after the visible rows
+// change, e.g. on refresh or when the filter changes. If the selected row is
+// no longer visible, the cursor stays at the same position.
+func (m *model) followSelection() {
+	rows := m.visibleRows()
+	for i, r := range rows {
+		if r.key() == m.selected {
+			m.cursor = i
+			m.clampScroll()
+			return
+		}
+	}
+	m.setCursor(m.cursor)
+}
+
+// setCursor moves the cursor to row i of the visible rows.
+func (m *model) setCursor(i int) {
+	rows := m.visibleRows()
+	if i >= len(rows) {
+		i = len(rows) - 1
+	}
+	if i < 0 {
+		i = 0
 	}
-	return total
+	m.cursor = i
+	if i < len(rows) {
+		m.selected = rows[i].key()
+	}
+	m.clampScroll()
 }
 
+// clampScroll keeps the scroll offset in range and the cursor on screen.
 func (m *model) clampScroll() {
 	maxRows := m.contentHeight()
+	if m.cursor < m.scrollOffset {
+		m.scrollOffset = m.cursor
+	}
+	if m.cursor >= m.scrollOffset+maxRows {
+		m.scrollOffset = m.cursor - maxRows + 1
+	}
 	totalRows := m.totalRows()
 	maxOffset := totalRows - maxRows
 	if maxOffset < 0 {
@@ -184,8 +463,72 @@ func (m *model) clampScroll() {
 	}
 }
 
+// updateSearch handles key presses while the search bar has focus.
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyEsc:
+		m.searching = false
+		m.filter.query = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.filter.query); len(r) > 0 {
+			m.filter.query = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.filter.query += " "
+	case tea.KeyRunes:
+		m.filter.query += string(msg.Runes)
+	}
+	m.followSelection()
+	return m, nil
+}
+
+// cycleSort advances to the next sort column.
+func (m *model) cycleSort() {
+	m.sort = (m.sort + 1) % numSortFields
+	m.followSelection()
+}
+
+// toggleGroup collapses or expands the group under the cursor.
+func (m *model) toggleGroup() {
+	rows := m.visibleRows()
+	if m.cursor >= len(rows) {
+		return
+	}
+	repo := rows[m.cursor].info.Repo
+	m.collapsed[repo] = !m.collapsed[repo]
+	m.selected = rowKey{repo: repo, header: true}
+	m.followSelection()
+}
+
+// setAllCollapsed collapses or expands every group.
+func (m *model) setAllCollapsed(collapsed bool) {
+	for _, repo := range m.repos {
+		m.collapsed[repo] = collapsed
+	}
+	if collapsed && m.grouped {
+		// Keep the selection on the group the cursor was in.
+		m.selected = rowKey{repo: m.selected.repo, header: true}
+	}
+	m.followSelection()
+}
+
+// toggleStatusFilter switches to quick filter f, or back to all rows if f is
+// already active.
+func (m *model) toggleStatusFilter(f statusFilter) {
+	if m.filter.status == f {
+		m.filter.status = showAll
+	} else {
+		m.filter.status = f
+	}
+	m.followSelection()
+}
+
 func (m model) contentHeight() int {
-	h := m.windowHeight - headerLines - footerLines
+	h := m.windowHeight - m.headerLines() - footerLines
 	if h < 1 {
 		h = 1
 	}
@@ -199,32 +542,94 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.windowHeight = msg.Height
 		m.clampScroll()
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+		if m.showDetail {
+			return m.updateDetail(msg)
+		}
+		k := m.keys
+		switch {
+		case key.Matches(msg, k.Quit):
 			return m, tea.Quit
-		case "r":
-			if !m.fetching {
-				m.fetching = true
-				m.fetchProgress = 0
-				return m, m.startFetch()
-			}
-		case "up", "k":
-			m.scrollOffset--
-			m.clampScroll()
-		case "down", "j":
-			m.scrollOffset++
-			m.clampScroll()
+		case key.Matches(msg, k.Help):
+			m.showHelp = true
+		case key.Matches(msg, k.Refresh):
+			// A refresh already running is restarted rather than left to
+			// finish, in case it is stuck waiting on GitHub.
+			return m, m.refresh()
+		case key.Matches(msg, k.Up):
+			m.setCursor(m.cursor - 1)
+		case key.Matches(msg, k.Down):
+			m.setCursor(m.cursor + 1)
+		case key.Matches(msg, k.PageUp):
+			m.setCursor(m.cursor - m.contentHeight())
+		case key.Matches(msg, k.PageDown):
+			m.setCursor(m.cursor + m.contentHeight())
+		case key.Matches(msg, k.Home):
+			m.setCursor(0)
+		case key.Matches(msg, k.End):
+			m.setCursor(len(m.visibleRows()) - 1)
+		case key.Matches(msg, k.Search):
+			m.searching = true
+		case key.Matches(msg, k.Failing):
+			m.toggleStatusFilter(showFailing)
+		case key.Matches(msg, k.Running):
+			m.toggleStatusFilter(showRunning)
+		case key.Matches(msg, k.HideOK):
+			m.toggleStatusFilter(hideSuccessful)
+		case key.Matches(msg, k.Clear):
+			m.filter = rowFilter{}
+			m.followSelection()
+		case key.Matches(msg, k.Sort):
+			m.cycleSort()
+		case key.Matches(msg, k.Reverse):
+			m.sortDesc = !m.sortDesc
+			m.followSelection()
+		case key.Matches(msg, k.Group):
+			m.grouped = !m.grouped
+			m.followSelection()
+		case key.Matches(msg, k.Timing):
+			m.timing = !m.timing
+		case key.Matches(msg, k.Fold):
+			m.selectRow()
+		case key.Matches(msg, k.Pause):
+			m.paused = !m.paused
+			return m, m.schedule(time.Now())
+		case key.Matches(msg, k.Faster):
+			m.rate = fasterRate(m.rate)
+			return m, m.schedule(time.Now())
+		case key.Matches(msg, k.Slower):
+			m.rate = slowerRate(m.rate)
+			return m, m.schedule(time.Now())
+		case key.Matches(msg, k.Adaptive):
+			m.adaptive = !m.adaptive
+			return m, m.schedule(time.Now())
+		case key.Matches(msg, k.CollapseAll):
+			m.setAllCollapsed(true)
+		case key.Matches(msg, k.ExpandAll):
+			m.setAllCollapsed(false)
 		}
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 	case tickMsg:
+		if msg.id != m.tickID {
+			return m, nil
+		}
+		if m.adaptive {
+			return m, m.fetchDue(msg.at)
+		}
+		schedule := m.schedule(msg.at)
 		if !m.fetching {
-			m.fetching = true
-			m.fetchProgress = 0
-			return m, tea.Batch(m.startFetch(), m.tick())
+			return m, tea.Batch(m.refresh(), schedule)
 		}
-		return m, m.tick()
+		return m, schedule
 	case fetchNextMsg:
-		if msg.index < len(m.repos) {
-			return m, m.fetchRepo(msg.index)
+		if msg.refresh == m.refreshID && msg.index < len(m.repos) {
+			return m, m.fetchRepo(msg.index, false)
 		}
 	case animationTickMsg:
 		m.animationFrame = (m.animationFrame + 1) % 4
@@ -232,24 +637,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case resetProgressMsg:
 		m.fetchProgress = 0
 	case fetchedRepoMsg:
-		if msg.err != nil {
-			m.err = msg.err
-			m.fetching = false
-		} else {
-			if msg.infos != nil {
-				m.runs[msg.index] = msg.infos
-			}
-			m.fetchProgress = msg.index + 1
-			if msg.index+1 < len(m.repos) {
-				return m, func() tea.Msg { return fetchNextMsg{index: msg.index + 1} }
-			}
-			m.fetching = false
-			m.err = nil
-			return m, tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
-				return resetProgressMsg{}
-			})
+		if !msg.alone && msg.refresh != m.refreshID {
+			// From a refresh since cancelled.
+			return m, nil
 		}
-		m.clampScroll()
+		now := time.Now()
+		if msg.infos != nil {
+			m.storeRepo(msg.index, msg.infos, now)
+		}
+		m.fetched[msg.index] = now
+		if msg.alone {
+			return m, m.fetchedAlone(msg)
+		}
+		m.followSelection()
+		m.fetchProgress = msg.index + 1
+		if msg.index+1 < len(m.repos) {
+			next := fetchNextMsg{index: msg.index + 1, refresh: msg.refresh}
+			return m, func() tea.Msg { return next }
+		}
+		m.fetching = false
+		m.cancelRefresh()
+		if m.upToDate() {
+			m.lastRefresh = now
+		}
+		return m, tea.Batch(m.updateTitle(), m.saveSnapshot(), tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+			return resetProgressMsg{}
+		}))
 	}
 	return m, nil
 }
@@ -263,53 +676,83 @@ func renderProgressBar(current, total, width int) string {
 	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", empty) + "]"
 }
 
+// header renders the title line with the refresh rate and fetch progress,
+// and the health bar below it.
+func (m model) header() string {
+	return m.clip(titleStyle.Render("GHA Monitor")+
+		"  Refresh: "+m.rateLabel()+
+		fmt.Sprintf("  %s %d/%d", renderProgressBar(m.fetchProgress, len(m.repos), 20), m.fetchProgress, len(m.repos))) +
+		"\n" + m.clip(m.healthBar(time.Now()))
+}
+
+// clip cuts a line of styled text to the terminal width, so it does not wrap.
+func (m model) clip(line string) string {
+	return lipgloss.NewStyle().MaxWidth(m.windowWidth).Render(line)
+}
+
+// headerLines returns the number of lines above the table's rows: the
+// header, a blank line and the column header.
+func (m model) headerLines() int {
+	return lipgloss.Height(m.header()) + 2
+}
+
 func (m model) View() string {
 	var b strings.Builder
 
 	// Header
-	b.WriteString(titleStyle.Render("GHA Monitor"))
-	b.WriteString(fmt.Sprintf("  Refresh: %ds", m.rate))
-	b.WriteString(fmt.Sprintf("  %s %d/%d",
-		renderProgressBar(m.fetchProgress, len(m.repos), 20),
-		m.fetchProgress, len(m.repos)))
+	b.WriteString(m.header())
 	b.WriteString("\n\n")
 
 	// Content
-	flat := m.flatRuns()
-	if m.err != nil {
-		b.WriteString(fmt.Sprintf("Error: %v\n", m.err))
-	} else {
-		b.WriteString(fmt.Sprintf("%-40s %-25s %s\n", "REPOSITORY", "WORKFLOW", "STATUS"))
-
-		maxRows := m.contentHeight()
-		end := m.scrollOffset + maxRows
-		if end > len(flat) {
-			end = len(flat)
-		}
-		animSuffix := strings.Repeat(".", m.animationFrame)
-		visible := flat[m.scrollOffset:end]
-		for _, r := range visible {
-			status := r.Status
-			if status == "in_progress" || status == "queued" {
-				status = status + " " + animSuffix
+	flat := m.visibleRows()
+	if m.showHelp {
+		return b.String() + m.helpOverlay()
+	}
+	if m.showDetail {
+		return b.String() + m.detailOverlay()
+	}
+	now := time.Now()
+	cols := m.columns(now)
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = fitCell(c.title, c.width)
+	}
+	b.WriteString(columnStyle.Render(strings.Join(titles, " ")) + "\n")
+
+	maxRows := m.contentHeight()
+	end := m.scrollOffset + maxRows
+	if end > len(flat) {
+		end = len(flat)
+	}
+	visible := flat[m.scrollOffset:end]
+	for i, row := range visible {
+		var line string
+		if row.header {
+			fold := "▾"
+			if m.collapsed[row.info.Repo] {
+				fold = "▸"
+			}
+			line = fmt.Sprintf("%s %s", fold, row.info.Repo)
+			summary := "  " + row.summary
+			if m.windowWidth > 0 {
+				summary = truncate(summary, m.windowWidth-lipgloss.Width(line))
+			}
+			if m.scrollOffset+i != m.cursor {
+				line = groupStyle.Render(line)
 			}
-			b.WriteString(fmt.Sprintf("%-40s %-25s %s\n", r.Repo, r.Workflow, status))
+			line += summary
+		} else {
+			line = m.renderRow(cols, row.info, now, m.scrollOffset+i == m.cursor)
+		}
+		if m.scrollOffset+i == m.cursor {
+			line = selectedStyle.Render(line)
 		}
+		b.WriteString(line + "\n")
 	}
 
 	// Pad to push footer to the bottom
-	header := headerLines
-	contentRendered := 0
-	if m.err != nil {
-		contentRendered = 1
-	} else {
-		contentRendered = 1 // column header
-		end := m.scrollOffset + m.contentHeight()
-		if end > len(flat) {
-			end = len(flat)
-		}
-		contentRendered += end - m.scrollOffset
-	}
+	header := m.headerLines()
+	contentRendered := 1 + len(visible) // column header and rows
 	usedLines := header + contentRendered + footerLines
 	if pad := m.windowHeight - usedLines; pad > 0 {
 		b.WriteString(strings.Repeat("\n", pad))
@@ -317,15 +760,237 @@ func (m model) View() string {
 
 	// Footer
 	b.WriteString("\n")
-	b.WriteString(footerStyle.Render("q: quit | r: refresh"))
+	b.WriteString(m.clip(m.footer()))
 
 	return b.String()
 }
 
-// RunTUI starts the TUI application.
-func RunTUI(workflow string, repos []string, rate int, token string) error {
-	m := newModel(workflow, repos, rate, token)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err := p.Run()
-	return err
+func (m model) footer() string {
+	if m.searching {
+		return "/" + m.filter.query + "█" + footerStyle.Render("  enter: apply | esc: clear")
+	}
+	bindings := m.keys.ShortHelp()
+	if m.grouped {
+		bindings = append(bindings, m.keys.Fold, m.keys.CollapseAll, m.keys.ExpandAll)
+	}
+	hints := footerStyle.Render(keyHints(bindings))
+	if m.sort == sortUpdated || m.sort == sortDuration {
+		hints += "  " + fmt.Sprintf("[sort: %s %s]", m.sort, m.sortArrow())
+	}
+	if m.filter.active() {
+		hints += "  " + fmt.Sprintf("[%s]", m.filter) + footerStyle.Render(" "+m.keys.Clear.Help().Key+": clear")
+	}
+	return hints
+}
+
+// columns lays out the table's columns for the terminal width, sized to the
+// rows of the table whatever the scroll position.
+func (m model) columns(now time.Time) []placedColumn {
+	cols := tableColumns(m.timing)
+	for i, c := range cols {
+		if c.field != sortDefault {
+			cols[i].title = m.columnTitle(c.title, c.field)
+		}
+	}
+	widths := make(map[columnID]int)
+	for _, row := range m.visibleRows() {
+		if row.header {
+			continue
+		}
+		for _, c := range cols {
+			widths[c.id] = max(widths[c.id], lipgloss.Width(m.cellText(c.id, row.info, now)))
+		}
+	}
+	return layoutColumns(cols, m.windowWidth, func(id columnID) int { return widths[id] })
+}
+
+// cellText returns the unstyled text of a row's cell in column id. Runs
+// taking much longer than their workflow's median are flagged in the
+// duration column.
+func (m model) cellText(id columnID, r workflowInfo, now time.Time) string {
+	if r.Status == "..." && id != colRepo && id != colWorkflow && id != colStatus {
+		return ""
+	}
+	switch id {
+	case colRepo:
+		if m.grouped {
+			return ""
+		}
+		return r.Repo
+	case colWorkflow:
+		if r.Err != nil {
+			return errorSummary(r)
+		}
+		return r.Workflow
+	case colStatus:
+		text := r.Status + m.statusSuffix(r)
+		if showIcons {
+			text = statusIcon(r.Status) + " " + text
+		}
+		return text
+	case colStarted:
+		return formatClock(r.StartedAt)
+	case colDuration:
+		if r.slow(m.slowFactor, now) {
+			return formatDuration(r.duration(now)) + " ⚠"
+		}
+		return formatDuration(r.duration(now))
+	case colQueued:
+		return formatDuration(r.queueWait())
+	case colUpdated:
+		return formatAge(r.UpdatedAt, now)
+	case colBranch:
+		return r.Branch
+	case colSHA:
+		return shortSHA(r.SHA)
+	case colActor:
+		return r.Actor
+	}
+	return ""
+}
+
+// statusSuffix returns the animated dots shown after a running status.
+func (m model) statusSuffix(r workflowInfo) string {
+	if r.Status == "in_progress" || r.Status == "queued" {
+		return " " + strings.Repeat(".", m.animationFrame)
+	}
+	return ""
+}
+
+// renderRow renders a workflow row in cols. Statuses and slow durations are
+// coloured, except on the selected row so the selection stays readable.
+func (m model) renderRow(cols []placedColumn, r workflowInfo, now time.Time, selected bool) string {
+	cells := make([]string, len(cols))
+	for i, c := range cols {
+		switch {
+		case c.id == colStatus:
+			cells[i] = renderStatus(r.Status, m.statusSuffix(r), c.width, selected)
+		case c.id == colDuration && !selected && r.slow(m.slowFactor, now):
+			cells[i] = slowStyle.Render(fitCell(m.cellText(c.id, r, now), c.width))
+		default:
+			cells[i] = fitCell(m.cellText(c.id, r, now), c.width)
+		}
+	}
+	return strings.Join(cells, " ")
+}
+
+// shortSHA abbreviates a commit SHA to seven characters, as git does.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// columnTitle returns a column heading, marked with an arrow if the table is
+// sorted by that column.
+func (m model) columnTitle(title string, field sortField) string {
+	if m.sort != field {
+		return title
+	}
+	return title + " " + m.sortArrow()
+}
+
+func (m model) sortArrow() string {
+	if m.sortDesc {
+		return "▼"
+	}
+	return "▲"
+}
+
+// RunTUI starts the TUI application. Sort and grouping preferences are loaded
+// from ~/.ghamon/settings.json and saved back on exit. Colours come from the
+// theme in opts or the settings, and are dropped if NO_COLOR is set.
+func RunTUI(opts Options) error {
+	path := ghamonFilePath(settingsFile)
+	settings, err := LoadSettings(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: could not load settings:", err)
+	}
+	if opts.Theme != "" {
+		settings.Theme = opts.Theme
+	}
+	theme, err := LoadTheme(ghamonFilePath(themesDir), settings.Theme)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		theme = noColorTheme()
+	}
+	applyTheme(theme)
+	keyCfg, err := LoadKeyConfig(ghamonFilePath(keysFile))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: could not load key bindings:", err)
+	}
+	keys, err := newKeyMap(keyCfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+
+	snapshotPath := ghamonFilePath(snapshotFile)
+	snap, err := loadSnapshot(snapshotPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: could not load the last snapshot:", err)
+	}
+	// Each dashboard starts from the rows last fetched, until it fetches
+	// them again, and fetches still in flight when the program ends are
+	// cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setUp := func(m *model) {
+		m.withContext(ctx)
+		m.snapshotPath = snapshotPath
+		m.restore(snap)
+	}
+	var start tea.Model
+	if len(opts.Tabs) > 0 {
+		d := newDashboards(opts, settings, keys)
+		for i := range d.tabs {
+			setUp(&d.tabs[i])
+		}
+		start = d
+	} else {
+		m := newModel(opts, settings)
+		m.keys = keys.withoutTabs()
+		setUp(&m)
+		start = m
+	}
+	p := tea.NewProgram(start, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+	// With tabs, the active tab's preferences are remembered.
+	if d, ok := final.(dashboards); ok {
+		final = d.tabs[d.active]
+	}
+	if fm, ok := final.(model); ok {
+		if err := SaveSettings(path, fm.settings()); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: could not save settings:", err)
+		}
+	}
+	return nil
+}
+
+// updateHelp handles key presses while the help overlay is shown.
+func (m model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Help, m.keys.Clear):
+		m.showHelp = false
+	}
+	return m, nil
+}
+
+// helpOverlay renders the list of key bindings in place of the table, with
+// the footer at the bottom.
+func (m model) helpOverlay() string {
+	overlay := titleStyle.Render("Key bindings") + "\n\n" + m.keys.helpView(m.windowWidth) + "\n"
+	// The header and blank line above the overlay, and the footer below it.
+	if pad := m.windowHeight - m.headerLines() + 1 - strings.Count(overlay, "\n") - footerLines; pad > 0 {
+		overlay += strings.Repeat("\n", pad)
+	}
+	hint := fmt.Sprintf("%s/%s: close", m.keys.Help.Help().Key, m.keys.Clear.Help().Key)
+	return overlay + "\n" + footerStyle.Render(hint)
 }
@@ -1,14 +1,18 @@
 package config_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"ghamon/internal/config"
+	ghclient "ghamon/internal/github"
 )
 
 func TestLoad_MissingFile(t *testing.T) {
@@ -72,3 +76,160 @@ func writeTempConfig(t *testing.T, content string) string {
 	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
 	return path
 }
+
+func TestLoadSettings_MissingFile(t *testing.T) {
+	s, err := config.LoadSettings(filepath.Join(t.TempDir(), "settings.json"))
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultSettings(), s)
+}
+
+func TestLoadSettings_KeepsDefaultsForMissingFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"sort": "status"}`), 0o644))
+	s, err := config.LoadSettings(path)
+	require.NoError(t, err)
+	assert.Equal(t, "status", s.Sort)
+	assert.Equal(t, 2.0, s.SlowFactor)
+}
+
+func TestLoadSettings_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err := config.LoadSettings(path)
+	assert.Error(t, err)
+}
+
+func TestSaveSettings_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "settings.json")
+	want := config.Settings{Sort: "status", SortDesc: true, Grouped: true, Collapsed: []string{"owner/a"}, Timing: true, SlowFactor: 3}
+	require.NoError(t, config.SaveSettings(path, want))
+
+	got, err := config.LoadSettings(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestDefaultSettingsPath(t *testing.T) {
+	p := config.DefaultSettingsPath()
+	assert.Contains(t, p, ".ghamon")
+	assert.True(t, filepath.IsAbs(p))
+}
+
+func TestLoadTheme_Builtin(t *testing.T) {
+	theme, err := config.LoadTheme(t.TempDir(), "deuteranopia")
+	require.NoError(t, err)
+	builtin, _ := config.BuiltinTheme("deuteranopia")
+	assert.Equal(t, builtin, theme)
+	assert.True(t, theme.Icons)
+}
+
+func TestLoadTheme_DefaultsToDark(t *testing.T) {
+	theme, err := config.LoadTheme(t.TempDir(), "")
+	require.NoError(t, err)
+	dark, _ := config.BuiltinTheme(config.DefaultTheme)
+	assert.Equal(t, dark, theme)
+}
+
+func TestLoadTheme_UserDefined(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mine.json"), []byte(`{"success": "#00ff00", "icons": false}`), 0o644))
+	theme, err := config.LoadTheme(dir, "mine")
+	require.NoError(t, err)
+	dark, _ := config.BuiltinTheme(config.DefaultTheme)
+	assert.Equal(t, "#00ff00", theme.Success)
+	assert.Equal(t, dark.Failure, theme.Failure)
+	assert.False(t, theme.Icons)
+}
+
+func TestLoadTheme_Unknown(t *testing.T) {
+	_, err := config.LoadTheme(t.TempDir(), "nope")
+	assert.ErrorContains(t, err, `unknown theme "nope"`)
+	assert.ErrorContains(t, err, "dark, light, high-contrast, deuteranopia")
+}
+
+func TestLoadTheme_NoThemesDir(t *testing.T) {
+	// Without a home directory there is no themes directory, and the
+	// working directory must not be searched instead.
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("light.json", []byte(`{"success": "#00ff00"}`), 0o644))
+	theme, err := config.LoadTheme("", "light")
+	require.NoError(t, err)
+	light, _ := config.BuiltinTheme("light")
+	assert.Equal(t, light, theme)
+}
+
+func TestLoadKeys(t *testing.T) {
+	keys, err := config.LoadKeys(filepath.Join(t.TempDir(), "keys.json"))
+	require.NoError(t, err)
+	assert.Equal(t, config.Keys{}, keys)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"preset": "vim", "bindings": {"quit": ["ctrl+x"]}}`), 0o644))
+	keys, err = config.LoadKeys(path)
+	require.NoError(t, err)
+	assert.Equal(t, "vim", keys.Preset)
+	assert.Equal(t, []string{"ctrl+x"}, keys.Bindings["quit"])
+}
+
+func TestLastRuns_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ghamon", "last_runs.json")
+	last, err := config.LoadLastRuns(path)
+	require.NoError(t, err, "a missing file holds no runs")
+	assert.Empty(t, last.Repos)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	a := config.LastRunsKey("owner/a", "")
+	b := config.LastRunsKey("owner/b", "ci.yml")
+	assert.Equal(t, "owner/b workflow:ci.yml", b)
+	require.NoError(t, config.SaveLastRuns(path, map[string]config.RepoRuns{
+		a: {FetchedAt: at, Runs: []ghclient.WorkflowRun{{Repo: "owner/a", Workflow: "CI", Status: "completed", Conclusion: "success"}}},
+		b: {FetchedAt: at, Runs: []ghclient.WorkflowRun{{Repo: "owner/b", Workflow: "ci.yml", Status: "in_progress"}}},
+	}))
+	require.NoError(t, config.SaveLastRuns(path, map[string]config.RepoRuns{
+		b: {FetchedAt: at.Add(time.Minute), Runs: []ghclient.WorkflowRun{{Repo: "owner/b", Workflow: "ci.yml", Status: "completed"}}},
+	}))
+
+	last, err = config.LoadLastRuns(path)
+	require.NoError(t, err)
+	assert.Equal(t, "success", last.Repos[a].Runs[0].Conclusion, "other repositories are kept")
+	assert.Equal(t, "completed", last.Repos[b].Runs[0].Status)
+	assert.True(t, at.Add(time.Minute).Equal(last.Repos[b].FetchedAt))
+}
+
+func TestLastRuns_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last_runs.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err := config.LoadLastRuns(path)
+	assert.Error(t, err)
+
+	require.NoError(t, config.SaveLastRuns(path, map[string]config.RepoRuns{"owner/repo": {}}), "a corrupt file is replaced")
+	last, err := config.LoadLastRuns(path)
+	require.NoError(t, err)
+	assert.Contains(t, last.Repos, "owner/repo")
+}
+
+func TestLastRuns_ConcurrentSaves(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "last_runs.json")
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := config.LastRunsKey(fmt.Sprintf("owner/repo%d", i), "")
+			assert.NoError(t, config.SaveLastRuns(path, map[string]config.RepoRuns{key: {}}))
+		}()
+	}
+	wg.Wait()
+
+	last, err := config.LoadLastRuns(path)
+	require.NoError(t, err)
+	assert.Len(t, last.Repos, 8)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"last_runs.json", "last_runs.json.lock"}, names, "no temporary file is left behind")
+}
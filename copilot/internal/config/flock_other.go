@@ -0,0 +1,12 @@
+//go:build !unix
+
+package config
+
+import "os"
+
+// acquireLock returns no lock on platforms without flock. Sessions saving at
+// the same moment may then overwrite each other's runs until their next
+// save; closing a nil *os.File is harmless.
+func acquireLock(path string) (*os.File, error) {
+	return nil, nil
+}
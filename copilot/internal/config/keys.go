@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Keys is the key bindings file, ~/.ghamon/keys.json. Its Preset ("vim",
+// "emacs" or "default", the same as "") adds a set of familiar keys, and
+// Bindings sets the keys of single actions by name, e.g.
+// {"quit": ["q", "ctrl+c"]}, on top of it.
+type Keys struct {
+	Preset   string              `json:"preset,omitempty"`
+	Bindings map[string][]string `json:"bindings,omitempty"`
+}
+
+// DefaultKeysPath returns where the key bindings file is read from, or "" if
+// the user has no home directory.
+func DefaultKeysPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ghamon", "keys.json")
+}
+
+// LoadKeys reads the key bindings file at path. Without one, the zero Keys
+// keeps every default binding.
+func LoadKeys(path string) (Keys, error) {
+	var k Keys
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return k, nil
+		}
+		return k, fmt.Errorf("reading key bindings file %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &k); err != nil {
+		return Keys{}, fmt.Errorf("parsing key bindings file %q: %w", path, err)
+	}
+	return k, nil
+}
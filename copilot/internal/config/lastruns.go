@@ -0,0 +1,121 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	ghclient "ghamon/internal/github"
+)
+
+// LastRuns holds the runs last fetched without error for each repository, so
+// that the next session can show them until it has fetched its own.
+type LastRuns struct {
+	Repos map[string]RepoRuns `json:"repos"`
+}
+
+// RepoRuns are the runs of a repository and when they were fetched.
+type RepoRuns struct {
+	FetchedAt time.Time              `json:"fetched_at"`
+	Runs      []ghclient.WorkflowRun `json:"runs"`
+}
+
+// lastRunsMu serializes saving the last runs, which is done in the
+// background after each refresh. Other ghamon processes are kept out by a
+// flock on the file's ".lock" sibling.
+var lastRunsMu sync.Mutex
+
+// LastRunsKey identifies the runs of repository repo, fetched for workflow
+// (or for all workflows when workflow is "").
+func LastRunsKey(repo, workflow string) string {
+	if workflow == "" {
+		return repo
+	}
+	return repo + " workflow:" + workflow
+}
+
+// DefaultLastRunsPath returns the default last runs file path.
+func DefaultLastRunsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ghamon", "last_runs.json")
+}
+
+// LoadLastRuns reads the last runs from a JSON file. A missing file holds no
+// runs.
+func LoadLastRuns(path string) (LastRuns, error) {
+	last := LastRuns{Repos: make(map[string]RepoRuns)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return last, nil
+		}
+		return last, fmt.Errorf("reading last runs file %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &last); err != nil {
+		return LastRuns{Repos: make(map[string]RepoRuns)}, fmt.Errorf("parsing last runs file %q: %w", path, err)
+	}
+	if last.Repos == nil {
+		last.Repos = make(map[string]RepoRuns)
+	}
+	return last, nil
+}
+
+// SaveLastRuns adds repos to the last runs in a JSON file, keeping the other
+// repositories in it, and creating its directory if needed. Sessions running
+// at once take turns under a file lock, so none drops another's runs, and the
+// file is replaced by renaming a temporary file in the same directory over
+// it, so it is never read half written.
+func SaveLastRuns(path string, repos map[string]RepoRuns) error {
+	lastRunsMu.Lock()
+	defer lastRunsMu.Unlock()
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating last runs directory: %w", err)
+	}
+	lock, err := acquireLock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	// An unreadable file has nothing worth keeping; it is overwritten.
+	last, _ := LoadLastRuns(path)
+	for key, r := range repos {
+		last.Repos[key] = r
+	}
+	data, err := json.Marshal(last)
+	if err != nil {
+		return fmt.Errorf("encoding last runs: %w", err)
+	}
+	if err := writeReplacing(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing last runs file %q: %w", path, err)
+	}
+	return nil
+}
+
+// writeReplacing writes data to a new temporary file next to path and renames
+// it over path. The temporary file is removed if anything fails.
+func writeReplacing(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
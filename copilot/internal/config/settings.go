@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Settings are the view options a user changed in the TUI, saved to
+// ~/.ghamon/settings.json when they quit and restored on the next start.
+type Settings struct {
+	// Sort and SortDesc are the table order, by the name of its column.
+	Sort     string `json:"sort,omitempty"`
+	SortDesc bool   `json:"sort_desc,omitempty"`
+
+	// Grouped shows the runs under a header per repository, and Collapsed
+	// lists the repositories folded down to their header.
+	Grouped   bool     `json:"grouped,omitempty"`
+	Collapsed []string `json:"collapsed,omitempty"`
+
+	// Timing shows the queue time, duration and age columns.
+	Timing bool `json:"timing,omitempty"`
+
+	// SlowFactor is how many times its workflow's median duration a run may
+	// take before it is flagged as slow; 0 turns the flag off. It is saved
+	// even when 0, so that turning it off sticks.
+	SlowFactor float64 `json:"slow_factor"`
+
+	// Theme is the name of a built-in theme or a file in
+	// ~/.ghamon/themes; "" is the default theme.
+	Theme string `json:"theme,omitempty"`
+
+	// Adaptive refreshes each repository on its own schedule, idle ones
+	// less often.
+	Adaptive bool `json:"adaptive,omitempty"`
+}
+
+// DefaultSettings are the settings of a first start, before any are saved.
+func DefaultSettings() Settings {
+	return Settings{SlowFactor: 2}
+}
+
+// DefaultSettingsPath returns the default settings file path, or "" if the
+// user has no home directory.
+func DefaultSettingsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ghamon", "settings.json")
+}
+
+// LoadSettings reads the settings saved at path. Settings the file does not
+// mention, e.g. ones added since it was saved, keep their defaults, and
+// without a file all of them do.
+func LoadSettings(path string) (Settings, error) {
+	s := DefaultSettings()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("reading settings file %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("parsing settings file %q: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveSettings saves s at path as indented JSON, creating ~/.ghamon if it
+// does not exist yet.
+func SaveSettings(path string, s Settings) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding settings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating settings directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing settings file %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTheme is the theme used when none is chosen, and the one whose
+// colours a user-defined theme falls back on.
+const DefaultTheme = "dark"
+
+// Theme is the palette the TUI is drawn in. Each colour is an ANSI 256
+// colour number such as "196" or a hex value such as "#d55e00"; "" leaves
+// the terminal's own colour.
+type Theme struct {
+	// Muted is for the header info, the footer and skipped runs; Accent for
+	// column headings and the progress bar; Text for any status that has no
+	// colour below. Running covers queued and pending runs as well, and
+	// Warning cancelled runs, slow runs and blocked merges.
+	Title   string `json:"title"`
+	Muted   string `json:"muted"`
+	Accent  string `json:"accent"`
+	Text    string `json:"text"`
+	Success string `json:"success"`
+	Failure string `json:"failure"`
+	Running string `json:"running"`
+	Warning string `json:"warning"`
+
+	// Icons puts ✓, ✗, ● or ○ before each status, for when colours are
+	// missing or hard to tell apart.
+	Icons bool `json:"icons"`
+}
+
+// builtinThemes are the themes that need no file, in the order --help lists
+// them.
+var builtinThemes = []struct {
+	name  string
+	theme Theme
+}{
+	{"dark", Theme{
+		Title: "212", Muted: "245", Accent: "39", Text: "254", Icons: true,
+		Success: "34", Failure: "160", Running: "214", Warning: "202",
+	}},
+	{"light", Theme{
+		Title: "90", Muted: "243", Accent: "26", Text: "236", Icons: true,
+		Success: "22", Failure: "124", Running: "130", Warning: "166",
+	}},
+	// The 16 basic colours at full brightness, which any terminal renders
+	// at the user's own contrast settings.
+	{"high-contrast", Theme{
+		Title: "15", Muted: "7", Accent: "14", Text: "15", Icons: true,
+		Success: "10", Failure: "9", Running: "11", Warning: "13",
+	}},
+	// Success and failure differ in hue and lightness (the Okabe-Ito blue
+	// and vermillion) rather than as green and red.
+	{"deuteranopia", Theme{
+		Title: "#cc79a7", Muted: "245", Accent: "#56b4e9", Text: "254", Icons: true,
+		Success: "#0072b2", Failure: "#d55e00", Running: "#f0e442", Warning: "#e69f00",
+	}},
+}
+
+// ThemeNames lists the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, len(builtinThemes))
+	for i, b := range builtinThemes {
+		names[i] = b.name
+	}
+	return names
+}
+
+// BuiltinTheme returns the built-in theme called name.
+func BuiltinTheme(name string) (Theme, bool) {
+	for _, b := range builtinThemes {
+		if b.name == name {
+			return b.theme, true
+		}
+	}
+	return Theme{}, false
+}
+
+// DefaultThemesDir returns the directory user-defined themes are loaded from,
+// or "" if the user has no home directory.
+func DefaultThemesDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ghamon", "themes")
+}
+
+// LoadTheme returns theme name ("" for DefaultTheme). A file <name>.json in
+// dir takes precedence over a built-in theme of that name, and gets the
+// colours it leaves out from DefaultTheme. On error the default theme is
+// returned along with it.
+func LoadTheme(dir, name string) (Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+	fallback, _ := BuiltinTheme(DefaultTheme)
+	if dir != "" {
+		path := filepath.Join(dir, name+".json")
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			t := fallback
+			if err := json.Unmarshal(data, &t); err != nil {
+				return fallback, fmt.Errorf("parsing theme file %q: %w", path, err)
+			}
+			return t, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return fallback, fmt.Errorf("reading theme file %q: %w", path, err)
+		}
+	}
+	if t, ok := BuiltinTheme(name); ok {
+		return t, nil
+	}
+	return fallback, fmt.Errorf("unknown theme %q (built-in themes: %s)", name, strings.Join(ThemeNames(), ", "))
+}
+
+// NoColorTheme is the theme for NO_COLOR (https://no-color.org): no colours
+// at all, with the status icons telling statuses apart.
+func NoColorTheme() Theme {
+	return Theme{Icons: true}
+}
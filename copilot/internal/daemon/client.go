@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	ghclient "ghamon/internal/github"
+)
+
+const dialTimeout = 500 * time.Millisecond
+
+// Client talks to a running daemon. It implements ghclient.Client so the TUI
+// can use it in place of a direct GitHub client.
+type Client struct {
+	path string
+}
+
+// Dial checks that a daemon speaking ProtocolVersion is listening on path and
+// returns a Client for it.
+func Dial(ctx context.Context, path string) (*Client, error) {
+	c := &Client{path: path}
+	resp, err := c.roundTrip(ctx, Request{Type: RequestPing})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Type != ResponsePong {
+		return nil, fmt.Errorf("unexpected %q response to ping", resp.Type)
+	}
+	return c, nil
+}
+
+// GetWorkflowStatuses returns the daemon's cached runs for owner/repo.
+func (c *Client) GetWorkflowStatuses(ctx context.Context, owner, repo, workflowFile string) ([]ghclient.WorkflowRun, error) {
+	resp, err := c.roundTrip(ctx, Request{Type: RequestSnapshot, Repo: owner + "/" + repo, Workflow: workflowFile})
+	if err != nil {
+		return nil, err
+	}
+	if resp.FetchError != nil {
+		return nil, resp.FetchError
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("daemon: %s", resp.Error)
+	}
+	return resp.Runs, nil
+}
+
+// Subscribe streams change events for repos until ctx is cancelled or the
+// daemon goes away, at which point the returned channel is closed.
+func (c *Client) Subscribe(ctx context.Context, repos []string, workflow string) (<-chan Response, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := Request{Version: ProtocolVersion, Type: RequestSubscribe, Repos: repos, Workflow: workflow}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("subscribing: %w", err)
+	}
+
+	events := make(chan Response)
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	go func() {
+		defer close(events)
+		dec := json.NewDecoder(bufio.NewReader(conn))
+		for {
+			var resp Response
+			if err := dec.Decode(&resp); err != nil {
+				return
+			}
+			if resp.Type != ResponseEvent {
+				continue
+			}
+			select {
+			case events <- resp:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// roundTrip sends a single request on a fresh connection and reads one reply.
+func (c *Client) roundTrip(ctx context.Context, req Request) (Response, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req.Version = ProtocolVersion
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return Response{}, fmt.Errorf("sending %s request: %w", req.Type, err)
+	}
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("reading %s response: %w", req.Type, err)
+	}
+	if resp.Version != ProtocolVersion {
+		return Response{}, fmt.Errorf("daemon speaks protocol version %d, want %d", resp.Version, ProtocolVersion)
+	}
+	if resp.Type == ResponseError {
+		return Response{}, fmt.Errorf("daemon: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	d := net.Dialer{Timeout: dialTimeout}
+	conn, err := d.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to daemon at %s: %w", c.path, err)
+	}
+	return conn, nil
+}
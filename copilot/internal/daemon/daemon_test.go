@@ -0,0 +1,257 @@
+package daemon_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v68/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"ghamon/internal/daemon"
+	ghclient "ghamon/internal/github"
+)
+
+// MockGHClient is a mock of ghclient.Client.
+type MockGHClient struct {
+	mock.Mock
+}
+
+func (m *MockGHClient) GetWorkflowStatuses(ctx context.Context, owner, repo, workflowFile string) ([]ghclient.WorkflowRun, error) {
+	args := m.Called(ctx, owner, repo, workflowFile)
+	runs, _ := args.Get(0).([]ghclient.WorkflowRun)
+	return runs, args.Error(1)
+}
+
+// startDaemon runs a daemon on a temporary socket for the duration of the test.
+func startDaemon(t *testing.T, gh ghclient.Client, interval time.Duration) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ghamon.sock")
+	ln, err := daemon.Listen(path, daemon.SocketAccess{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		daemon.NewServer(gh, interval).Serve(ctx, ln)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return path
+}
+
+func TestDial(t *testing.T) {
+	path := startDaemon(t, &MockGHClient{}, time.Hour)
+	_, err := daemon.Dial(context.Background(), path)
+	assert.NoError(t, err)
+}
+
+func TestDial_NoDaemon(t *testing.T) {
+	_, err := daemon.Dial(context.Background(), filepath.Join(t.TempDir(), "missing.sock"))
+	assert.Error(t, err)
+}
+
+func TestListen_AlreadyRunning(t *testing.T) {
+	path := startDaemon(t, &MockGHClient{}, time.Hour)
+	_, err := daemon.Listen(path, daemon.SocketAccess{})
+	assert.Error(t, err)
+}
+
+func TestListen_Access(t *testing.T) {
+	me, err := user.Current()
+	require.NoError(t, err)
+	group, err := user.LookupGroupId(me.Gid)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		access  daemon.SocketAccess
+		mode    os.FileMode
+		dirMode os.FileMode
+	}{
+		{"private", daemon.SocketAccess{}, 0o600, 0o700},
+		{"group", daemon.SocketAccess{Group: group.Name}, 0o660, 0o750},
+		{"group by id", daemon.SocketAccess{Group: group.Gid, Mode: 0o666}, 0o666, 0o755},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "shared", "ghamon.sock")
+			ln, err := daemon.Listen(path, tt.access)
+			require.NoError(t, err)
+			defer ln.Close()
+
+			for p, want := range map[string]os.FileMode{path: tt.mode, filepath.Dir(path): tt.dirMode} {
+				fi, err := os.Stat(p)
+				require.NoError(t, err)
+				assert.Equal(t, want, fi.Mode().Perm(), p)
+				if tt.access.Group != "" {
+					assert.Equal(t, me.Gid, strconv.Itoa(int(fi.Sys().(*syscall.Stat_t).Gid)), p)
+				}
+			}
+		})
+	}
+
+	_, err = daemon.Listen(filepath.Join(t.TempDir(), "ghamon.sock"), daemon.SocketAccess{Group: "no-such-group-ghamon"})
+	assert.ErrorContains(t, err, "unknown socket group")
+}
+
+func TestSnapshot_SharedCache(t *testing.T) {
+	gh := &MockGHClient{}
+	runs := []ghclient.WorkflowRun{{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"}}
+	gh.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return(runs, nil).Once()
+
+	path := startDaemon(t, gh, time.Hour)
+
+	// Several clients asking for the same repository cost one API call.
+	for i := 0; i < 3; i++ {
+		c, err := daemon.Dial(context.Background(), path)
+		require.NoError(t, err)
+		got, err := c.GetWorkflowStatuses(context.Background(), "owner", "repo", "")
+		require.NoError(t, err)
+		assert.Equal(t, runs, got)
+	}
+	gh.AssertExpectations(t)
+}
+
+func TestSnapshot_Error(t *testing.T) {
+	gh := &MockGHClient{}
+	gh.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return(nil, assert.AnError)
+
+	path := startDaemon(t, gh, time.Hour)
+	c, err := daemon.Dial(context.Background(), path)
+	require.NoError(t, err)
+
+	_, err = c.GetWorkflowStatuses(context.Background(), "owner", "repo", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), assert.AnError.Error())
+}
+
+func TestSnapshot_FetchError(t *testing.T) {
+	gh := &MockGHClient{}
+	gh.On("GetWorkflowStatuses", mock.Anything, "owner", "gone", "").
+		Return(nil, &ghclient.FetchError{StatusCode: 404, Message: "Not Found"})
+
+	path := startDaemon(t, gh, time.Hour)
+	c, err := daemon.Dial(context.Background(), path)
+	require.NoError(t, err)
+
+	_, err = c.GetWorkflowStatuses(context.Background(), "owner", "gone", "")
+	var fe *ghclient.FetchError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, 404, fe.StatusCode)
+	assert.False(t, fe.Retryable)
+}
+
+func TestSubscribe_ReceivesChanges(t *testing.T) {
+	gh := &MockGHClient{}
+	running := []ghclient.WorkflowRun{{Repo: "owner/repo", Workflow: "CI", Status: "in_progress"}}
+	done := []ghclient.WorkflowRun{{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure"}}
+	gh.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return(running, nil).Once()
+	gh.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return(done, nil)
+
+	path := startDaemon(t, gh, 20*time.Millisecond)
+	c, err := daemon.Dial(context.Background(), path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := c.Subscribe(ctx, []string{"owner/repo"}, "")
+	require.NoError(t, err)
+
+	var last daemon.Response
+	require.Eventually(t, func() bool {
+		select {
+		case last = <-events:
+			return last.Runs[0].Conclusion == "failure"
+		default:
+			return false
+		}
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, daemon.ResponseEvent, last.Type)
+	assert.Equal(t, "owner/repo", last.Repo)
+}
+
+func TestProtocol_VersionMismatch(t *testing.T) {
+	path := startDaemon(t, &MockGHClient{}, time.Hour)
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, json.NewEncoder(conn).Encode(daemon.Request{Version: daemon.ProtocolVersion + 1, Type: daemon.RequestPing}))
+	var resp daemon.Response
+	require.NoError(t, json.NewDecoder(bufio.NewReader(conn)).Decode(&resp))
+	assert.Equal(t, daemon.ResponseError, resp.Type)
+	assert.Contains(t, resp.Error, "unsupported protocol version")
+}
+
+func TestProtocol_UnknownRequest(t *testing.T) {
+	path := startDaemon(t, &MockGHClient{}, time.Hour)
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, json.NewEncoder(conn).Encode(daemon.Request{Version: daemon.ProtocolVersion, Type: "bogus"}))
+	var resp daemon.Response
+	require.NoError(t, json.NewDecoder(bufio.NewReader(conn)).Decode(&resp))
+	assert.Equal(t, daemon.ResponseError, resp.Type)
+}
+
+func TestListen_OwnerOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "ghamon.sock")
+	ln, err := daemon.Listen(path, daemon.SocketAccess{})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	dir, err := os.Stat(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), dir.Mode().Perm())
+}
+
+func TestDefaultSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	assert.Equal(t, "/run/user/1000/ghamon.sock", daemon.DefaultSocketPath())
+}
+
+func TestSnapshot_RateLimitShared(t *testing.T) {
+	gh := &MockGHClient{}
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/owner/one", nil)
+	limited := &gogithub.RateLimitError{
+		Rate:     gogithub.Rate{Reset: gogithub.Timestamp{Time: time.Now().Add(time.Hour)}},
+		Response: &http.Response{StatusCode: http.StatusForbidden, Request: req},
+		Message:  "API rate limit exceeded",
+	}
+	gh.On("GetWorkflowStatuses", mock.Anything, "owner", "one", "").Return(nil, limited).Once()
+
+	path := startDaemon(t, gh, time.Hour)
+	c, err := daemon.Dial(context.Background(), path)
+	require.NoError(t, err)
+
+	_, err = c.GetWorkflowStatuses(context.Background(), "owner", "one", "")
+	require.Error(t, err)
+
+	// Until the limit resets, no other repository is fetched.
+	_, err = c.GetWorkflowStatuses(context.Background(), "owner", "two", "")
+	var fe *ghclient.FetchError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, http.StatusForbidden, fe.StatusCode)
+	assert.True(t, fe.Retryable)
+	gh.AssertExpectations(t)
+}
@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	ghclient "ghamon/internal/github"
+)
+
+// ProtocolVersion is the version of the wire protocol spoken over the daemon
+// socket. It must be bumped whenever Request or Response change incompatibly.
+const ProtocolVersion = 1
+
+// Request types sent by clients.
+const (
+	RequestPing      = "ping"
+	RequestSnapshot  = "snapshot"
+	RequestSubscribe = "subscribe"
+)
+
+// Response types sent by the daemon.
+const (
+	ResponsePong     = "pong"
+	ResponseSnapshot = "snapshot"
+	ResponseEvent    = "event"
+	ResponseError    = "error"
+)
+
+// Request is a single newline-delimited JSON message sent by a client.
+type Request struct {
+	Version  int      `json:"version"`
+	Type     string   `json:"type"`
+	Repo     string   `json:"repo,omitempty"`
+	Repos    []string `json:"repos,omitempty"`
+	Workflow string   `json:"workflow,omitempty"`
+}
+
+// Response is a single newline-delimited JSON message sent by the daemon.
+// Snapshot responses answer a snapshot request; event responses are pushed to
+// subscribers whenever the runs for one of their repositories change.
+type Response struct {
+	Version   int                    `json:"version"`
+	Type      string                 `json:"type"`
+	Repo      string                 `json:"repo,omitempty"`
+	Workflow  string                 `json:"workflow,omitempty"`
+	Runs      []ghclient.WorkflowRun `json:"runs,omitempty"`
+	FetchedAt time.Time              `json:"fetched_at,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	// FetchError details Error when it comes from GitHub, so clients can
+	// tell e.g. a missing repository from a rate limit.
+	FetchError *ghclient.FetchError `json:"fetch_error,omitempty"`
+}
+
+// DefaultSocketPath returns the default daemon socket path, or "" if the
+// user has neither a runtime directory nor a home directory. The socket lives
+// in $XDG_RUNTIME_DIR, or else ~/.ghamon, because any client that can reach
+// it is served with the daemon owner's token; a daemon shared with a team
+// listens elsewhere, on a --socket given to its --socket-group.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "ghamon.sock")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ghamon", "ghamon.sock")
+}
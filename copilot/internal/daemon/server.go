@@ -0,0 +1,406 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ghclient "ghamon/internal/github"
+)
+
+// idleEntries is the number of poll intervals after which a repository that
+// no client has asked about (and nobody is subscribed to) stops being polled.
+const idleEntries = 10
+
+type key struct {
+	repo     string
+	workflow string
+}
+
+type entry struct {
+	mu            sync.Mutex // serialises fetches for this key
+	runs          []ghclient.WorkflowRun
+	err           error
+	fetchedAt     time.Time
+	lastRequested time.Time
+}
+
+// Server owns polling, caching and the API budget on behalf of any number of
+// TUI clients. Each (repo, workflow) pair is fetched at most once per interval
+// no matter how many clients ask for it, and once GitHub's rate limit is used
+// up no requests are made for anyone until it resets.
+type Server struct {
+	client   ghclient.Client
+	interval time.Duration
+
+	mu           sync.Mutex
+	entries      map[key]*entry
+	subs         map[chan Response][]key
+	limitedUntil time.Time // when the exhausted rate limit resets
+}
+
+// NewServer creates a Server that refreshes its cache every interval.
+func NewServer(client ghclient.Client, interval time.Duration) *Server {
+	return &Server{
+		client:   client,
+		interval: interval,
+		entries:  make(map[key]*entry),
+		subs:     make(map[chan Response][]key),
+	}
+}
+
+// SocketAccess is who may connect to the daemon socket besides the user
+// running the daemon. Anyone who can connect is served runs fetched with
+// that user's token.
+type SocketAccess struct {
+	// Group, a group name or ID, is given the socket, and a directory
+	// created for it, so that its members can connect if Mode allows.
+	Group string
+	// Mode holds the socket's permission bits: 0600 if zero, or 0660 if
+	// Group is set.
+	Mode os.FileMode
+}
+
+// mode returns the socket's permission bits.
+func (a SocketAccess) mode() os.FileMode {
+	switch {
+	case a.Mode != 0:
+		return a.Mode.Perm()
+	case a.Group != "":
+		return 0o660
+	}
+	return 0o600
+}
+
+// dirMode returns the permission bits of a directory created for the socket:
+// private, except that whoever may use the socket may also reach it.
+func (a SocketAccess) dirMode() os.FileMode {
+	dir, m := os.FileMode(0o700), a.mode()
+	if m&0o070 != 0 {
+		dir |= 0o050
+	}
+	if m&0o007 != 0 {
+		dir |= 0o005
+	}
+	return dir
+}
+
+// gid returns the ID of a.Group, or -1 if it is not set.
+func (a SocketAccess) gid() (int, error) {
+	if a.Group == "" {
+		return -1, nil
+	}
+	g, err := user.LookupGroup(a.Group)
+	if err != nil {
+		var idErr error
+		if g, idErr = user.LookupGroupId(a.Group); idErr != nil {
+			return 0, fmt.Errorf("unknown socket group %q", a.Group)
+		}
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// Listen creates the Unix socket at path, removing a stale socket left by a
+// previous daemon. It fails if another daemon is already listening there.
+// The socket, and its directory if Listen creates it, are open to the user
+// running the daemon and to whoever access lets in.
+func Listen(path string, access SocketAccess) (net.Listener, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no socket path: set --socket")
+	}
+	gid, err := access.gid()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("creating socket directory: %w", err)
+		}
+		// MkdirAll's mode is cut by the umask; set the one asked for.
+		if err := os.Chmod(dir, access.dirMode()); err != nil {
+			return nil, fmt.Errorf("setting permissions on %s: %w", dir, err)
+		}
+		if err := os.Lchown(dir, -1, gid); err != nil {
+			return nil, fmt.Errorf("giving %s to group %s: %w", dir, access.Group, err)
+		}
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("daemon already running on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing stale socket %s: %w", path, err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	if err := os.Lchown(path, -1, gid); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("giving %s to group %s: %w", path, access.Group, err)
+	}
+	if err := os.Chmod(path, access.mode()); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("setting permissions on %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// Serve accepts client connections on ln and polls GitHub until ctx is
+// cancelled. The listener is closed when Serve returns.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	go s.pollLoop(ctx)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+func (s *Server) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx)
+		}
+	}
+}
+
+// poll refreshes every tracked key and notifies subscribers of changes.
+func (s *Server) poll(ctx context.Context) {
+	now := time.Now()
+	s.mu.Lock()
+	subscribed := make(map[key]bool)
+	for _, keys := range s.subs {
+		for _, k := range keys {
+			subscribed[k] = true
+		}
+	}
+	var keys []key
+	for k, e := range s.entries {
+		if !subscribed[k] && now.Sub(e.lastRequested) > idleEntries*s.interval {
+			delete(s.entries, k)
+			continue
+		}
+		keys = append(keys, k)
+	}
+	s.mu.Unlock()
+
+	for _, k := range keys {
+		s.refresh(ctx, k)
+	}
+}
+
+// refresh fetches k from GitHub and publishes an event if the runs changed.
+func (s *Server) refresh(ctx context.Context, k key) {
+	e := s.entry(k)
+	e.mu.Lock()
+	prev, prevErr := e.runs, e.err
+	s.fetch(ctx, k, e)
+	changed := !reflect.DeepEqual(prev, e.runs) || (prevErr == nil) != (e.err == nil)
+	resp := e.response(k, ResponseEvent)
+	e.mu.Unlock()
+
+	if changed {
+		s.publish(k, resp)
+	}
+}
+
+// snapshot returns the cached runs for k, fetching them first if the cache is
+// empty or older than the poll interval.
+func (s *Server) snapshot(ctx context.Context, k key) Response {
+	e := s.entry(k)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastRequested = time.Now()
+	if e.fetchedAt.IsZero() || time.Since(e.fetchedAt) >= s.interval {
+		s.fetch(ctx, k, e)
+	}
+	return e.response(k, ResponseSnapshot)
+}
+
+// fetch must be called with e.mu held. While the rate limit is used up, e
+// keeps what it has; an entry never fetched gets a rate limit error.
+func (s *Server) fetch(ctx context.Context, k key, e *entry) {
+	s.mu.Lock()
+	until := s.limitedUntil
+	s.mu.Unlock()
+	if time.Now().Before(until) {
+		if e.fetchedAt.IsZero() {
+			e.err = &ghclient.FetchError{
+				StatusCode: http.StatusForbidden,
+				Message:    "API rate limit exceeded until " + until.Format(time.Kitchen),
+				Retryable:  true,
+			}
+		}
+		return
+	}
+
+	parts := strings.SplitN(k.repo, "/", 2)
+	if len(parts) != 2 {
+		e.runs, e.err = nil, &ghclient.FetchError{Message: fmt.Sprintf("invalid repository %q", k.repo)}
+	} else {
+		e.runs, e.err = s.client.GetWorkflowStatuses(ctx, parts[0], parts[1], k.workflow)
+	}
+	e.fetchedAt = time.Now()
+
+	if reset, ok := ghclient.RateLimitReset(e.err); ok {
+		s.mu.Lock()
+		if reset.After(s.limitedUntil) {
+			s.limitedUntil = reset
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) entry(k key) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[k]
+	if !ok {
+		e = &entry{lastRequested: time.Now()}
+		s.entries[k] = e
+	}
+	return e
+}
+
+// response must be called with e.mu held.
+func (e *entry) response(k key, typ string) Response {
+	resp := Response{
+		Version:   ProtocolVersion,
+		Type:      typ,
+		Repo:      k.repo,
+		Workflow:  k.workflow,
+		Runs:      e.runs,
+		FetchedAt: e.fetchedAt,
+	}
+	if e.err != nil {
+		resp.Error = e.err.Error()
+		resp.FetchError = ghclient.NewFetchError(e.err)
+	}
+	return resp
+}
+
+func (s *Server) publish(k key, resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch, keys := range s.subs {
+		for _, sk := range keys {
+			if sk != k {
+				continue
+			}
+			select {
+			case ch <- resp:
+			default:
+				// Slow subscriber; drop the event rather than stall polling.
+			}
+			break
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	enc := json.NewEncoder(conn)
+
+	for {
+		var req Request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		if req.Version != ProtocolVersion {
+			enc.Encode(errorResponse(fmt.Errorf("unsupported protocol version %d (daemon speaks %d)", req.Version, ProtocolVersion)))
+			return
+		}
+
+		switch req.Type {
+		case RequestPing:
+			if err := enc.Encode(Response{Version: ProtocolVersion, Type: ResponsePong}); err != nil {
+				return
+			}
+		case RequestSnapshot:
+			if err := enc.Encode(s.snapshot(ctx, key{repo: req.Repo, workflow: req.Workflow})); err != nil {
+				return
+			}
+		case RequestSubscribe:
+			s.subscribe(ctx, conn, enc, req)
+			return
+		default:
+			enc.Encode(errorResponse(fmt.Errorf("unknown request type %q", req.Type)))
+			return
+		}
+	}
+}
+
+// subscribe streams change events for the requested repositories until the
+// client disconnects or the daemon shuts down.
+func (s *Server) subscribe(ctx context.Context, conn net.Conn, enc *json.Encoder, req Request) {
+	keys := make([]key, 0, len(req.Repos))
+	for _, repo := range req.Repos {
+		keys = append(keys, key{repo: repo, workflow: req.Workflow})
+		s.entry(keys[len(keys)-1])
+	}
+
+	ch := make(chan Response, 16)
+	s.mu.Lock()
+	s.subs[ch] = keys
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, ch)
+		s.mu.Unlock()
+	}()
+
+	// The client never sends anything after subscribing, so a read returning
+	// means it has gone away.
+	closed := make(chan struct{})
+	go func() {
+		var buf [1]byte
+		conn.Read(buf[:])
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case resp := <-ch:
+			if err := enc.Encode(resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func errorResponse(err error) Response {
+	return Response{Version: ProtocolVersion, Type: ResponseError, Error: err.Error()}
+}
@@ -0,0 +1,116 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// maxArtifactRedirects is how many redirects to follow when asking GitHub for
+// an artifact's download location.
+const maxArtifactRedirects = 10
+
+// newDownloadClient returns the client for artifact downloads. Failed requests
+// are retried as API requests are, but only the wait for a response is
+// bounded: an artifact may take longer than DefaultTimeout to arrive.
+func newDownloadClient() *http.Client {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.ResponseHeaderTimeout = DefaultTimeout
+	return &http.Client{Transport: &RetryTransport{Base: base, Retries: DefaultRetries, Backoff: DefaultBackoff}}
+}
+
+// Artifact is a file uploaded by a workflow run.
+type Artifact struct {
+	ID          int64
+	Name        string
+	SizeInBytes int64
+	ExpiresAt   time.Time
+	Expired     bool
+}
+
+// ArtifactClient is the interface for listing and downloading workflow run
+// artifacts.
+type ArtifactClient interface {
+	// ListArtifacts fetches the artifacts of a workflow run.
+	ListArtifacts(ctx context.Context, owner, repo string, runID int64) ([]Artifact, error)
+	// DownloadArtifact writes the zip archive of an artifact to w. progress,
+	// if not nil, is called as bytes arrive with the number written so far
+	// and the total, or 0 if the total is unknown.
+	DownloadArtifact(ctx context.Context, owner, repo string, id int64, w io.Writer, progress func(done, total int64)) error
+}
+
+// NewArtifactClient creates an artifact client authenticated with the provided
+// token.
+func NewArtifactClient(token string) ArtifactClient {
+	return newClient(token, 0)
+}
+
+// ListArtifacts fetches the artifacts of run runID in owner/repo.
+func (c *ghClient) ListArtifacts(ctx context.Context, owner, repo string, runID int64) ([]Artifact, error) {
+	list, _, err := c.gh.Actions.ListWorkflowRunArtifacts(ctx, owner, repo, runID, &gogithub.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("listing artifacts of run %d in %s/%s: %w", runID, owner, repo, err)
+	}
+	artifacts := make([]Artifact, 0, len(list.Artifacts))
+	for _, a := range list.Artifacts {
+		artifacts = append(artifacts, Artifact{
+			ID:          a.GetID(),
+			Name:        a.GetName(),
+			SizeInBytes: a.GetSizeInBytes(),
+			ExpiresAt:   a.GetExpiresAt().Time,
+			Expired:     a.GetExpired(),
+		})
+	}
+	return artifacts, nil
+}
+
+// DownloadArtifact asks GitHub where artifact id is stored and streams it from
+// there. The storage URL is pre-signed, so it is fetched without the token.
+func (c *ghClient) DownloadArtifact(ctx context.Context, owner, repo string, id int64, w io.Writer, progress func(done, total int64)) error {
+	u, _, err := c.gh.Actions.DownloadArtifact(ctx, owner, repo, id, maxArtifactRedirects)
+	if err != nil {
+		return fmt.Errorf("locating artifact %d in %s/%s: %w", id, owner, repo, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("downloading artifact %d: %w", id, err)
+	}
+	resp, err := c.download.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading artifact %d: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading artifact %d: %s", id, resp.Status)
+	}
+
+	var r io.Reader = resp.Body
+	if progress != nil {
+		r = &progressReader{r: resp.Body, total: max(resp.ContentLength, 0), progress: progress}
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("downloading artifact %d: %w", id, err)
+	}
+	return nil
+}
+
+// progressReader reports how much of r has been read.
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.done += int64(n)
+		p.progress(p.done, p.total)
+	}
+	return n, err
+}
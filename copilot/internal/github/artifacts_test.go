@@ -0,0 +1,30 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadArtifact_FollowsStorageURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/actions/artifacts/7/zip", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://"+r.Host+"/storage/7?sig=abc", http.StatusFound)
+	})
+	mux.HandleFunc("/storage/7", func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"), "the pre-signed URL is fetched without the token")
+		w.Write([]byte("PK"))
+	})
+	c := newTestClient(t, mux, 0)
+
+	var buf strings.Builder
+	var done int64
+	err := c.DownloadArtifact(context.Background(), "owner", "repo", 7, &buf, func(n, _ int64) { done = n })
+	require.NoError(t, err)
+	assert.Equal(t, "PK", buf.String())
+	assert.Equal(t, int64(2), done)
+}
@@ -3,7 +3,10 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	gogithub "github.com/google/go-github/v68/github"
@@ -12,16 +15,74 @@ import (
 
 // WorkflowRun represents the status of a single workflow run.
 type WorkflowRun struct {
+	ID         int64
 	Repo       string
 	Workflow   string
 	Status     string
 	Conclusion string
+	CreatedAt  time.Time
+	StartedAt  time.Time
 	UpdatedAt  time.Time
 	URL        string
+
+	// HeadBranch and HeadSHA are the branch and commit the run is for, and
+	// Actor the login of the user who triggered it.
+	HeadBranch string
+	HeadSHA    string
+	Actor      string
+
+	// DefaultBranch names the branch a failure must be on to count as
+	// failing rather than a warning; empty until it is known.
+	DefaultBranch string
+
+	// MedianDuration is the typical run time of the workflow, taken from
+	// its recent successful runs; zero when there are too few of them.
+	MedianDuration time.Duration
+
+	// History holds the display statuses of the workflow's recent runs,
+	// oldest first and ending with this run.
+	History []string
+
+	// WorkflowID and WorkflowState identify the workflow and whether it is
+	// enabled (see workflows.go). They are zero if unknown.
+	WorkflowID    int64
+	WorkflowState string
+
+	// Err is why the runs could not be fetched, on the error row that
+	// stands in for them. Its Status is "error".
+	Err *FetchError
+}
+
+const (
+	// DefaultHistory is the default number of runs kept in History.
+	DefaultHistory = 10
+
+	// recentRuns is the minimum number of runs fetched per workflow: the
+	// latest one is shown and all of them feed the median duration.
+	recentRuns = 10
+
+	// maxPerPage is the largest page size the API accepts.
+	maxPerPage = 100
+
+	// minMedianSamples is the number of successful runs needed before a
+	// median duration is considered meaningful.
+	minMedianSamples = 3
+)
+
+// OnDefaultBranch reports whether the run is for its repository's default
+// branch. A run whose branch is not known counts as on the default branch.
+func (w WorkflowRun) OnDefaultBranch() bool {
+	return w.HeadBranch == "" || w.DefaultBranch == "" || w.HeadBranch == w.DefaultBranch
 }
 
 // DisplayStatus returns a human-readable combined status string.
 func (w WorkflowRun) DisplayStatus() string {
+	switch w.WorkflowState {
+	case StateDisabledManually:
+		return "disabled"
+	case StateDisabledInactivity:
+		return "auto-disabled"
+	}
 	switch w.Status {
 	case "completed":
 		if w.Conclusion != "" {
@@ -40,47 +101,158 @@ func (w WorkflowRun) DisplayStatus() string {
 	}
 }
 
+// MedianDuration returns the median duration of the successful runs in runs,
+// or zero if there are fewer than three of them.
+func MedianDuration(runs []WorkflowRun) time.Duration {
+	var ds []time.Duration
+	for _, r := range runs {
+		if r.Status != "completed" || r.Conclusion != "success" || r.StartedAt.IsZero() {
+			continue
+		}
+		if d := r.UpdatedAt.Sub(r.StartedAt); d > 0 {
+			ds = append(ds, d)
+		}
+	}
+	if len(ds) < minMedianSamples {
+		return 0
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	mid := len(ds) / 2
+	if len(ds)%2 == 0 {
+		return (ds[mid-1] + ds[mid]) / 2
+	}
+	return ds[mid]
+}
+
 // Client is the interface for fetching workflow data from GitHub.
 type Client interface {
 	GetWorkflowStatuses(ctx context.Context, owner, repo, workflowFile string) ([]WorkflowRun, error)
 }
 
 type ghClient struct {
-	gh *gogithub.Client
+	gh      *gogithub.Client
+	history int
+
+	// download fetches pre-signed storage URLs, without the token (see
+	// artifacts.go).
+	download *http.Client
+
+	mu               sync.Mutex
+	defaultBranches  map[string]defaultBranch // by owner/repo
+	runWorkflowFiles map[int64]string         // by run ID
+
+	// workflowsByFile holds the ID and state of the workflows watched with
+	// --workflow, by owner/repo/file (see workflows.go).
+	workflowsByFile map[string]cachedWorkflow
+
+	// settledChecks holds the finished checks of pull request heads, by
+	// owner/repo and commit SHA (see pulls.go).
+	settledChecks map[string]map[string][]Check
 }
 
 // New creates a new GitHub API client authenticated with the provided token.
-func New(token string) Client {
+// Each run carries the statuses of up to history recent runs of its workflow;
+// they come from the same request as the latest run, so keeping history costs
+// no extra API calls.
+func New(token string, history int) Client {
+	return newClient(token, history)
+}
+
+func newClient(token string, history int) *ghClient {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 	tc := oauth2.NewClient(context.Background(), ts)
-	return &ghClient{gh: gogithub.NewClient(tc)}
+	tc.Transport = NewRetryTransport(tc.Transport)
+	history = max(0, min(history, maxPerPage))
+	return &ghClient{gh: gogithub.NewClient(tc), history: history, download: newDownloadClient()}
+}
+
+// perPage returns how many runs to fetch per workflow.
+func (c *ghClient) perPage() int {
+	return max(c.history, recentRuns)
 }
 
 // GetWorkflowStatuses fetches the latest run for the specified workflow (or all
 // workflows when workflowFile is "").
 func (c *ghClient) GetWorkflowStatuses(ctx context.Context, owner, repo, workflowFile string) ([]WorkflowRun, error) {
+	var runs []WorkflowRun
+	var err error
 	if workflowFile != "" {
-		return c.getByFile(ctx, owner, repo, workflowFile)
+		runs, err = c.getByFile(ctx, owner, repo, workflowFile)
+	} else {
+		runs, err = c.getAll(ctx, owner, repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The default branch only tells failures that matter from others, so the
+	// runs are returned even if it cannot be fetched.
+	if branch, err := c.defaultBranch(ctx, owner, repo); err == nil {
+		for i := range runs {
+			runs[i].DefaultBranch = branch
+		}
 	}
-	return c.getAll(ctx, owner, repo)
+	return runs, nil
+}
+
+// defaultBranchRetry is how long a default branch that could not be fetched
+// is left before trying again.
+const defaultBranchRetry = 5 * time.Minute
+
+// defaultBranch is a repository's default branch, or why it could not be
+// fetched.
+type defaultBranch struct {
+	name    string
+	err     error
+	fetched time.Time
+}
+
+// defaultBranch returns the default branch of a repository. It is fetched
+// once per client; an error is kept for defaultBranchRetry so a repository
+// whose details cannot be read does not cost a request every refresh.
+func (c *ghClient) defaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	full := owner + "/" + repo
+	c.mu.Lock()
+	b, ok := c.defaultBranches[full]
+	c.mu.Unlock()
+	if ok && (b.err == nil || time.Since(b.fetched) < defaultBranchRetry) {
+		return b.name, b.err
+	}
+	b = defaultBranch{fetched: time.Now()}
+	r, _, err := c.gh.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+		b.err = fmt.Errorf("getting %s: %w", full, err)
+	} else {
+		b.name = r.GetDefaultBranch()
+	}
+	c.mu.Lock()
+	if c.defaultBranches == nil {
+		c.defaultBranches = make(map[string]defaultBranch)
+	}
+	c.defaultBranches[full] = b
+	c.mu.Unlock()
+	return b.name, b.err
 }
 
 func (c *ghClient) getByFile(ctx context.Context, owner, repo, file string) ([]WorkflowRun, error) {
 	opts := &gogithub.ListWorkflowRunsOptions{
-		ListOptions: gogithub.ListOptions{PerPage: 1},
+		ListOptions: gogithub.ListOptions{PerPage: c.perPage()},
 	}
 	runs, _, err := c.gh.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, file, opts)
 	if err != nil {
 		return nil, fmt.Errorf("listing workflow runs for %s/%s (%s): %w", owner, repo, file, err)
 	}
-	if runs == nil || len(runs.WorkflowRuns) == 0 {
-		return []WorkflowRun{{
-			Repo:     owner + "/" + repo,
-			Workflow: file,
-			Status:   "no runs",
-		}}, nil
+	run := WorkflowRun{Repo: owner + "/" + repo, Workflow: file, Status: "no runs"}
+	if runs != nil && len(runs.WorkflowRuns) > 0 {
+		run = latestRun(owner, repo, file, runs.WorkflowRuns, c.history)
+	}
+	// Without the workflow's state the run is still worth showing.
+	if wf, err := c.workflowByFile(ctx, owner, repo, file); err == nil {
+		run.WorkflowID, run.WorkflowState = wf.id, wf.state
 	}
-	return []WorkflowRun{runFromAPI(owner, repo, file, runs.WorkflowRuns[0])}, nil
+	return []WorkflowRun{run}, nil
 }
 
 func (c *ghClient) getAll(ctx context.Context, owner, repo string) ([]WorkflowRun, error) {
@@ -109,26 +281,20 @@ func (c *ghClient) getAll(ctx context.Context, owner, repo string) ([]WorkflowRu
 		}
 
 		opts := &gogithub.ListWorkflowRunsOptions{
-			ListOptions: gogithub.ListOptions{PerPage: 1},
+			ListOptions: gogithub.ListOptions{PerPage: c.perPage()},
 		}
 		runs, _, err := c.gh.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, fileName, opts)
-		if err != nil {
-			results = append(results, WorkflowRun{
-				Repo:     owner + "/" + repo,
-				Workflow: wfName,
-				Status:   "error",
-			})
-			continue
-		}
-		if runs == nil || len(runs.WorkflowRuns) == 0 {
-			results = append(results, WorkflowRun{
-				Repo:     owner + "/" + repo,
-				Workflow: wfName,
-				Status:   "no runs",
-			})
-			continue
+		var run WorkflowRun
+		switch {
+		case err != nil:
+			run = WorkflowRun{Repo: owner + "/" + repo, Workflow: wfName, Status: "error", Err: NewFetchError(err)}
+		case runs == nil || len(runs.WorkflowRuns) == 0:
+			run = WorkflowRun{Repo: owner + "/" + repo, Workflow: wfName, Status: "no runs"}
+		default:
+			run = latestRun(owner, repo, wfName, runs.WorkflowRuns, c.history)
 		}
-		results = append(results, runFromAPI(owner, repo, wfName, runs.WorkflowRuns[0]))
+		run.WorkflowID, run.WorkflowState = wf.GetID(), wf.GetState()
+		results = append(results, run)
 	}
 
 	if len(results) == 0 {
@@ -140,10 +306,29 @@ func (c *ghClient) getAll(ctx context.Context, owner, repo string) ([]WorkflowRu
 	return results, nil
 }
 
+// latestRun returns the first (most recent) of runs with the median duration
+// of all of them and the statuses of up to history of them.
+func latestRun(owner, repo, workflow string, runs []*gogithub.WorkflowRun, history int) WorkflowRun {
+	recent := make([]WorkflowRun, len(runs))
+	for i, r := range runs {
+		recent[i] = runFromAPI(owner, repo, workflow, r)
+	}
+	latest := recent[0]
+	latest.MedianDuration = MedianDuration(recent)
+	for i := min(history, len(recent)) - 1; i >= 0; i-- {
+		latest.History = append(latest.History, recent[i].DisplayStatus())
+	}
+	return latest
+}
+
 func runFromAPI(owner, repo, workflow string, r *gogithub.WorkflowRun) WorkflowRun {
 	wr := WorkflowRun{
-		Repo:     owner + "/" + repo,
-		Workflow: workflow,
+		ID:         r.GetID(),
+		Repo:       owner + "/" + repo,
+		Workflow:   workflow,
+		HeadBranch: r.GetHeadBranch(),
+		HeadSHA:    r.GetHeadSHA(),
+		Actor:      r.GetActor().GetLogin(),
 	}
 	if r.Status != nil {
 		wr.Status = *r.Status
@@ -151,6 +336,12 @@ func runFromAPI(owner, repo, workflow string, r *gogithub.WorkflowRun) WorkflowR
 	if r.Conclusion != nil {
 		wr.Conclusion = *r.Conclusion
 	}
+	if r.CreatedAt != nil {
+		wr.CreatedAt = r.CreatedAt.Time
+	}
+	if r.RunStartedAt != nil {
+		wr.StartedAt = r.RunStartedAt.Time
+	}
 	if r.UpdatedAt != nil {
 		wr.UpdatedAt = r.UpdatedAt.Time
 	}
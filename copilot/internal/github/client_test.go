@@ -2,9 +2,13 @@ package github_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
+	gogithub "github.com/google/go-github/v68/github"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -67,6 +71,9 @@ func TestWorkflowRun_DisplayStatus(t *testing.T) {
 		{"queued", ghclient.WorkflowRun{Status: "queued"}, "queued"},
 		{"completed_no_conc", ghclient.WorkflowRun{Status: "completed"}, "completed"},
 		{"empty_status", ghclient.WorkflowRun{}, "unknown"},
+		{"disabled", ghclient.WorkflowRun{Status: "completed", Conclusion: "failure", WorkflowState: ghclient.StateDisabledManually}, "disabled"},
+		{"auto_disabled", ghclient.WorkflowRun{Status: "completed", WorkflowState: ghclient.StateDisabledInactivity}, "auto-disabled"},
+		{"active", ghclient.WorkflowRun{Status: "queued", WorkflowState: ghclient.StateActive}, "queued"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -74,3 +81,130 @@ func TestWorkflowRun_DisplayStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkflowRun_OnDefaultBranch(t *testing.T) {
+	assert.True(t, ghclient.WorkflowRun{HeadBranch: "main", DefaultBranch: "main"}.OnDefaultBranch())
+	assert.False(t, ghclient.WorkflowRun{HeadBranch: "feature", DefaultBranch: "main"}.OnDefaultBranch())
+	assert.True(t, ghclient.WorkflowRun{HeadBranch: "feature"}.OnDefaultBranch(), "an unknown default branch counts")
+}
+
+func TestMedianDuration(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	run := func(conclusion string, d time.Duration) ghclient.WorkflowRun {
+		return ghclient.WorkflowRun{Status: "completed", Conclusion: conclusion, StartedAt: base, UpdatedAt: base.Add(d)}
+	}
+
+	runs := []ghclient.WorkflowRun{
+		run("success", 3*time.Minute),
+		run("failure", 30*time.Minute),
+		run("success", time.Minute),
+		{Status: "in_progress", StartedAt: base},
+	}
+	assert.Zero(t, ghclient.MedianDuration(runs), "too few successful runs")
+
+	runs = append(runs, run("success", 2*time.Minute))
+	assert.Equal(t, 2*time.Minute, ghclient.MedianDuration(runs))
+
+	runs = append(runs, run("success", 6*time.Minute))
+	assert.Equal(t, 150*time.Second, ghclient.MedianDuration(runs), "even count averages the middle pair")
+}
+
+func TestPullRequest_CheckState(t *testing.T) {
+	pr := func(states ...string) ghclient.PullRequest {
+		var p ghclient.PullRequest
+		for i, s := range states {
+			p.Checks = append(p.Checks, ghclient.Check{Name: fmt.Sprint("check", i), State: s})
+		}
+		return p
+	}
+
+	assert.Equal(t, "none", pr().CheckState())
+	assert.Equal(t, "success", pr("success", "skipped").CheckState())
+	assert.Equal(t, "pending", pr("success", "in progress").CheckState())
+	assert.Equal(t, "pending", pr("pending").CheckState())
+	assert.Equal(t, "failure", pr("in progress", "error").CheckState())
+
+	failing := pr("success", "failure", "cancelled").FailingChecks()
+	require.Len(t, failing, 2)
+	assert.Equal(t, "check1", failing[0].Name)
+	assert.Equal(t, "check2", failing[1].Name)
+}
+
+func TestRunner_State(t *testing.T) {
+	assert.Equal(t, "online", ghclient.Runner{Status: "online"}.State())
+	assert.Equal(t, "busy", ghclient.Runner{Status: "online", Busy: true}.State())
+	assert.Equal(t, "offline", ghclient.Runner{Status: "offline", Busy: true}.State())
+}
+
+func TestUnservedJobs(t *testing.T) {
+	runners := []ghclient.Runner{
+		{Name: "linux-1", Status: "online", Busy: true, Labels: []string{"self-hosted", "Linux", "X64"}},
+		{Name: "gpu-1", Status: "offline", Labels: []string{"self-hosted", "Linux", "gpu"}},
+	}
+	jobs := []ghclient.QueuedJob{
+		{Name: "build", Labels: []string{"self-hosted", "linux"}},
+		{Name: "train", Labels: []string{"self-hosted", "gpu"}},
+		{Name: "lint", Labels: []string{"ubuntu-latest"}},
+	}
+
+	unserved := ghclient.UnservedJobs(jobs, runners)
+	require.Len(t, unserved, 1)
+	assert.Equal(t, "train", unserved[0].Name)
+	assert.Empty(t, ghclient.UnservedJobs(jobs[2:], nil), "GitHub-hosted jobs are never flagged")
+}
+
+func TestFailure_Summary(t *testing.T) {
+	exit := ghclient.Annotation{Job: "test", Path: ".github", Level: "failure", Message: "Process completed with exit code 1."}
+	code := ghclient.Annotation{Job: "test", Path: "ghamon/github_test.go", StartLine: 42, Level: "failure", Message: "expected 200\nactual 500"}
+	warning := ghclient.Annotation{Job: "lint", Path: "main.go", StartLine: 3, Level: "warning", Message: "unused variable"}
+	job := ghclient.FailedJob{Name: "test", Step: "Run go test"}
+
+	tests := []struct {
+		name    string
+		failure ghclient.Failure
+		want    string
+	}{
+		{"code annotation", ghclient.Failure{Jobs: []ghclient.FailedJob{job}, Annotations: []ghclient.Annotation{exit, code, warning}},
+			"test: ghamon/github_test.go:42 expected 200"},
+		{"failed step", ghclient.Failure{Jobs: []ghclient.FailedJob{job}, Annotations: []ghclient.Annotation{exit, warning}},
+			`test: step "Run go test" failed`},
+		{"runner annotation", ghclient.Failure{Jobs: []ghclient.FailedJob{{Name: "test"}}, Annotations: []ghclient.Annotation{exit}},
+			"test: Process completed with exit code 1."},
+		{"nothing known", ghclient.Failure{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.failure.Summary())
+		})
+	}
+}
+
+func TestNewFetchError(t *testing.T) {
+	resp := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+	tests := []struct {
+		name      string
+		err       error
+		code      int
+		retryable bool
+		text      string
+	}{
+		{"not found", &gogithub.ErrorResponse{Response: resp(404), Message: "Not Found"}, 404, false, "404 Not Found"},
+		{"bad token", fmt.Errorf("listing runs: %w", &gogithub.ErrorResponse{Response: resp(401), Message: "Bad credentials"}),
+			401, false, "401 Bad credentials"},
+		{"server error", &gogithub.ErrorResponse{Response: resp(502)}, 502, true, "502 Bad Gateway"},
+		{"too many requests", &gogithub.ErrorResponse{Response: resp(429), Message: "slow down"}, 429, true, "429 slow down"},
+		{"rate limit", &gogithub.RateLimitError{Response: resp(403), Message: "API rate limit exceeded"}, 403, true,
+			"403 API rate limit exceeded"},
+		{"network", errors.New("dial tcp: connection refused"), 0, true, "dial tcp: connection refused"},
+		{"cancelled", context.Canceled, 0, false, "context canceled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fe := ghclient.NewFetchError(tt.err)
+			assert.Equal(t, tt.code, fe.StatusCode)
+			assert.Equal(t, tt.retryable, fe.Retryable)
+			assert.Equal(t, tt.text, fe.Error())
+		})
+	}
+	assert.Contains(t, ghclient.NewFetchError(&gogithub.ErrorResponse{Response: resp(401)}).Hint(), "GITHUB_TOKEN")
+}
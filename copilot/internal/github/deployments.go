@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// Deployment is the latest deployment to one environment of a repository.
+type Deployment struct {
+	Repo        string
+	Environment string
+	ID          int64
+	Ref         string
+	SHA         string
+	Creator     string
+	CreatedAt   time.Time
+
+	// State is the display status of the deployment's latest status, e.g.
+	// "success", "failure", "in progress" or "inactive"; "no deployments" if
+	// the environment was never deployed to.
+	State     string
+	UpdatedAt time.Time
+
+	// RunURL links to the workflow run that made the deployment, if known.
+	RunURL string
+}
+
+// DeploymentClient is the interface for fetching deployment data from GitHub.
+type DeploymentClient interface {
+	// GetDeployments fetches the latest deployment to each environment of a
+	// repository.
+	GetDeployments(ctx context.Context, owner, repo string) ([]Deployment, error)
+}
+
+// NewDeploymentClient creates a deployment client authenticated with the
+// provided token.
+func NewDeploymentClient(token string) DeploymentClient {
+	return newClient(token, 0)
+}
+
+// GetDeployments fetches the environments of owner/repo and the latest
+// deployment to each. Each environment costs two further requests: its latest
+// deployment and that deployment's latest status.
+func (c *ghClient) GetDeployments(ctx context.Context, owner, repo string) ([]Deployment, error) {
+	envs, _, err := c.gh.Repositories.ListEnvironments(ctx, owner, repo, &gogithub.EnvironmentListOptions{
+		ListOptions: gogithub.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing environments for %s/%s: %w", owner, repo, err)
+	}
+
+	var results []Deployment
+	for _, env := range envs.Environments {
+		d := Deployment{Repo: owner + "/" + repo, Environment: env.GetName(), State: "no deployments"}
+		deps, _, err := c.gh.Repositories.ListDeployments(ctx, owner, repo, &gogithub.DeploymentsListOptions{
+			Environment: d.Environment,
+			ListOptions: gogithub.ListOptions{PerPage: 1},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing deployments to %s in %s/%s: %w", d.Environment, owner, repo, err)
+		}
+		if len(deps) > 0 {
+			dep := deps[0]
+			d.ID = dep.GetID()
+			d.Ref = dep.GetRef()
+			d.SHA = dep.GetSHA()
+			d.Creator = dep.GetCreator().GetLogin()
+			d.CreatedAt = dep.GetCreatedAt().Time
+			d.UpdatedAt = d.CreatedAt
+			d.State = "pending"
+
+			statuses, _, err := c.gh.Repositories.ListDeploymentStatuses(ctx, owner, repo, d.ID, &gogithub.ListOptions{PerPage: 1})
+			if err != nil {
+				return nil, fmt.Errorf("listing statuses of deployment %d in %s/%s: %w", d.ID, owner, repo, err)
+			}
+			if len(statuses) > 0 {
+				s := statuses[0]
+				d.State = WorkflowRun{Status: s.GetState()}.DisplayStatus()
+				d.UpdatedAt = s.GetCreatedAt().Time
+				// Deployments made from Actions log to their workflow run.
+				d.RunURL = s.GetLogURL()
+				if d.RunURL == "" {
+					d.RunURL = s.GetTargetURL()
+				}
+			}
+		}
+		results = append(results, d)
+	}
+	return results, nil
+}
@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// FetchError is GitHub's answer to a request for a repository's or
+// workflow's runs that failed. The error row shown in place of those runs
+// carries it, and so does the daemon's snapshot, hence the JSON tags.
+//
+// StatusCode is 0 when GitHub never answered (a network error or timeout).
+// Retryable means asking again later may work, as with rate limits and
+// server errors; otherwise something such as the token has to change.
+type FetchError struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	Message    string `json:"message"`
+	Retryable  bool   `json:"retryable,omitempty"`
+}
+
+// NewFetchError describes err, as returned by a GitHub API call.
+func NewFetchError(err error) *FetchError {
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		return fe
+	}
+	var rateErr *gogithub.RateLimitError
+	if errors.As(err, &rateErr) {
+		return &FetchError{StatusCode: statusOf(rateErr.Response), Message: rateErr.Message, Retryable: true}
+	}
+	var abuseErr *gogithub.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return &FetchError{StatusCode: statusOf(abuseErr.Response), Message: abuseErr.Message, Retryable: true}
+	}
+	var respErr *gogithub.ErrorResponse
+	if errors.As(err, &respErr) {
+		code := statusOf(respErr.Response)
+		return &FetchError{StatusCode: code, Message: respErr.Message, Retryable: retryableStatus(code)}
+	}
+	// No response: the network, a timeout or a cancelled request.
+	return &FetchError{Message: err.Error(), Retryable: !errors.Is(err, context.Canceled)}
+}
+
+// RateLimitReset reports whether err is GitHub's rate limit being used up
+// and, if so, when requests may be made again.
+func RateLimitReset(err error) (time.Time, bool) {
+	var rateErr *gogithub.RateLimitError
+	if errors.As(err, &rateErr) {
+		return rateErr.Rate.Reset.Time, true
+	}
+	var abuseErr *gogithub.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		wait := abuseErr.GetRetryAfter()
+		if wait <= 0 {
+			wait = time.Minute
+		}
+		return time.Now().Add(wait), true
+	}
+	return time.Time{}, false
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// retryableStatus reports whether a request answered with code may succeed
+// if made again: rate limits and server errors.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// Error is the status code and GitHub's message, e.g. "404 Not Found",
+// falling back to the status text when there is no message. Without a
+// response it is just the message.
+func (e *FetchError) Error() string {
+	text := e.Message
+	if text == "" {
+		text = http.StatusText(e.StatusCode)
+	}
+	if e.StatusCode == 0 {
+		return text
+	}
+	return strconv.Itoa(e.StatusCode) + " " + text
+}
+
+// Hint tells the user in a few words what to do about e, or that ghamon
+// will try again by itself. It is empty when there is nothing useful to say.
+func (e *FetchError) Hint() string {
+	const retrying = "; ghamon tries again at the next refresh"
+	code := e.StatusCode
+	switch {
+	case code == http.StatusTooManyRequests, code == http.StatusForbidden && e.Retryable:
+		return "GitHub's rate limit was reached" + retrying
+	case code == http.StatusUnauthorized:
+		return "GitHub did not accept the token: set a valid GITHUB_TOKEN"
+	case code == http.StatusForbidden:
+		return "the token lacks permission to read Actions in this repository"
+	case code == http.StatusNotFound:
+		return "no such repository or workflow, or the token cannot see it"
+	case code >= 500:
+		return "GitHub failed with a server error" + retrying
+	case code == 0 && e.Retryable:
+		return "no connection to GitHub" + retrying
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// runnerAnnotationPath is the path GitHub gives the annotations it adds itself,
+// such as "Process completed with exit code 1.".
+const runnerAnnotationPath = ".github"
+
+// Annotation is a message a check run attached to a line of code.
+type Annotation struct {
+	Job       string
+	Path      string
+	StartLine int
+	Level     string // "notice", "warning" or "failure"
+	Title     string
+	Message   string
+}
+
+// Location returns "path:line", or just the path if the line is unknown.
+func (a Annotation) Location() string {
+	if a.StartLine == 0 {
+		return a.Path
+	}
+	return fmt.Sprintf("%s:%d", a.Path, a.StartLine)
+}
+
+// FailedJob is a job of a workflow run that failed, with the step that failed.
+type FailedJob struct {
+	Name string
+	Step string
+}
+
+// Failure explains why a workflow run failed.
+type Failure struct {
+	Jobs        []FailedJob
+	Annotations []Annotation
+}
+
+// Summary returns a one-line failure reason, e.g.
+// "test: ghamon/github_test.go:42 expected 200". It prefers an annotation
+// pointing at the code, then the failed step, then any other failure
+// annotation.
+func (f Failure) Summary() string {
+	var fallback *Annotation
+	for i, a := range f.Annotations {
+		if a.Level != "failure" {
+			continue
+		}
+		if a.Path != runnerAnnotationPath && a.Path != "" {
+			return fmt.Sprintf("%s: %s %s", a.Job, a.Location(), firstLine(a.Message))
+		}
+		if fallback == nil {
+			fallback = &f.Annotations[i]
+		}
+	}
+	if len(f.Jobs) > 0 && f.Jobs[0].Step != "" {
+		return fmt.Sprintf("%s: step %q failed", f.Jobs[0].Name, f.Jobs[0].Step)
+	}
+	if fallback != nil {
+		return fmt.Sprintf("%s: %s", fallback.Job, firstLine(fallback.Message))
+	}
+	if len(f.Jobs) > 0 {
+		return f.Jobs[0].Name + " failed"
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return s
+}
+
+// Failed reports whether the run completed with a failure or timed out.
+func (w WorkflowRun) Failed() bool {
+	return w.Status == "completed" && (w.Conclusion == "failure" || w.Conclusion == "timed_out")
+}
+
+// FailureClient is the interface for finding out why a workflow run failed.
+type FailureClient interface {
+	// GetFailure fetches the failed jobs of a workflow run and the
+	// annotations of their check runs.
+	GetFailure(ctx context.Context, owner, repo string, runID int64) (Failure, error)
+}
+
+// NewFailureClient creates a failure client authenticated with the provided
+// token.
+func NewFailureClient(token string) FailureClient {
+	return newClient(token, 0)
+}
+
+// GetFailure fetches the jobs of the latest attempt of run runID and, for each
+// failed job, the step that failed and its annotations. A job's check run has
+// the job's ID. Failure annotations are listed before warnings and notices.
+func (c *ghClient) GetFailure(ctx context.Context, owner, repo string, runID int64) (Failure, error) {
+	jobs, _, err := c.gh.Actions.ListWorkflowJobs(ctx, owner, repo, runID, &gogithub.ListWorkflowJobsOptions{
+		Filter:      "latest",
+		ListOptions: gogithub.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return Failure{}, fmt.Errorf("listing jobs of run %d in %s/%s: %w", runID, owner, repo, err)
+	}
+
+	var f Failure
+	var others []Annotation
+	for _, j := range jobs.Jobs {
+		if j.GetConclusion() != "failure" {
+			continue
+		}
+		job := FailedJob{Name: j.GetName()}
+		for _, s := range j.Steps {
+			if s.GetConclusion() == "failure" {
+				job.Step = s.GetName()
+				break
+			}
+		}
+		f.Jobs = append(f.Jobs, job)
+
+		anns, _, err := c.gh.Checks.ListCheckRunAnnotations(ctx, owner, repo, j.GetID(), &gogithub.ListOptions{PerPage: 100})
+		if err != nil {
+			return Failure{}, fmt.Errorf("listing annotations of job %d in %s/%s: %w", j.GetID(), owner, repo, err)
+		}
+		for _, a := range anns {
+			ann := Annotation{
+				Job:       job.Name,
+				Path:      a.GetPath(),
+				StartLine: a.GetStartLine(),
+				Level:     a.GetAnnotationLevel(),
+				Title:     a.GetTitle(),
+				Message:   a.GetMessage(),
+			}
+			if ann.Level == "failure" {
+				f.Annotations = append(f.Annotations, ann)
+			} else {
+				others = append(others, ann)
+			}
+		}
+	}
+	f.Annotations = append(f.Annotations, others...)
+	return f, nil
+}
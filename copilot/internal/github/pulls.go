@@ -0,0 +1,241 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// PullRequest is an open pull request with the checks on its head commit.
+type PullRequest struct {
+	Repo               string
+	Number             int
+	Title              string
+	Author             string
+	RequestedReviewers []string
+	Draft              bool
+	URL                string
+	HeadSHA            string
+
+	// MergeableState is GitHub's mergeable_state: "clean", "dirty",
+	// "blocked", "behind", "unstable", "draft" or "unknown".
+	MergeableState string
+
+	Checks []Check
+
+	// Err is why the pull request's mergeability or checks could not be
+	// fetched; the rest of the pull request is still shown.
+	Err *FetchError
+}
+
+// Check is a check run or commit status reported on a pull request's head.
+type Check struct {
+	Name string
+	// State is the check's display status, e.g. "success", "failure",
+	// "in progress" or "pending".
+	State string
+	URL   string
+}
+
+var failingCheckStates = map[string]bool{
+	"failure":         true,
+	"error":           true,
+	"timed_out":       true,
+	"cancelled":       true,
+	"action_required": true,
+	"startup_failure": true,
+}
+
+var pendingCheckStates = map[string]bool{
+	"pending":     true,
+	"queued":      true,
+	"in progress": true,
+	"requested":   true,
+	"waiting":     true,
+}
+
+// Failing reports whether the check failed or needs action.
+func (c Check) Failing() bool {
+	return failingCheckStates[c.State]
+}
+
+// CheckState combines the pull request's checks into one status: "failure"
+// if any check failed, "pending" if any is still running, "success" if all
+// passed, "error" if they could not be fetched and "none" if there are no
+// checks.
+func (p PullRequest) CheckState() string {
+	if len(p.Checks) == 0 {
+		if p.Err != nil {
+			return "error"
+		}
+		return "none"
+	}
+	state := "success"
+	for _, c := range p.Checks {
+		switch {
+		case c.Failing():
+			return "failure"
+		case pendingCheckStates[c.State]:
+			state = "pending"
+		}
+	}
+	return state
+}
+
+// FailingChecks returns the checks that failed.
+func (p PullRequest) FailingChecks() []Check {
+	var failing []Check
+	for _, c := range p.Checks {
+		if c.Failing() {
+			failing = append(failing, c)
+		}
+	}
+	return failing
+}
+
+// PullRequestClient is the interface for fetching pull request data from
+// GitHub.
+type PullRequestClient interface {
+	// Viewer returns the login of the user the client authenticates as.
+	Viewer(ctx context.Context) (string, error)
+	// GetPullRequests fetches the open pull requests of a repository with
+	// their checks and mergeability.
+	GetPullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error)
+}
+
+// NewPullRequestClient creates a pull request client authenticated with the
+// provided token.
+func NewPullRequestClient(token string) PullRequestClient {
+	return newClient(token, 0)
+}
+
+// Viewer returns the login of the authenticated user.
+func (c *ghClient) Viewer(ctx context.Context) (string, error) {
+	u, _, err := c.gh.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("getting authenticated user: %w", err)
+	}
+	return u.GetLogin(), nil
+}
+
+// GetPullRequests fetches the open pull requests of owner/repo. Each pull
+// request costs a further request for its details (for mergeability), and
+// two for its check runs and commit statuses unless they were fetched
+// before for the same head commit and had all finished. A pull request whose
+// details or checks cannot be fetched carries the error.
+func (c *ghClient) GetPullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error) {
+	opts := &gogithub.PullRequestListOptions{
+		State:       "open",
+		ListOptions: gogithub.ListOptions{PerPage: 50},
+	}
+	prs, _, err := c.gh.PullRequests.List(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing pull requests for %s/%s: %w", owner, repo, err)
+	}
+
+	results := make([]PullRequest, 0, len(prs))
+	heads := make(map[string]bool, len(prs))
+	for _, pr := range prs {
+		p := pullRequestFromAPI(owner, repo, pr)
+		heads[p.HeadSHA] = true
+		// List omits mergeability; it is only computed for a single PR.
+		full, _, err := c.gh.PullRequests.Get(ctx, owner, repo, p.Number)
+		if err != nil {
+			p.Err = NewFetchError(err)
+		} else {
+			p.MergeableState = full.GetMergeableState()
+		}
+		if p.MergeableState == "" {
+			p.MergeableState = "unknown"
+		}
+		checks, err := c.cachedChecks(ctx, owner, repo, p.HeadSHA)
+		if err != nil {
+			p.Err = NewFetchError(err)
+		}
+		p.Checks = checks
+		results = append(results, p)
+	}
+	c.pruneChecks(owner+"/"+repo, heads)
+	return results, nil
+}
+
+// cachedChecks returns the checks for commit sha of owner/repo, fetching
+// them unless they were fetched before and none was still pending.
+func (c *ghClient) cachedChecks(ctx context.Context, owner, repo, sha string) ([]Check, error) {
+	full := owner + "/" + repo
+	c.mu.Lock()
+	checks, ok := c.settledChecks[full][sha]
+	c.mu.Unlock()
+	if ok {
+		return checks, nil
+	}
+	checks, err := c.getChecks(ctx, owner, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	if (PullRequest{Checks: checks}).CheckState() != "pending" {
+		c.mu.Lock()
+		if c.settledChecks == nil {
+			c.settledChecks = make(map[string]map[string][]Check)
+		}
+		if c.settledChecks[full] == nil {
+			c.settledChecks[full] = make(map[string][]Check)
+		}
+		c.settledChecks[full][sha] = checks
+		c.mu.Unlock()
+	}
+	return checks, nil
+}
+
+// pruneChecks forgets the checks of commits of repo that are no longer the
+// head of an open pull request.
+func (c *ghClient) pruneChecks(repo string, heads map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for sha := range c.settledChecks[repo] {
+		if !heads[sha] {
+			delete(c.settledChecks[repo], sha)
+		}
+	}
+}
+
+// getChecks returns the check runs and commit statuses reported for ref.
+func (c *ghClient) getChecks(ctx context.Context, owner, repo, ref string) ([]Check, error) {
+	var checks []Check
+	runs, _, err := c.gh.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, &gogithub.ListCheckRunsOptions{
+		ListOptions: gogithub.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing check runs for %s/%s@%s: %w", owner, repo, ref, err)
+	}
+	for _, r := range runs.CheckRuns {
+		state := WorkflowRun{Status: r.GetStatus(), Conclusion: r.GetConclusion()}.DisplayStatus()
+		checks = append(checks, Check{Name: r.GetName(), State: state, URL: r.GetHTMLURL()})
+	}
+
+	combined, _, err := c.gh.Repositories.GetCombinedStatus(ctx, owner, repo, ref, &gogithub.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("getting commit status for %s/%s@%s: %w", owner, repo, ref, err)
+	}
+	for _, s := range combined.Statuses {
+		checks = append(checks, Check{Name: s.GetContext(), State: s.GetState(), URL: s.GetTargetURL()})
+	}
+	return checks, nil
+}
+
+func pullRequestFromAPI(owner, repo string, pr *gogithub.PullRequest) PullRequest {
+	p := PullRequest{
+		Repo:    owner + "/" + repo,
+		Number:  pr.GetNumber(),
+		Title:   pr.GetTitle(),
+		Author:  pr.GetUser().GetLogin(),
+		Draft:   pr.GetDraft(),
+		URL:     pr.GetHTMLURL(),
+		HeadSHA: pr.GetHead().GetSHA(),
+	}
+	for _, u := range pr.RequestedReviewers {
+		p.RequestedReviewers = append(p.RequestedReviewers, u.GetLogin())
+	}
+	return p
+}
@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pullRequestMux serves two open pull requests whose heads are aaa and bbb.
+// Listing the check runs of bbb fails.
+func pullRequestMux(checkRuns map[string]int) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"number": 1, "head": {"sha": "aaa"}}, {"number": 2, "head": {"sha": "bbb"}}]`)
+	})
+	for _, n := range []string{"1", "2"} {
+		mux.HandleFunc("/repos/owner/repo/pulls/"+n, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"mergeable_state": "clean"}`)
+		})
+	}
+	mux.HandleFunc("/repos/owner/repo/commits/aaa/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		checkRuns["aaa"]++
+		fmt.Fprint(w, `{"total_count": 1, "check_runs": [{"name": "build", "status": "completed", "conclusion": "success"}]}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/aaa/status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"statuses": []}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/bbb/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		checkRuns["bbb"]++
+		http.Error(w, `{"message": "Server Error"}`, http.StatusInternalServerError)
+	})
+	return mux
+}
+
+func TestGetPullRequests_CheckErrorOnItsRow(t *testing.T) {
+	c := newTestClient(t, pullRequestMux(map[string]int{}), 0)
+
+	prs, err := c.GetPullRequests(context.Background(), "owner", "repo")
+	require.NoError(t, err)
+	require.Len(t, prs, 2)
+	assert.Nil(t, prs[0].Err)
+	assert.Equal(t, "success", prs[0].CheckState())
+	require.NotNil(t, prs[1].Err)
+	assert.Equal(t, http.StatusInternalServerError, prs[1].Err.StatusCode)
+	assert.Equal(t, "error", prs[1].CheckState())
+	assert.Equal(t, "clean", prs[1].MergeableState)
+}
+
+func TestGetPullRequests_ReusesSettledChecks(t *testing.T) {
+	checkRuns := map[string]int{}
+	c := newTestClient(t, pullRequestMux(checkRuns), 0)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetPullRequests(context.Background(), "owner", "repo")
+		require.NoError(t, err)
+	}
+	// Finished checks are fetched once per head commit; failed fetches are
+	// made again.
+	assert.Equal(t, 1, checkRuns["aaa"])
+	assert.Equal(t, 3, checkRuns["bbb"])
+}
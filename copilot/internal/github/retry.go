@@ -0,0 +1,129 @@
+package github
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults for the RetryTransport of the API clients.
+const (
+	DefaultTimeout = 15 * time.Second
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+
+	// longestWait is the most a retry waits, whatever the backoff has
+	// grown to or a Retry-After header says.
+	longestWait = 30 * time.Second
+)
+
+// RetryTransport is an http.RoundTripper that bounds each request with a
+// timeout and makes a GET or HEAD request that failed for a reason that may
+// pass again: after a network error or timeout, a server error (5xx) or 429
+// Too Many Requests. Other 4xx responses are returned as they are. Zero
+// values disable the timeout and the retries.
+type RetryTransport struct {
+	// Base makes the requests; http.DefaultTransport if nil.
+	Base http.RoundTripper
+
+	// Timeout bounds each attempt, until its response body is closed.
+	Timeout time.Duration
+
+	// Retries is how many times a failed request is made again, waiting
+	// about Backoff before the first retry and twice as long before each
+	// next one, or as long as a Retry-After header asks.
+	Retries int
+	Backoff time.Duration
+}
+
+// NewRetryTransport returns a RetryTransport over base with the default
+// timeout and retries.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return &RetryTransport{Base: base, Timeout: DefaultTimeout, Retries: DefaultRetries, Backoff: DefaultBackoff}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := t.Retries
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		// Other requests could take effect twice.
+		retries = 0
+	}
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTrip(req)
+		if attempt >= retries || ctx.Err() != nil || !t.retryable(resp, err) {
+			return resp, err
+		}
+		wait := t.delay(attempt, resp)
+		if resp != nil {
+			// Drain a little of the body so the connection can be reused.
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// roundTrip makes a single attempt, bounded by the timeout.
+func (t *RetryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Timeout <= 0 {
+		return base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.Timeout)
+	resp, err := base.RoundTrip(req.Clone(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (t *RetryTransport) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// cancelBody releases an attempt's timeout once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// delay is the wait before retrying after the given attempt (0 for the
+// first) got resp, which is nil after a network error. A Retry-After header
+// in seconds is obeyed. Otherwise the wait doubles from Backoff with each
+// attempt, and is cut to a random point in its upper half so that clients
+// that failed together spread out.
+func (t *RetryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			return min(time.Duration(secs)*time.Second, longestWait)
+		}
+	}
+	full := min(t.Backoff<<attempt, longestWait)
+	if full <= 0 {
+		return 0
+	}
+	return full - rand.N(full/2+1)
+}
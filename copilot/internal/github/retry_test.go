@@ -0,0 +1,180 @@
+package github_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v68/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ghclient "ghamon/internal/github"
+)
+
+// flakyServer plays back one handler per request, in order, then answers
+// 200 with "{}" to every request after them. hits counts the requests it got.
+type flakyServer struct {
+	*httptest.Server
+	hits atomic.Int32
+}
+
+func newFlakyServer(t *testing.T, script ...http.HandlerFunc) *flakyServer {
+	t.Helper()
+	s := &flakyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := int(s.hits.Add(1)); n <= len(script) {
+			script[n-1](w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "{}")
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// respond answers with an empty body and the given status.
+func respond(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(code) }
+}
+
+// dropConnection takes over the connection and closes it, so the client
+// sees a network error.
+func dropConnection(w http.ResponseWriter, _ *http.Request) {
+	if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// stall never answers; it returns when the client gives up.
+func stall(_ http.ResponseWriter, r *http.Request) {
+	<-r.Context().Done()
+}
+
+func retryTransport(server *flakyServer) *ghclient.RetryTransport {
+	return &ghclient.RetryTransport{
+		Base:    server.Client().Transport,
+		Timeout: time.Second,
+		Retries: 3,
+		Backoff: time.Millisecond,
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		script []http.HandlerFunc
+		status int
+		hits   int32
+	}{
+		{"server errors", http.MethodGet, []http.HandlerFunc{respond(500), respond(502)}, 200, 3},
+		{"too many requests", http.MethodGet, []http.HandlerFunc{respond(429)}, 200, 2},
+		{"gives up", http.MethodGet, []http.HandlerFunc{respond(503), respond(503), respond(503), respond(503)}, 503, 4},
+		{"not found", http.MethodGet, []http.HandlerFunc{respond(404)}, 404, 1},
+		{"unauthorized", http.MethodGet, []http.HandlerFunc{respond(401)}, 401, 1},
+		{"rate limited", http.MethodGet, []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		}}, 403, 1},
+		{"not idempotent", http.MethodPut, []http.HandlerFunc{respond(502)}, 502, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFlakyServer(t, tt.script...)
+			client := &http.Client{Transport: retryTransport(server)}
+			req, err := http.NewRequest(tt.method, server.URL, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.hits, server.hits.Load())
+		})
+	}
+}
+
+func TestRetryTransport_NetworkError(t *testing.T) {
+	server := newFlakyServer(t, dropConnection, dropConnection)
+	resp, err := (&http.Client{Transport: retryTransport(server)}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), server.hits.Load())
+}
+
+func TestRetryTransport_Timeout(t *testing.T) {
+	server := newFlakyServer(t, stall)
+	rt := retryTransport(server)
+	rt.Timeout = 50 * time.Millisecond
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	require.NoError(t, err, "a request that hangs is made again")
+	resp.Body.Close()
+	assert.Equal(t, int32(2), server.hits.Load())
+
+	server = newFlakyServer(t, stall, stall, stall, stall)
+	rt = retryTransport(server)
+	rt.Timeout = 20 * time.Millisecond
+	_, err = (&http.Client{Transport: rt}).Get(server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(4), server.hits.Load())
+}
+
+func TestRetryTransport_Cancel(t *testing.T) {
+	server := newFlakyServer(t, stall, stall)
+	rt := retryTransport(server)
+	rt.Timeout = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = (&http.Client{Transport: rt}).Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second, "cancelling ends the request")
+	assert.Equal(t, int32(1), server.hits.Load(), "a cancelled request is not retried")
+
+	// A cancel during the backoff returns at once, without another attempt.
+	server = newFlakyServer(t, respond(500))
+	rt = retryTransport(server)
+	rt.Backoff = time.Minute
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	start = time.Now()
+	_, err = (&http.Client{Transport: rt}).Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryTransport_RetryAfter(t *testing.T) {
+	server := newFlakyServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	start := time.Now()
+	resp, err := (&http.Client{Transport: retryTransport(server)}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(2), server.hits.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "Retry-After is honoured")
+}
+
+func TestRetryTransport_FetchError(t *testing.T) {
+	server := newFlakyServer(t, respond(502), respond(502), respond(502), respond(502))
+	gh := gogithub.NewClient(&http.Client{Transport: retryTransport(server)})
+	gh.BaseURL, _ = gh.BaseURL.Parse(server.URL + "/")
+	_, _, err := gh.Repositories.Get(context.Background(), "owner", "repo")
+	fe := ghclient.NewFetchError(err)
+	assert.Equal(t, 502, fe.StatusCode)
+	assert.True(t, fe.Retryable)
+	assert.Equal(t, int32(4), server.hits.Load())
+	assert.True(t, strings.HasPrefix(fe.Error(), "502"))
+}
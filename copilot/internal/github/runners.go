@@ -0,0 +1,144 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// selfHostedLabel is the label every self-hosted runner carries. Jobs without
+// it run on GitHub-hosted runners, which the runners API does not list.
+const selfHostedLabel = "self-hosted"
+
+// Runner is a self-hosted runner registered with a repository or organization.
+type Runner struct {
+	// Scope is the repository ("owner/repo") or organization the runner is
+	// registered with.
+	Scope  string
+	Name   string
+	OS     string
+	Status string // "online" or "offline"
+	Busy   bool
+	Labels []string
+}
+
+// State returns "busy", "online" or "offline".
+func (r Runner) State() string {
+	if r.Status == "online" && r.Busy {
+		return "busy"
+	}
+	return r.Status
+}
+
+// CanRun reports whether the runner is online and carries every label in
+// labels, ignoring case.
+func (r Runner) CanRun(labels []string) bool {
+	if r.Status != "online" {
+		return false
+	}
+	for _, l := range labels {
+		if !slices.ContainsFunc(r.Labels, func(rl string) bool { return strings.EqualFold(rl, l) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueuedJob is a job of a workflow run that is waiting for a runner.
+type QueuedJob struct {
+	Name   string
+	Labels []string // the job's runs-on labels
+}
+
+// NeedsSelfHosted reports whether the job waits for a self-hosted runner.
+func (j QueuedJob) NeedsSelfHosted() bool {
+	return slices.ContainsFunc(j.Labels, func(l string) bool { return strings.EqualFold(l, selfHostedLabel) })
+}
+
+// UnservedJobs returns the jobs that need a self-hosted runner but that none
+// of runners can currently run.
+func UnservedJobs(jobs []QueuedJob, runners []Runner) []QueuedJob {
+	var unserved []QueuedJob
+	for _, j := range jobs {
+		if !j.NeedsSelfHosted() {
+			continue
+		}
+		if !slices.ContainsFunc(runners, func(r Runner) bool { return r.CanRun(j.Labels) }) {
+			unserved = append(unserved, j)
+		}
+	}
+	return unserved
+}
+
+// RunnerClient is the interface for fetching self-hosted runner data from
+// GitHub.
+type RunnerClient interface {
+	// GetRunners fetches the runners available to a repository: its own and,
+	// if the token may list them, its organization's.
+	GetRunners(ctx context.Context, owner, repo string) ([]Runner, error)
+	// GetQueuedJobs fetches the queued jobs of a workflow run.
+	GetQueuedJobs(ctx context.Context, owner, repo string, runID int64) ([]QueuedJob, error)
+}
+
+// NewRunnerClient creates a runner client authenticated with the provided
+// token. Listing runners needs admin access to the repository.
+func NewRunnerClient(token string) RunnerClient {
+	return newClient(token, 0)
+}
+
+// GetRunners fetches the repository's runners and its organization's. Errors
+// listing organization runners are ignored, since the owner may be a user or
+// the token may lack the organization scope.
+func (c *ghClient) GetRunners(ctx context.Context, owner, repo string) ([]Runner, error) {
+	opts := &gogithub.ListRunnersOptions{ListOptions: gogithub.ListOptions{PerPage: 100}}
+	repoRunners, _, err := c.gh.Actions.ListRunners(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing runners for %s/%s: %w", owner, repo, err)
+	}
+	runners := runnersFromAPI(owner+"/"+repo, repoRunners)
+	if orgRunners, _, err := c.gh.Actions.ListOrganizationRunners(ctx, owner, opts); err == nil {
+		runners = append(runners, runnersFromAPI(owner, orgRunners)...)
+	}
+	return runners, nil
+}
+
+// GetQueuedJobs fetches the jobs of run runID that are still queued.
+func (c *ghClient) GetQueuedJobs(ctx context.Context, owner, repo string, runID int64) ([]QueuedJob, error) {
+	jobs, _, err := c.gh.Actions.ListWorkflowJobs(ctx, owner, repo, runID, &gogithub.ListWorkflowJobsOptions{
+		ListOptions: gogithub.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing jobs of run %d in %s/%s: %w", runID, owner, repo, err)
+	}
+	var queued []QueuedJob
+	for _, j := range jobs.Jobs {
+		if j.GetStatus() == "queued" {
+			queued = append(queued, QueuedJob{Name: j.GetName(), Labels: j.Labels})
+		}
+	}
+	return queued, nil
+}
+
+func runnersFromAPI(scope string, rs *gogithub.Runners) []Runner {
+	if rs == nil {
+		return nil
+	}
+	runners := make([]Runner, 0, len(rs.Runners))
+	for _, r := range rs.Runners {
+		runner := Runner{
+			Scope:  scope,
+			Name:   r.GetName(),
+			OS:     r.GetOS(),
+			Status: r.GetStatus(),
+			Busy:   r.GetBusy(),
+		}
+		for _, l := range r.Labels {
+			runner.Labels = append(runner.Labels, l.GetName())
+		}
+		runners = append(runners, runner)
+	}
+	return runners
+}
@@ -0,0 +1,24 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// newTestClient returns a client whose API requests are served by mux.
+func newTestClient(t *testing.T, mux *http.ServeMux, history int) *ghClient {
+	t.Helper()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	base, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gh := gogithub.NewClient(nil)
+	gh.BaseURL = base
+	return &ghClient{gh: gh, history: history, download: srv.Client()}
+}
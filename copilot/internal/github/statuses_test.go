@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultBranch_ErrorRetried(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, `{"message": "Server Error"}`, http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"full_name": "owner/repo", "default_branch": "trunk"}`)
+	})
+	c := newTestClient(t, mux, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := c.defaultBranch(ctx, "owner", "repo")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, calls, "an error is kept for a while")
+
+	b := c.defaultBranches["owner/repo"]
+	b.fetched = b.fetched.Add(-defaultBranchRetry)
+	c.defaultBranches["owner/repo"] = b
+	for i := 0; i < 2; i++ {
+		branch, err := c.defaultBranch(ctx, "owner", "repo")
+		require.NoError(t, err)
+		assert.Equal(t, "trunk", branch)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestGetAll_WorkflowError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/actions/workflows", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 2, "workflows": [
+			{"id": 1, "name": "CI", "path": ".github/workflows/ci.yml", "state": "active"},
+			{"id": 2, "name": "Deploy", "path": ".github/workflows/deploy.yml", "state": "active"}]}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/actions/workflows/ci.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 1, "workflow_runs": [{"id": 10, "status": "completed", "conclusion": "success"}]}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/actions/workflows/deploy.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Bad Gateway"}`, http.StatusBadGateway)
+	})
+	c := newTestClient(t, mux, 0)
+
+	runs, err := c.getAll(context.Background(), "owner", "repo")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "success", runs[0].Conclusion)
+	assert.Nil(t, runs[0].Err)
+
+	assert.Equal(t, "Deploy", runs[1].Workflow)
+	assert.Equal(t, "error", runs[1].Status)
+	require.NotNil(t, runs[1].Err)
+	assert.Equal(t, http.StatusBadGateway, runs[1].Err.StatusCode)
+	assert.Equal(t, "Bad Gateway", runs[1].Err.Message)
+	assert.True(t, runs[1].Err.Retryable)
+}
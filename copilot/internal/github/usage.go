@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	gogithub "github.com/google/go-github/v68/github"
+)
+
+// maxListedCaches is how many of a repository's largest caches are listed.
+const maxListedCaches = 20
+
+// Cache is an Actions cache entry of a repository.
+type Cache struct {
+	ID             int64
+	Key            string
+	Ref            string
+	SizeInBytes    int64
+	LastAccessedAt time.Time
+}
+
+// CacheUsage is the Actions cache usage of a repository.
+type CacheUsage struct {
+	Repo        string
+	Count       int
+	SizeInBytes int64
+	Largest     []Cache // largest first
+}
+
+// WorkflowMinutes is the billable time of a workflow in the current billing
+// cycle, by runner OS (e.g. "UBUNTU", "MACOS", "WINDOWS").
+type WorkflowMinutes struct {
+	Repo     string
+	Workflow string
+	ByOS     map[string]time.Duration
+}
+
+// Total returns the billable time across all runner OSes.
+func (w WorkflowMinutes) Total() time.Duration {
+	var total time.Duration
+	for _, d := range w.ByOS {
+		total += d
+	}
+	return total
+}
+
+// UsageClient is the interface for fetching and managing Actions cache and
+// billing usage.
+type UsageClient interface {
+	// GetCacheUsage fetches the cache usage of a repository.
+	GetCacheUsage(ctx context.Context, owner, repo string) (CacheUsage, error)
+	// GetBillableMinutes fetches the billable time of each workflow of a
+	// repository in the current billing cycle.
+	GetBillableMinutes(ctx context.Context, owner, repo string) ([]WorkflowMinutes, error)
+	// DeleteCache deletes a cache entry of a repository.
+	DeleteCache(ctx context.Context, owner, repo string, id int64) error
+}
+
+// NewUsageClient creates a usage client authenticated with the provided token.
+func NewUsageClient(token string) UsageClient {
+	return newClient(token, 0)
+}
+
+// GetCacheUsage fetches the totals of owner/repo's caches and its largest
+// entries.
+func (c *ghClient) GetCacheUsage(ctx context.Context, owner, repo string) (CacheUsage, error) {
+	usage, _, err := c.gh.Actions.GetCacheUsageForRepo(ctx, owner, repo)
+	if err != nil {
+		return CacheUsage{}, fmt.Errorf("getting cache usage for %s/%s: %w", owner, repo, err)
+	}
+	u := CacheUsage{Repo: owner + "/" + repo, Count: usage.ActiveCachesCount, SizeInBytes: usage.ActiveCachesSizeInBytes}
+	if u.Count == 0 {
+		return u, nil
+	}
+
+	list, _, err := c.gh.Actions.ListCaches(ctx, owner, repo, &gogithub.ActionsCacheListOptions{
+		ListOptions: gogithub.ListOptions{PerPage: maxListedCaches},
+		Sort:        gogithub.Ptr("size_in_bytes"),
+		Direction:   gogithub.Ptr("desc"),
+	})
+	if err != nil {
+		return CacheUsage{}, fmt.Errorf("listing caches for %s/%s: %w", owner, repo, err)
+	}
+	for _, ac := range list.ActionsCaches {
+		u.Largest = append(u.Largest, Cache{
+			ID:             ac.GetID(),
+			Key:            ac.GetKey(),
+			Ref:            ac.GetRef(),
+			SizeInBytes:    ac.GetSizeInBytes(),
+			LastAccessedAt: ac.GetLastAccessedAt().Time,
+		})
+	}
+	return u, nil
+}
+
+// GetBillableMinutes fetches the billable time of each workflow of owner/repo.
+// Workflows without billable time, e.g. in public repositories, are left out.
+// It costs one request per workflow.
+func (c *ghClient) GetBillableMinutes(ctx context.Context, owner, repo string) ([]WorkflowMinutes, error) {
+	workflows, _, err := c.gh.Actions.ListWorkflows(ctx, owner, repo, &gogithub.ListOptions{PerPage: maxPerPage})
+	if err != nil {
+		return nil, fmt.Errorf("listing workflows for %s/%s: %w", owner, repo, err)
+	}
+	var minutes []WorkflowMinutes
+	for _, wf := range workflows.Workflows {
+		usage, _, err := c.gh.Actions.GetWorkflowUsageByID(ctx, owner, repo, wf.GetID())
+		if err != nil {
+			return nil, fmt.Errorf("getting usage of workflow %s in %s/%s: %w", wf.GetName(), owner, repo, err)
+		}
+		w := WorkflowMinutes{Repo: owner + "/" + repo, Workflow: wf.GetName(), ByOS: make(map[string]time.Duration)}
+		if billable := usage.GetBillable(); billable != nil {
+			for runnerOS, bill := range *billable {
+				if ms := bill.GetTotalMS(); ms > 0 {
+					w.ByOS[runnerOS] = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+		if len(w.ByOS) > 0 {
+			minutes = append(minutes, w)
+		}
+	}
+	sort.Slice(minutes, func(i, j int) bool { return minutes[i].Total() > minutes[j].Total() })
+	return minutes, nil
+}
+
+// DeleteCache deletes cache entry id of owner/repo.
+func (c *ghClient) DeleteCache(ctx context.Context, owner, repo string, id int64) error {
+	if _, err := c.gh.Actions.DeleteCachesByID(ctx, owner, repo, id); err != nil {
+		return fmt.Errorf("deleting cache %d in %s/%s: %w", id, owner, repo, err)
+	}
+	return nil
+}
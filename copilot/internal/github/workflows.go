@@ -0,0 +1,136 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+)
+
+// Workflow states reported by the API.
+const (
+	StateActive             = "active"
+	StateDisabledManually   = "disabled_manually"
+	StateDisabledInactivity = "disabled_inactivity"
+)
+
+// workflowTTL is how long the state of a workflow watched with --workflow is
+// kept before it is fetched again. States change rarely, and this client's
+// own changes show at once.
+const workflowTTL = 5 * time.Minute
+
+// cachedWorkflow is a workflow looked up by file name. A failed lookup is
+// kept with its err like a result, so it is not repeated every refresh
+// either.
+type cachedWorkflow struct {
+	id      int64
+	state   string
+	err     error
+	fetched time.Time
+}
+
+// Disabled reports whether the run's workflow is disabled, manually or by
+// GitHub after a period without repository activity.
+func (w WorkflowRun) Disabled() bool {
+	return w.WorkflowState == StateDisabledManually || w.WorkflowState == StateDisabledInactivity
+}
+
+// WorkflowClient is the interface for managing workflows.
+type WorkflowClient interface {
+	// SetWorkflowEnabled enables or disables a workflow.
+	SetWorkflowEnabled(ctx context.Context, owner, repo string, workflowID int64, enabled bool) error
+}
+
+// NewWorkflowClient creates a workflow client authenticated with the provided
+// token. Enabling and disabling workflows needs write access to Actions.
+func NewWorkflowClient(token string) WorkflowClient {
+	return newClient(token, 0)
+}
+
+// SetWorkflowEnabled enables or disables workflow workflowID of owner/repo.
+func (c *ghClient) SetWorkflowEnabled(ctx context.Context, owner, repo string, workflowID int64, enabled bool) error {
+	var err error
+	if enabled {
+		_, err = c.gh.Actions.EnableWorkflowByID(ctx, owner, repo, workflowID)
+	} else {
+		_, err = c.gh.Actions.DisableWorkflowByID(ctx, owner, repo, workflowID)
+	}
+	if err != nil {
+		verb := "disabling"
+		if enabled {
+			verb = "enabling"
+		}
+		return fmt.Errorf("%s workflow %d in %s/%s: %w", verb, workflowID, owner, repo, err)
+	}
+	// The cached state is stale now; the next refresh fetches it again.
+	c.mu.Lock()
+	for key, wf := range c.workflowsByFile {
+		if wf.id == workflowID {
+			delete(c.workflowsByFile, key)
+		}
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// RunWorkflowClient is the interface for finding the workflow of a run.
+type RunWorkflowClient interface {
+	// WorkflowFile returns the file name (e.g. "ci.yml") of the workflow
+	// run runID belongs to.
+	WorkflowFile(ctx context.Context, owner, repo string, runID int64) (string, error)
+}
+
+// NewRunWorkflowClient creates a client that finds the workflow of a run,
+// authenticated with the provided token.
+func NewRunWorkflowClient(token string) RunWorkflowClient {
+	return newClient(token, 0)
+}
+
+// WorkflowFile returns the file name of run runID's workflow. A run's
+// workflow never changes, so it is fetched once per client.
+func (c *ghClient) WorkflowFile(ctx context.Context, owner, repo string, runID int64) (string, error) {
+	c.mu.Lock()
+	file, ok := c.runWorkflowFiles[runID]
+	c.mu.Unlock()
+	if ok {
+		return file, nil
+	}
+	run, _, err := c.gh.Actions.GetWorkflowRunByID(ctx, owner, repo, runID)
+	if err != nil {
+		return "", fmt.Errorf("getting run %d of %s/%s: %w", runID, owner, repo, err)
+	}
+	file = path.Base(run.GetPath())
+	c.mu.Lock()
+	if c.runWorkflowFiles == nil {
+		c.runWorkflowFiles = make(map[int64]string)
+	}
+	c.runWorkflowFiles[runID] = file
+	c.mu.Unlock()
+	return file, nil
+}
+
+// workflowByFile returns the ID and state of workflow file of owner/repo,
+// fetched at most once per workflowTTL.
+func (c *ghClient) workflowByFile(ctx context.Context, owner, repo, file string) (cachedWorkflow, error) {
+	key := owner + "/" + repo + "/" + file
+	c.mu.Lock()
+	wf, ok := c.workflowsByFile[key]
+	c.mu.Unlock()
+	if ok && time.Since(wf.fetched) < workflowTTL {
+		return wf, wf.err
+	}
+	wf = cachedWorkflow{fetched: time.Now()}
+	got, _, err := c.gh.Actions.GetWorkflowByFileName(ctx, owner, repo, file)
+	if err != nil {
+		wf.err = fmt.Errorf("getting workflow %s of %s/%s: %w", file, owner, repo, err)
+	} else {
+		wf.id, wf.state = got.GetID(), got.GetState()
+	}
+	c.mu.Lock()
+	if c.workflowsByFile == nil {
+		c.workflowsByFile = make(map[string]cachedWorkflow)
+	}
+	c.workflowsByFile[key] = wf
+	c.mu.Unlock()
+	return wf, wf.err
+}
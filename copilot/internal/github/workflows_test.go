@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowFile_Cached(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/actions/runs/42", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"id": 42, "path": ".github/workflows/deploy.yml"}`)
+	})
+	c := newTestClient(t, mux, 0)
+
+	for i := 0; i < 2; i++ {
+		file, err := c.WorkflowFile(context.Background(), "owner", "repo", 42)
+		require.NoError(t, err)
+		assert.Equal(t, "deploy.yml", file)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestWorkflowByFile_Cached(t *testing.T) {
+	lookups := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/actions/workflows/ci.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 0, "workflow_runs": []}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/actions/workflows/ci.yml", func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		fmt.Fprint(w, `{"id": 7, "state": "active"}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/actions/workflows/7/disable", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c := newTestClient(t, mux, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		runs, err := c.getByFile(ctx, "owner", "repo", "ci.yml")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), runs[0].WorkflowID)
+		assert.Equal(t, StateActive, runs[0].WorkflowState)
+	}
+	assert.Equal(t, 1, lookups)
+
+	// Disabling the workflow drops its cached state.
+	require.NoError(t, c.SetWorkflowEnabled(ctx, "owner", "repo", 7, false))
+	_, err := c.getByFile(ctx, "owner", "repo", "ci.yml")
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+
+	// So does time.
+	wf := c.workflowsByFile["owner/repo/ci.yml"]
+	wf.fetched = wf.fetched.Add(-workflowTTL)
+	c.workflowsByFile["owner/repo/ci.yml"] = wf
+	_, err = c.getByFile(ctx, "owner", "repo", "ci.yml")
+	require.NoError(t, err)
+	assert.Equal(t, 3, lookups)
+}
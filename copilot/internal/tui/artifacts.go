@@ -0,0 +1,410 @@
+package tui
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	ghclient "ghamon/internal/github"
+)
+
+// maxExtractedSize caps the bytes written when unzipping an artifact, so that
+// a small archive cannot fill the disk.
+const maxExtractedSize = 4 << 30
+
+type artifactsMsg struct {
+	runID     int64
+	artifacts []ghclient.Artifact
+	err       error
+}
+
+// downloadProgressMsg reports how many bytes of the artifact being downloaded
+// have arrived. total is 0 if unknown.
+type downloadProgressMsg struct {
+	done, total int64
+}
+
+// downloadDoneMsg reports where a finished download was saved.
+type downloadDoneMsg struct {
+	name string
+	path string
+	err  error
+}
+
+// download is an artifact download in flight. Its goroutine reports progress
+// and completion on ch.
+type download struct {
+	name string
+	size int64
+	ch   chan tea.Msg
+}
+
+// WithArtifacts returns a copy of m that can list and download the artifacts
+// of the selected run, using client.
+func (m Model) WithArtifacts(client ghclient.ArtifactClient) Model {
+	m.artifactClient = client
+	return m
+}
+
+func (m Model) doFetchArtifacts() tea.Cmd {
+	run := m.artifactRun
+	client := m.artifactClient
+	ctx := m.ctx
+
+	return func() tea.Msg {
+		parts := strings.SplitN(run.Repo, "/", 2)
+		artifacts, err := client.ListArtifacts(ctx, parts[0], parts[1], run.ID)
+		return artifactsMsg{runID: run.ID, artifacts: artifacts, err: err}
+	}
+}
+
+// openArtifacts switches to the artifacts of the selected run.
+func (m *Model) openArtifacts() tea.Cmd {
+	if m.artifactClient == nil {
+		m.notice = "the artifact browser needs GITHUB_TOKEN"
+		return nil
+	}
+	rows := m.visibleRows()
+	if m.cursor >= len(rows) || rows[m.cursor].header || rows[m.cursor].run.ID == 0 {
+		m.notice = "select a workflow run to list its artifacts"
+		return nil
+	}
+	m.view = viewArtifacts
+	m.artifactRun = rows[m.cursor].run
+	m.artifacts = nil
+	m.artifactsErr = nil
+	m.artifactCursor = 0
+	return m.doFetchArtifacts()
+}
+
+// updateArtifacts handles key presses in the artifact list.
+func (m Model) updateArtifacts(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.refresh):
+		cmd = m.doFetchArtifacts()
+	case key.Matches(msg, k.artifacts, k.back):
+		m.view = viewWorkflows
+	case key.Matches(msg, k.up):
+		m.setArtifactCursor(m.artifactCursor - 1)
+	case key.Matches(msg, k.down):
+		m.setArtifactCursor(m.artifactCursor + 1)
+	case key.Matches(msg, k.home):
+		m.setArtifactCursor(0)
+	case key.Matches(msg, k.end):
+		m.setArtifactCursor(len(m.artifacts) - 1)
+	case key.Matches(msg, k.unzip):
+		m.unzip = !m.unzip
+	case key.Matches(msg, k.selectRow):
+		switch {
+		case m.artifactCursor >= len(m.artifacts):
+		case m.download != nil:
+			m.notice = "wait for " + m.download.name + " to finish downloading"
+		case m.artifacts[m.artifactCursor].Expired:
+			m.notice = m.artifacts[m.artifactCursor].Name + " has expired"
+		default:
+			m.promptingDir = true
+			if m.downloadDir == "" {
+				m.downloadDir = "."
+			}
+		}
+	}
+	m.render()
+	return m, cmd
+}
+
+// updateDirPrompt handles key presses while asking where to save an artifact.
+func (m Model) updateDirPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.promptingDir = false
+		cmd = m.startDownload(m.artifacts[m.artifactCursor])
+	case tea.KeyEsc:
+		m.promptingDir = false
+	case tea.KeyCtrlU:
+		m.downloadDir = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.downloadDir); len(r) > 0 {
+			m.downloadDir = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.downloadDir += " "
+	case tea.KeyRunes:
+		m.downloadDir += string(msg.Runes)
+	}
+	return m, cmd
+}
+
+func (m *Model) setArtifactCursor(i int) {
+	m.artifactCursor = max(0, min(i, len(m.artifacts)-1))
+}
+
+// startDownload saves a to the chosen directory in the background, showing
+// its progress in the header's progress bar.
+func (m *Model) startDownload(a ghclient.Artifact) tea.Cmd {
+	m.resetProgress()
+	d := &download{name: a.Name, size: a.SizeInBytes, ch: make(chan tea.Msg, 1)}
+	m.download = d
+
+	client := m.artifactClient
+	ctx := m.ctx
+	parts := strings.SplitN(m.artifactRun.Repo, "/", 2)
+	dir := expandHome(m.downloadDir)
+	unzip := m.unzip
+	go func() {
+		path, err := saveArtifact(ctx, client, parts[0], parts[1], a, dir, unzip, func(done, total int64) {
+			// Progress is only a hint; drop it rather than stall the download.
+			select {
+			case d.ch <- downloadProgressMsg{done: done, total: total}:
+			default:
+			}
+		})
+		d.ch <- downloadDoneMsg{name: a.Name, path: path, err: err}
+	}()
+	return waitDownload(d.ch)
+}
+
+// waitDownload delivers the next message from a download.
+func waitDownload(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-ch }
+}
+
+// updateDownload handles the progress and completion of the download in
+// flight.
+func (m *Model) updateDownload(msg tea.Msg) tea.Cmd {
+	if m.download == nil {
+		return nil
+	}
+	switch msg := msg.(type) {
+	case downloadProgressMsg:
+		total := msg.total
+		if total == 0 {
+			total = m.download.size
+		}
+		cmd := waitDownload(m.download.ch)
+		if total > 0 {
+			cmd = tea.Batch(cmd, m.prog.SetPercent(min(1, float64(msg.done)/float64(total))))
+		}
+		return cmd
+	case downloadDoneMsg:
+		m.download = nil
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("downloading %s failed: %v", msg.name, msg.err)
+			return nil
+		}
+		m.notice = fmt.Sprintf("saved %s to %s", msg.name, msg.path)
+		return m.prog.SetPercent(1.0)
+	}
+	return nil
+}
+
+// saveArtifact downloads a into dir as <name>.zip or, if unzip is set,
+// extracts it into dir/<name>. It returns the path of the file or directory
+// written.
+func saveArtifact(ctx context.Context, client ghclient.ArtifactClient, owner, repo string, a ghclient.Artifact,
+	dir string, unzip bool, progress func(done, total int64)) (string, error) {
+	name, err := artifactFileName(a.Name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	zipPath := filepath.Join(dir, name+".zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return "", err
+	}
+	err = client.DownloadArtifact(ctx, owner, repo, a.ID, f, progress)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(zipPath)
+		return "", err
+	}
+	if !unzip {
+		return zipPath, nil
+	}
+
+	dest := filepath.Join(dir, name)
+	if err := extractZip(zipPath, dest); err != nil {
+		return "", fmt.Errorf("unzipping %s: %w", zipPath, err)
+	}
+	os.Remove(zipPath)
+	return dest, nil
+}
+
+// artifactFileName returns the name an artifact is saved under, refusing
+// names that are not a single path element, such as ".." or "a/b".
+func artifactFileName(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || name != filepath.Clean(name) {
+		return "", fmt.Errorf("artifact name %q is not a valid file name", name)
+	}
+	return name, nil
+}
+
+// extractZip extracts the archive at src into the directory dest, refusing
+// entries that would land outside it and stopping once maxExtractedSize
+// bytes have been written.
+func extractZip(src, dest string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	remaining := int64(maxExtractedSize)
+	for _, zf := range zr.File {
+		path := filepath.Join(dest, zf.Name)
+		if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("%s: path escapes the destination", zf.Name)
+		}
+		if zf.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		n, err := extractFile(zf, path, remaining)
+		if err != nil {
+			return err
+		}
+		remaining -= n
+	}
+	return nil
+}
+
+// extractFile writes zf to path, failing if it is larger than limit bytes.
+// It returns the number of bytes written.
+func extractFile(zf *zip.File, path string, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+	r, err := zf.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	// Reading one byte past the limit tells a file that fits exactly from
+	// one that does not.
+	n, err := io.Copy(w, io.LimitReader(r, limit+1))
+	if err == nil && n > limit {
+		err = fmt.Errorf("%s: archive expands to more than %s", zf.Name, formatSize(maxExtractedSize))
+	}
+	if err != nil {
+		w.Close()
+		return n, err
+	}
+	return n, w.Close()
+}
+
+// expandHome replaces a leading "~" in path with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// formatSize renders a byte count, e.g. "512 B", "1.5 MB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// formatExpiry renders when an artifact expires, e.g. "in 12d".
+func formatExpiry(a ghclient.Artifact, now time.Time) string {
+	if a.Expired || (!a.ExpiresAt.IsZero() && !a.ExpiresAt.After(now)) {
+		return "expired"
+	}
+	if a.ExpiresAt.IsZero() {
+		return "-"
+	}
+	d := a.ExpiresAt.Sub(now)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("in %dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("in %dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("in %dd", int(d.Hours()/24))
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (m Model) artifactContent() string {
+	if m.artifacts == nil {
+		if m.artifactsErr != nil {
+			return fmt.Sprintf("  Error: %v\n", m.artifactsErr)
+		}
+		return "  Fetching artifacts…\n"
+	}
+
+	nameW, sizeW := 40, 10
+	for _, a := range m.artifacts {
+		nameW = max(nameW, len(a.Name)+2)
+	}
+
+	var sb strings.Builder
+	hdr := fmt.Sprintf("  %-*s  %-*s  %s", nameW, "NAME", sizeW, "SIZE", "EXPIRES")
+	sb.WriteString(colHeaderStyle.Render(hdr))
+	sb.WriteByte('\n')
+	if len(m.artifacts) == 0 {
+		sb.WriteString("  No artifacts.\n")
+	}
+
+	now := time.Now()
+	for i, a := range m.artifacts {
+		rowStyle := lipgloss.NewStyle()
+		if a.Expired {
+			rowStyle = removedRowStyle
+		}
+		if i == m.artifactCursor {
+			rowStyle = selectedRowStyle
+		}
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %-*s  %s", nameW, a.Name, sizeW, formatSize(a.SizeInBytes),
+			formatExpiry(a, now))))
+		sb.WriteByte('\n')
+	}
+	if m.artifactsErr != nil {
+		sb.WriteString(fmt.Sprintf("\n  Error: %v\n", m.artifactsErr))
+	}
+	return sb.String()
+}
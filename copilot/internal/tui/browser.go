@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// noticeMsg is a one-line message shown in the footer until the next key press.
+type noticeMsg string
+
+// openURL opens url in the user's web browser.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// openInBrowser returns a command opening url, reporting failures as a notice.
+func openInBrowser(url string) tea.Cmd {
+	if url == "" {
+		return func() tea.Msg { return noticeMsg("nothing to open") }
+	}
+	return func() tea.Msg {
+		if err := openURL(url); err != nil {
+			return noticeMsg(fmt.Sprintf("could not open browser: %v", err))
+		}
+		return nil
+	}
+}
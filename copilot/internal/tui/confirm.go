@@ -0,0 +1,23 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmation is a question awaiting a yes or no in the footer before an
+// action that changes something on GitHub.
+type confirmation struct {
+	prompt string
+	action tea.Cmd // run on yes
+}
+
+// updateConfirm handles the answer to the pending confirmation: y runs its
+// action, any other key drops it.
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.confirm
+	m.confirm = nil
+	if msg.String() == "y" || msg.String() == "Y" {
+		return m, c.action
+	}
+	return m, nil
+}
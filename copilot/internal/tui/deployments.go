@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	ghclient "ghamon/internal/github"
+)
+
+type deploymentsMsg struct {
+	deployments []ghclient.Deployment
+	err         error
+}
+
+// WithDeployments returns a copy of m that can switch to the deployment view,
+// fetching from client.
+func (m Model) WithDeployments(client ghclient.DeploymentClient) Model {
+	m.deployClient = client
+	return m
+}
+
+func (m Model) doFetchDeployments() tea.Cmd {
+	repos := m.repos
+	client := m.deployClient
+	ctx := m.ctx
+
+	return func() tea.Msg {
+		var all []ghclient.Deployment
+		var errs []error
+		for _, full := range repos {
+			parts := strings.SplitN(full, "/", 2)
+			deps, err := client.GetDeployments(ctx, parts[0], parts[1])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			all = append(all, deps...)
+		}
+		return deploymentsMsg{deployments: all, err: errors.Join(errs...)}
+	}
+}
+
+// toggleDeployments switches between the workflow view and the deployment
+// view.
+func (m *Model) toggleDeployments() tea.Cmd {
+	if m.view == viewDeployments {
+		m.view = viewWorkflows
+		return nil
+	}
+	if m.deployClient == nil {
+		m.notice = "the deployment view needs GITHUB_TOKEN"
+		return nil
+	}
+	m.view = viewDeployments
+	if m.deployments == nil && !m.deploymentsLoading {
+		return m.refreshDeployments()
+	}
+	return nil
+}
+
+func (m *Model) refreshDeployments() tea.Cmd {
+	m.deploymentsLoading = true
+	m.resetProgress()
+	return m.doFetchDeployments()
+}
+
+// updateDeployments handles key presses in the deployment view.
+func (m Model) updateDeployments(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.refresh):
+		cmd = m.refreshDeployments()
+	case key.Matches(msg, k.deployments, k.back):
+		cmd = m.toggleDeployments()
+	case key.Matches(msg, k.up):
+		m.setDeployCursor(m.deployCursor - 1)
+	case key.Matches(msg, k.down):
+		m.setDeployCursor(m.deployCursor + 1)
+	case key.Matches(msg, k.pageUp):
+		m.setDeployCursor(m.deployCursor - m.vp.Height)
+	case key.Matches(msg, k.pageDown):
+		m.setDeployCursor(m.deployCursor + m.vp.Height)
+	case key.Matches(msg, k.home):
+		m.setDeployCursor(0)
+	case key.Matches(msg, k.end):
+		m.setDeployCursor(len(m.deployments) - 1)
+	case key.Matches(msg, k.browser):
+		cmd = openInBrowser(m.selectedURL())
+	}
+	m.render()
+	return m, cmd
+}
+
+func (m *Model) setDeployCursor(i int) {
+	m.deployCursor = max(0, min(i, len(m.deployments)-1))
+}
+
+func (m Model) deploymentContent() string {
+	if m.deployments == nil {
+		if m.deploymentsErr != nil {
+			return fmt.Sprintf("  Error: %v\n", m.deploymentsErr)
+		}
+		return "  Fetching deployments…\n"
+	}
+
+	repoW, envW, stateW, refW, creatorW := 24, 16, 16, 20, 14
+	for _, d := range m.deployments {
+		repoW = max(repoW, len(d.Repo)+2)
+		envW = max(envW, len(d.Environment)+2)
+	}
+
+	var sb strings.Builder
+	hdr := fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s  %-7s  %-*s  %s", repoW, "REPOSITORY", envW, "ENVIRONMENT",
+		stateW, "STATUS", refW, "REF", "SHA", creatorW, "CREATOR", "AGE")
+	sb.WriteString(colHeaderStyle.Render(hdr))
+	sb.WriteByte('\n')
+	if len(m.deployments) == 0 {
+		sb.WriteString("  No environments.\n")
+	}
+
+	now := time.Now()
+	for i, d := range m.deployments {
+		rowStyle := lipgloss.NewStyle()
+		if i == m.deployCursor {
+			rowStyle = selectedRowStyle
+		}
+		sha := shortSHA(d.SHA)
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %-*s  ", repoW, d.Repo, envW, d.Environment)))
+		sb.WriteString(statusStyle(d.State).Inherit(rowStyle).Render(fmt.Sprintf("%-*s", stateW, withIcon(d.State))))
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %-7s  %-*s  %s", refW, truncate(d.Ref, refW), sha,
+			creatorW, truncate(d.Creator, creatorW), formatAge(d.UpdatedAt, now))))
+		sb.WriteByte('\n')
+	}
+	if m.deploymentsErr != nil {
+		sb.WriteString(fmt.Sprintf("\n  Error: %v\n", m.deploymentsErr))
+	}
+	return sb.String()
+}
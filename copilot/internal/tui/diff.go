@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"time"
+
+	ghclient "ghamon/internal/github"
+)
+
+// highlightDuration is how long a changed, new or removed row stays marked.
+const highlightDuration = 5 * time.Second
+
+type changeKind int
+
+const (
+	changeNone changeKind = iota
+	changeStatus
+	changeAdded
+	changeRemoved
+)
+
+// rowKey identifies a row across snapshots.
+type rowKey struct {
+	repo     string
+	workflow string
+	header   bool
+}
+
+func keyOf(r ghclient.WorkflowRun) rowKey {
+	return rowKey{repo: r.Repo, workflow: r.Workflow}
+}
+
+// rowChange tracks what happened to a row in recent snapshots.
+type rowChange struct {
+	kind        changeKind
+	markedAt    time.Time // when kind was last set
+	lastChanged time.Time // when the row's status last changed
+}
+
+// highlighted reports whether the row should still be marked at now.
+func (c rowChange) highlighted(now time.Time) bool {
+	return c.kind != changeNone && now.Sub(c.markedAt) < highlightDuration
+}
+
+// diffSnapshot compares next against prev by (repo, workflow) and returns the
+// updated change state plus the rows that disappeared, which are kept on screen
+// until their highlight expires. When seed is true nothing is marked; rows
+// just record when they last changed.
+func diffSnapshot(prev, next []ghclient.WorkflowRun, changes map[rowKey]rowChange, removed []ghclient.WorkflowRun, seed bool, now time.Time) (map[rowKey]rowChange, []ghclient.WorkflowRun) {
+	prevByKey := make(map[rowKey]ghclient.WorkflowRun, len(prev))
+	for _, r := range prev {
+		prevByKey[keyOf(r)] = r
+	}
+
+	out := make(map[rowKey]rowChange, len(next))
+	nextKeys := make(map[rowKey]bool, len(next))
+	for _, r := range next {
+		k := keyOf(r)
+		nextKeys[k] = true
+		c := changes[k]
+		old, existed := prevByKey[k]
+		switch {
+		case seed:
+			c.lastChanged = r.UpdatedAt
+			if c.lastChanged.IsZero() {
+				c.lastChanged = now
+			}
+		case !existed:
+			c = rowChange{kind: changeAdded, markedAt: now, lastChanged: now}
+		case old.DisplayStatus() != r.DisplayStatus():
+			c = rowChange{kind: changeStatus, markedAt: now, lastChanged: now}
+		}
+		out[k] = c
+	}
+
+	var stillRemoved []ghclient.WorkflowRun
+	for _, r := range removed {
+		k := keyOf(r)
+		if c := changes[k]; !nextKeys[k] && c.highlighted(now) {
+			out[k] = c
+			stillRemoved = append(stillRemoved, r)
+		}
+	}
+	if !seed {
+		for _, r := range prev {
+			k := keyOf(r)
+			if !nextKeys[k] {
+				out[k] = rowChange{kind: changeRemoved, markedAt: now, lastChanged: now}
+				stillRemoved = append(stillRemoved, r)
+			}
+		}
+	}
+	return out, stillRemoved
+}
+
+// expireChanges clears highlights older than highlightDuration and drops
+// removed rows whose highlight has expired.
+func expireChanges(changes map[rowKey]rowChange, removed []ghclient.WorkflowRun, now time.Time) (map[rowKey]rowChange, []ghclient.WorkflowRun) {
+	out := make(map[rowKey]rowChange, len(changes))
+	for k, c := range changes {
+		if c.kind != changeNone && !c.highlighted(now) {
+			if c.kind == changeRemoved {
+				continue
+			}
+			c.kind = changeNone
+		}
+		out[k] = c
+	}
+	var kept []ghclient.WorkflowRun
+	for _, r := range removed {
+		if _, ok := out[keyOf(r)]; ok {
+			kept = append(kept, r)
+		}
+	}
+	return out, kept
+}
+
+// displayRow is a row as rendered, including rows that were just removed and,
+// when grouping by repository, group headers.
+type displayRow struct {
+	run     ghclient.WorkflowRun
+	change  rowChange
+	header  bool
+	summary string
+}
+
+func (r displayRow) key() rowKey {
+	k := keyOf(r.run)
+	k.header = r.header
+	return k
+}
+
+// displayRows merges the current runs with recently removed ones, placing each
+// removed row after the last row of its repository.
+func (m Model) displayRows() []displayRow {
+	rows := make([]displayRow, 0, len(m.runs)+len(m.removed))
+	for _, r := range m.runs {
+		rows = append(rows, displayRow{run: r, change: m.changes[keyOf(r)]})
+	}
+	for _, r := range m.removed {
+		row := displayRow{run: r, change: m.changes[keyOf(r)]}
+		at := len(rows)
+		for i := len(rows) - 1; i >= 0; i-- {
+			if rows[i].run.Repo == r.Repo {
+				at = i + 1
+				break
+			}
+		}
+		rows = append(rows[:at], append([]displayRow{row}, rows[at:]...)...)
+	}
+	return rows
+}
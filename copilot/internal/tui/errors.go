@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	ghclient "ghamon/internal/github"
+)
+
+// errorSummary is what an error row shows in its workflow cell: the error,
+// after the workflow's name when only that workflow failed, e.g.
+// "ci.yml: 404 Not Found".
+func errorSummary(r ghclient.WorkflowRun) string {
+	if r.Workflow != "" {
+		return r.Workflow + ": " + r.Err.Error()
+	}
+	return r.Err.Error()
+}
+
+// errorContent is the failure view of an error row, which has no logs to
+// show: the repository and workflow concerned, GitHub's answer, whether
+// ghamon keeps trying, and the hint.
+func (m Model) errorContent() string {
+	r := m.failureRun
+	e := r.Err
+	fields := [][2]string{{"Repository", r.Repo}}
+	if r.Workflow != "" {
+		fields = append(fields, [2]string{"Workflow", r.Workflow})
+	}
+	status := "no response"
+	if e.StatusCode != 0 {
+		status = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	retried := "no, it needs fixing"
+	if e.Retryable {
+		retried = "yes, on the next refresh"
+	}
+	fields = append(fields,
+		[2]string{"HTTP status", status},
+		[2]string{"Message", e.Message},
+		[2]string{"Retried", retried},
+	)
+
+	var sb strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&sb, "  %-12s %s\n", f[0]+":", f[1])
+	}
+	if hint := e.Hint(); hint != "" {
+		sb.WriteString("\n  " + failureLineStyle.Render(hint) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	ghclient "ghamon/internal/github"
+)
+
+// failureResult is the failure of a run, or why it could not be fetched.
+type failureResult struct {
+	failure ghclient.Failure
+	err     error
+}
+
+type failureMsg struct {
+	runID int64
+	failureResult
+}
+
+// WithFailures returns a copy of m that explains failed runs, fetching their
+// failed jobs and annotations from client.
+func (m Model) WithFailures(client ghclient.FailureClient) Model {
+	m.failureClient = client
+	return m
+}
+
+// fetchFailures fetches the failure of each failed run in runs not fetched
+// yet. A finished run's failure does not change, so each is fetched once;
+// one that could not be fetched keeps its error until the next refresh
+// tries again.
+func (m *Model) fetchFailures(runs []ghclient.WorkflowRun) tea.Cmd {
+	if m.failureClient == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, r := range runs {
+		if !r.Failed() || r.ID == 0 || m.failuresPending[r.ID] {
+			continue
+		}
+		if res, ok := m.failures[r.ID]; ok && res.err == nil {
+			continue
+		}
+		m.failuresPending[r.ID] = true
+		cmds = append(cmds, m.doFetchFailure(r))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m Model) doFetchFailure(r ghclient.WorkflowRun) tea.Cmd {
+	client := m.failureClient
+	ctx := m.ctx
+	return func() tea.Msg {
+		parts := strings.SplitN(r.Repo, "/", 2)
+		f, err := client.GetFailure(ctx, parts[0], parts[1], r.ID)
+		return failureMsg{runID: r.ID, failureResult: failureResult{failure: f, err: err}}
+	}
+}
+
+// selectedRun returns the workflow run under the cursor.
+func (m Model) selectedRun() (ghclient.WorkflowRun, bool) {
+	rows := m.visibleRows()
+	if m.cursor >= len(rows) || rows[m.cursor].header {
+		return ghclient.WorkflowRun{}, false
+	}
+	return rows[m.cursor].run, true
+}
+
+// toggleFailureLine shows or hides the failure reason under the selected
+// failed run.
+func (m *Model) toggleFailureLine() {
+	if m.failureClient == nil {
+		m.notice = "failure reasons need GITHUB_TOKEN"
+		return
+	}
+	r, ok := m.selectedRun()
+	if !ok || !r.Failed() {
+		return
+	}
+	m.expanded[keyOf(r)] = !m.expanded[keyOf(r)]
+}
+
+// openFailure switches to the failed jobs and annotations of the selected run,
+// or to why it could not be fetched if it is an error row. It reports whether
+// there was anything to show.
+func (m *Model) openFailure() bool {
+	r, ok := m.selectedRun()
+	if !ok || r.Err == nil && (!r.Failed() || r.ID == 0 || m.failureClient == nil) {
+		return false
+	}
+	m.view = viewFailure
+	m.failureRun = r
+	m.failureCursor = 0
+	return true
+}
+
+// updateFailure handles key presses in the failure detail view.
+func (m Model) updateFailure(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	n := len(m.failures[m.failureRun.ID].failure.Annotations)
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.refresh) && m.failureRun.Err != nil:
+		// The error row is replaced by the refresh, so go back to it.
+		m.view = viewWorkflows
+		m.loading = true
+		m.resetProgress()
+		cmd = m.doFetch()
+	case key.Matches(msg, k.refresh):
+		delete(m.failures, m.failureRun.ID)
+		cmd = m.fetchFailures([]ghclient.WorkflowRun{m.failureRun})
+	case key.Matches(msg, k.back, k.selectRow):
+		m.view = viewWorkflows
+	case key.Matches(msg, k.up):
+		m.failureCursor = max(0, m.failureCursor-1)
+	case key.Matches(msg, k.down):
+		m.failureCursor = max(0, min(m.failureCursor+1, n-1))
+	case key.Matches(msg, k.home):
+		m.failureCursor = 0
+	case key.Matches(msg, k.end):
+		m.failureCursor = max(0, n-1)
+	case key.Matches(msg, k.browser):
+		cmd = openInBrowser(m.failureRun.URL)
+	}
+	m.render()
+	return m, cmd
+}
+
+// failureLine renders the failure reason shown under an expanded run.
+func (m Model) failureLine(r ghclient.WorkflowRun) string {
+	res, ok := m.failures[r.ID]
+	var reason string
+	switch {
+	case !ok:
+		reason = "fetching failure reason…"
+	case res.err != nil:
+		reason = fmt.Sprintf("error: %v", res.err)
+	default:
+		reason = res.failure.Summary()
+		if reason == "" {
+			reason = "no failure reason reported"
+		}
+	}
+	return "      ↳ " + failureLineStyle.Render(reason)
+}
+
+// failureLinesBefore counts the failure lines shown above row n of rows.
+func (m Model) failureLinesBefore(rows []displayRow, n int) int {
+	count := 0
+	for _, row := range rows[:min(n, len(rows))] {
+		if !row.header && row.run.Failed() && m.expanded[keyOf(row.run)] {
+			count++
+		}
+	}
+	return count
+}
+
+// failureHeight is the number of lines above the annotation table in the
+// failure detail view.
+func (m Model) failureHeight() int {
+	return len(m.failures[m.failureRun.ID].failure.Jobs) + 2 // blank line and column header
+}
+
+func (m Model) failureContent() string {
+	if m.failureRun.Err != nil {
+		return m.errorContent()
+	}
+	res, ok := m.failures[m.failureRun.ID]
+	switch {
+	case !ok:
+		return "  Fetching failed jobs…\n"
+	case res.err != nil:
+		return fmt.Sprintf("  Error: %v\n", res.err)
+	}
+	f := res.failure
+
+	var sb strings.Builder
+	for _, j := range f.Jobs {
+		if j.Step != "" {
+			sb.WriteString(fmt.Sprintf("  Failed job %s at step %q\n", j.Name, j.Step))
+		} else {
+			sb.WriteString(fmt.Sprintf("  Failed job %s\n", j.Name))
+		}
+	}
+	sb.WriteByte('\n')
+
+	jobW, levelW, locW := 16, 8, 32
+	for _, a := range f.Annotations {
+		jobW = max(jobW, len(a.Job)+2)
+		locW = max(locW, len(a.Location())+2)
+	}
+	hdr := fmt.Sprintf("  %-*s  %-*s  %-*s  %s", jobW, "JOB", levelW, "LEVEL", locW, "LOCATION", "MESSAGE")
+	sb.WriteString(colHeaderStyle.Render(hdr))
+	sb.WriteByte('\n')
+	if len(f.Annotations) == 0 {
+		sb.WriteString("  No annotations.\n")
+	}
+	for i, a := range f.Annotations {
+		rowStyle := lipgloss.NewStyle()
+		if i == m.failureCursor {
+			rowStyle = selectedRowStyle
+		}
+		levelStyle, ok := annotationStyles[a.Level]
+		if !ok {
+			levelStyle = palette.text
+		}
+		msg := strings.ReplaceAll(strings.TrimSpace(a.Message), "\n", " ⏎ ")
+		if a.Title != "" {
+			msg = a.Title + ": " + msg
+		}
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  ", jobW, a.Job)))
+		sb.WriteString(levelStyle.Inherit(rowStyle).Render(fmt.Sprintf("%-*s", levelW, a.Level)))
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %s", locW, a.Location(), msg)))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	ghclient "ghamon/internal/github"
+)
+
+// outcome is what a display status means for filtering, colouring and
+// counting runs.
+type outcome int
+
+const (
+	outcomeOther   outcome = iota // cancelled, skipped, no runs, disabled…
+	outcomeSuccess                // success
+	outcomeFailed                 // failure, timed_out, startup_failure
+	outcomeError                  // the runs could not be fetched
+	outcomeRunning                // in progress
+	outcomeWaiting                // queued, requested, waiting, pending
+)
+
+// outcomeOf returns the outcome of a display status (see
+// ghclient.WorkflowRun.DisplayStatus).
+func outcomeOf(displayStatus string) outcome {
+	switch displayStatus {
+	case "success":
+		return outcomeSuccess
+	case "failure", "timed_out", "startup_failure":
+		return outcomeFailed
+	case "error":
+		return outcomeError
+	case "in progress":
+		return outcomeRunning
+	case "queued", "requested", "waiting", "pending":
+		return outcomeWaiting
+	}
+	return outcomeOther
+}
+
+// failing reports whether o needs attention: a failed run or a fetch error.
+func (o outcome) failing() bool {
+	return o == outcomeFailed || o == outcomeError
+}
+
+// active reports whether o is a run that has not finished yet.
+func (o outcome) active() bool {
+	return o == outcomeRunning || o == outcomeWaiting
+}
+
+// quickFilter is one of the one-key filters (f, p and s by default). Its
+// zero value lets every run through.
+type quickFilter int
+
+const (
+	noQuickFilter quickFilter = iota
+	onlyFailing
+	onlyRunning
+	exceptSuccess
+)
+
+// quickFilterLabels name each quick filter in the footer.
+var quickFilterLabels = [...]string{"", "failing only", "running only", "hiding successful"}
+
+func (f quickFilter) allows(o outcome) bool {
+	switch f {
+	case onlyFailing:
+		return o.failing()
+	case onlyRunning:
+		return o.active()
+	case exceptSuccess:
+		return o != outcomeSuccess
+	}
+	return true
+}
+
+// tableFilter is what the workflow table is narrowed by: the text typed
+// after / and a quick filter.
+type tableFilter struct {
+	search string
+	quick  quickFilter
+}
+
+func (f tableFilter) on() bool {
+	return f.search != "" || f.quick != noQuickFilter
+}
+
+// keeps reports whether run r passes the filter. The search is matched
+// against the repository and workflow name together.
+func (f tableFilter) keeps(r ghclient.WorkflowRun) bool {
+	return f.quick.allows(outcomeOf(r.DisplayStatus())) && searchMatches(f.search, r.Repo+" "+r.Workflow)
+}
+
+// label is shown in the footer while the filter is on, e.g.
+// "/dpl, failing only".
+func (f tableFilter) label() string {
+	var parts []string
+	if f.search != "" {
+		parts = append(parts, "/"+f.search)
+	}
+	if f.quick != noQuickFilter {
+		parts = append(parts, quickFilterLabels[f.quick])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// searchMatches reports whether text contains the letters of every word of
+// search in order, though not necessarily next to each other, so that "dpl"
+// finds "Deploy". Case is ignored.
+func searchMatches(search, text string) bool {
+	text = strings.ToLower(text)
+	for _, word := range strings.Fields(strings.ToLower(search)) {
+		rest := text
+		for _, c := range word {
+			i := strings.IndexRune(rest, c)
+			if i < 0 {
+				return false
+			}
+			rest = rest[i+utf8.RuneLen(c):]
+		}
+	}
+	return true
+}
+
+// visibleRows returns the rows of the workflow table as shown: filtered,
+// sorted, and under their repository headers in grouped mode.
+func (m Model) visibleRows() []displayRow {
+	rows := slices.DeleteFunc(m.displayRows(), func(row displayRow) bool {
+		return !m.filter.keeps(row.run)
+	})
+	sortRows(rows, m.sort, m.sortDesc, time.Now())
+	if !m.grouped {
+		return rows
+	}
+	return groupRows(rows, m.runs, m.collapsed)
+}
+
+// followSelection moves the cursor to wherever the selected row ended up
+// after a refresh, a sort or a new filter. A selected row that went away
+// leaves the cursor at its index, on whichever row took its place.
+func (m *Model) followSelection() {
+	rows := m.visibleRows()
+	if i := slices.IndexFunc(rows, func(row displayRow) bool { return row.key() == m.selected }); i >= 0 {
+		m.cursor = i
+		return
+	}
+	m.setCursor(m.cursor)
+}
+
+// setCursor selects visible row i, clamped to the rows there are.
+func (m *Model) setCursor(i int) {
+	rows := m.visibleRows()
+	m.cursor = max(0, min(i, len(rows)-1))
+	if m.cursor < len(rows) {
+		m.selected = rows[m.cursor].key()
+	}
+}
+
+// updateSearch edits the search while it is being typed. enter keeps it and
+// esc drops it; the table narrows with every key.
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEnter, tea.KeyEsc:
+		m.searching = false
+		if msg.Type == tea.KeyEsc {
+			m.filter.search = ""
+		}
+	case tea.KeyBackspace:
+		_, size := utf8.DecodeLastRuneInString(m.filter.search)
+		m.filter.search = m.filter.search[:len(m.filter.search)-size]
+	case tea.KeySpace:
+		m.filter.search += " "
+	case tea.KeyRunes:
+		m.filter.search += string(msg.Runes)
+	}
+	m.followSelection()
+	m.render()
+	return m, nil
+}
+
+// toggleGroup folds or unfolds the repository of the row under the cursor,
+// leaving the cursor on its header.
+func (m *Model) toggleGroup() {
+	rows := m.visibleRows()
+	if m.cursor >= len(rows) {
+		return
+	}
+	repo := rows[m.cursor].run.Repo
+	m.collapsed[repo] = !m.collapsed[repo]
+	m.selected = rowKey{repo: repo, header: true}
+	m.followSelection()
+}
+
+// setAllCollapsed folds or unfolds every repository. Folding moves the
+// selection to the header of its repository, the only row of it left.
+func (m *Model) setAllCollapsed(collapsed bool) {
+	for _, repo := range m.repos {
+		m.collapsed[repo] = collapsed
+	}
+	if collapsed && m.grouped {
+		m.selected = rowKey{repo: m.selected.repo, header: true}
+	}
+	m.followSelection()
+}
+
+// toggleQuickFilter turns quick filter f on, replacing any other, or off if
+// it is already on.
+func (m *Model) toggleQuickFilter(f quickFilter) {
+	if m.filter.quick == f {
+		f = noQuickFilter
+	}
+	m.filter.quick = f
+	m.followSelection()
+}
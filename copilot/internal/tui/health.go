@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	ghclient "ghamon/internal/github"
+)
+
+// overallState is the health line's verdict on every workflow together.
+// Later states are worse and win.
+type overallState int
+
+const (
+	statePending overallState = iota // no runs to judge yet
+	statePassing
+	stateRunning
+	stateWarning // a failure elsewhere than the default branch, or an error row
+	stateFailing // a failure on the default branch
+)
+
+// stateLooks gives each overall state its name, its icon in the header and
+// window title, and the run status whose colour it is drawn in.
+var stateLooks = [...]struct{ name, icon, status string }{
+	statePending: {"pending", "○", "none"},
+	statePassing: {"passing", "✓", "success"},
+	stateRunning: {"running", "●", "in progress"},
+	stateWarning: {"warning", "!", "cancelled"},
+	stateFailing: {"failing", "✗", "failure"},
+}
+
+func (s overallState) String() string {
+	return stateLooks[s].name
+}
+
+// runCounts tallies runs by outcome, plus the failures on a default branch
+// among them, which are what turn the state to failing.
+type runCounts struct {
+	byOutcome      [outcomeWaiting + 1]int
+	failingDefault int
+}
+
+func countRuns(runs []ghclient.WorkflowRun) runCounts {
+	var c runCounts
+	for _, r := range runs {
+		o := outcomeOf(r.DisplayStatus())
+		c.byOutcome[o]++
+		if o == outcomeFailed && r.OnDefaultBranch() {
+			c.failingDefault++
+		}
+	}
+	return c
+}
+
+// failing is the number of failed runs and fetch errors, shown in the window
+// title.
+func (c runCounts) failing() int {
+	return c.byOutcome[outcomeFailed] + c.byOutcome[outcomeError]
+}
+
+func (c runCounts) state() overallState {
+	switch {
+	case c.failingDefault > 0:
+		return stateFailing
+	case c.failing() > 0:
+		return stateWarning
+	case c.byOutcome[outcomeRunning]+c.byOutcome[outcomeWaiting] > 0:
+		return stateRunning
+	case c.byOutcome[outcomeSuccess] > 0:
+		return statePassing
+	}
+	return statePending
+}
+
+// countLabels is the order and wording of the counts on the health line.
+var countLabels = []struct {
+	o     outcome
+	label string
+}{
+	{outcomeSuccess, "ok"},
+	{outcomeFailed, "failing"},
+	{outcomeRunning, "running"},
+	{outcomeWaiting, "queued"},
+	{outcomeError, "error"},
+}
+
+// String is e.g. "12 ok · 2 failing · 1 queued"; outcomes with no runs are
+// left out.
+func (c runCounts) String() string {
+	var parts []string
+	for _, l := range countLabels {
+		if n := c.byOutcome[l.o]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, l.label))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// healthLine is the header line with the overall state and counts of every
+// run, filtered out or not, followed by the refresh status.
+func (m Model) healthLine(now time.Time) string {
+	c := countRuns(m.runs)
+	look := stateLooks[c.state()]
+	line := statusStyle(look.status).Bold(true).Render(look.icon + " " + strings.ToUpper(look.name))
+	if counts := c.String(); counts != "" {
+		line += headerInfoStyle.Render("  " + counts)
+	}
+	return line + footerStyle.Render("  "+m.refreshStatus(now))
+}
+
+// refreshStatus is the part of the health line about timing: the last
+// complete refresh (or how long GitHub has been unreachable), the age of any
+// runs kept from an earlier fetch, and what happens next.
+func (m Model) refreshStatus(now time.Time) string {
+	var parts []string
+	switch {
+	case !m.offlineSince.IsZero():
+		parts = append(parts, "offline since "+formatClock(m.offlineSince))
+	case !m.lastRefresh.IsZero():
+		parts = append(parts, "refreshed "+formatClock(m.lastRefresh))
+	default:
+		parts = append(parts, "not refreshed yet")
+	}
+	if stale := m.staleSince(); !stale.IsZero() {
+		parts = append(parts, "stale data from "+formatDuration(max(time.Second, now.Sub(stale)))+" ago")
+	}
+	switch {
+	case m.loading:
+		parts = append(parts, "refreshing…")
+	case m.paused:
+		parts = append(parts, "paused")
+	default:
+		parts = append(parts, "next in "+formatDuration(max(time.Second, m.nextRefresh.Sub(now))))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// updateTitle puts the overall state and failing count in the terminal
+// window title, e.g. "✗ ghamon: failing (2)". It returns nil when the title
+// would not change.
+func (m *Model) updateTitle() tea.Cmd {
+	c := countRuns(m.runs)
+	state := c.state()
+	title := "ghamon: " + state.String()
+	if n := c.failing(); n > 0 {
+		title += fmt.Sprintf(" (%d)", n)
+	}
+	title = stateLooks[state].icon + " " + title
+	if title == m.title {
+		return nil
+	}
+	m.title = title
+	return tea.SetWindowTitle(title)
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	ghclient "ghamon/internal/github"
+)
+
+// sparkline renders a workflow's recent run statuses, oldest first, as one
+// coloured dot per run: filled for finished runs, hollow for runs that are
+// still queued or in progress. Every dot inherits base so the selection
+// highlight spans the sparkline. Only the latest width runs are shown.
+func sparkline(history []string, width int, base lipgloss.Style) string {
+	history = history[max(0, len(history)-width):]
+	var sb strings.Builder
+	for _, status := range history {
+		style := statusStyle(status)
+		dot := "●"
+		if outcomeOf(status).active() {
+			dot = "○"
+		}
+		sb.WriteString(style.Inherit(base).Render(dot))
+	}
+	return sb.String()
+}
+
+// WithHistory returns a copy of m that keeps up to n runs in the history of
+// a pushed run, as the client fetching the runs does.
+func (m Model) WithHistory(n int) Model {
+	m.history = n
+	return m
+}
+
+// pushHistory returns old's history updated with the pushed run: the latest
+// entry is replaced if run is the same run as old, and otherwise run is
+// appended. The history grows up to size entries, and only then is the
+// oldest entry dropped; it never gets shorter than old's.
+func pushHistory(old, run ghclient.WorkflowRun, size int) []string {
+	if len(old.History) == 0 {
+		return nil
+	}
+	history := append([]string(nil), old.History...)
+	if run.ID == 0 || run.ID == old.ID {
+		history[len(history)-1] = run.DisplayStatus()
+		return history
+	}
+	history = append(history, run.DisplayStatus())
+	if limit := max(size, len(old.History)); len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
+}
+
+// historyWidth returns the width of the history column for rows, removed
+// ones included, or zero if none of them has any history.
+func historyWidth(rows []displayRow) int {
+	w := 0
+	for _, row := range rows {
+		w = max(w, len(row.run.History))
+	}
+	if w > 0 {
+		w = max(w, len("HISTORY"))
+	}
+	return w
+}
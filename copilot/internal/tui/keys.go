@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"ghamon/internal/config"
+)
+
+// KeyMap holds the key bindings of every view. Text entry (search, the
+// download directory prompt and confirmations) keeps fixed keys.
+type KeyMap struct {
+	quit, refresh, help key.Binding
+
+	up, down, pageUp, pageDown, home, end key.Binding
+	selectRow, back, browser              key.Binding
+
+	search, failing, running, hideSuccessful     key.Binding
+	sort, reverse, group, collapseAll, expandAll key.Binding
+	changed, timing                              key.Binding
+	pause, faster, slower, adaptive              key.Binding
+
+	pullRequests, runners, deployments, artifacts key.Binding
+	failure, usage, workflowState                 key.Binding
+
+	prFilter, unzip, deleteCache key.Binding
+}
+
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), desc))
+}
+
+// rebind gives b new keys, keeping its description. With no keys, b is
+// switched off.
+func rebind(b *key.Binding, keys ...string) {
+	b.SetKeys(keys...)
+	b.SetHelp(keyLabel(keys), b.Help().Desc)
+	b.SetEnabled(len(keys) > 0)
+}
+
+// keyLabel is how keys are written in the footer and the help, e.g.
+// "q/ctrl+c". A capital letter bound alongside its lower case, so that the
+// key works with caps lock on, is not worth mentioning.
+func keyLabel(keys []string) string {
+	var labels []string
+	for _, k := range keys {
+		switch {
+		case k == " ":
+			k = "space"
+		case len(k) == 1 && unicode.IsUpper(rune(k[0])) && slices.Contains(keys, strings.ToLower(k)):
+			continue
+		}
+		labels = append(labels, k)
+	}
+	return strings.Join(labels, "/")
+}
+
+// applyPreset adds the keys of preset name to k. The presets only add
+// familiar keys for moving around; everything else keeps its default keys.
+func (k *KeyMap) applyPreset(name string) error {
+	switch name {
+	case "", "default":
+	case "vim":
+		rebind(&k.pageUp, "pgup", "ctrl+b", "ctrl+u")
+		rebind(&k.pageDown, "pgdown", "ctrl+f", "ctrl+d")
+	case "emacs":
+		rebind(&k.up, "up", "ctrl+p")
+		rebind(&k.down, "down", "ctrl+n")
+		rebind(&k.pageUp, "pgup", "alt+v")
+		rebind(&k.pageDown, "pgdown", "ctrl+v")
+		rebind(&k.home, "home", "alt+<")
+		rebind(&k.end, "end", "alt+>")
+		rebind(&k.back, "esc", "ctrl+g")
+		rebind(&k.search, "/", "ctrl+s")
+	default:
+		return fmt.Errorf("unknown key preset %q (presets: default, emacs, vim)", name)
+	}
+	return nil
+}
+
+// DefaultKeyMap returns the built-in key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		quit:    binding("quit", "q", "Q", "ctrl+c"),
+		refresh: binding("refresh", "r", "R"),
+		help:    binding("help", "?"),
+
+		up:        binding("up", "up", "k"),
+		down:      binding("down", "down", "j"),
+		pageUp:    binding("page up", "pgup"),
+		pageDown:  binding("page down", "pgdown"),
+		home:      binding("first row", "home", "g"),
+		end:       binding("last row", "end", "G"),
+		selectRow: binding("select", "enter", " "),
+		back:      binding("back", "esc"),
+		browser:   binding("open in browser", "b", "B"),
+
+		search:         binding("search", "/"),
+		failing:        binding("failing only", "f"),
+		running:        binding("running only", "p"),
+		hideSuccessful: binding("hide successful", "s"),
+		sort:           binding("sort", "o"),
+		reverse:        binding("reverse sort", "O"),
+		group:          binding("group", "t"),
+		collapseAll:    binding("fold all", "["),
+		expandAll:      binding("unfold all", "]"),
+		changed:        binding("changed column", "c", "C"),
+		timing:         binding("timing", "d", "D"),
+		pause:          binding("pause refresh", "P"),
+		faster:         binding("refresh faster", "+"),
+		slower:         binding("refresh slower", "-"),
+		adaptive:       binding("adaptive refresh", "~"),
+
+		pullRequests:  binding("pull requests", "v", "V"),
+		runners:       binding("runners", "u", "U"),
+		deployments:   binding("deployments", "e", "E"),
+		artifacts:     binding("artifacts", "a", "A"),
+		failure:       binding("failure reason", "x", "X"),
+		usage:         binding("usage", "m", "M"),
+		workflowState: binding("enable/disable", "w", "W"),
+
+		prFilter:    binding("mine/review/all", "a"),
+		unzip:       binding("unzip", "z", "Z"),
+		deleteCache: binding("delete cache", "D", "delete"),
+	}
+}
+
+// actions maps the action names of the key bindings file (see config.Keys)
+// to the bindings they change.
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit": &k.quit, "refresh": &k.refresh, "help": &k.help,
+		"up": &k.up, "down": &k.down, "page_up": &k.pageUp, "page_down": &k.pageDown,
+		"home": &k.home, "end": &k.end, "select": &k.selectRow, "back": &k.back, "browser": &k.browser,
+		"search": &k.search, "failing": &k.failing, "running": &k.running, "hide_successful": &k.hideSuccessful,
+		"sort": &k.sort, "reverse": &k.reverse, "group": &k.group,
+		"collapse_all": &k.collapseAll, "expand_all": &k.expandAll,
+		"changed": &k.changed, "timing": &k.timing,
+		"pause": &k.pause, "faster": &k.faster, "slower": &k.slower, "adaptive": &k.adaptive,
+		"pull_requests": &k.pullRequests, "runners": &k.runners, "deployments": &k.deployments,
+		"artifacts": &k.artifacts, "failure": &k.failure, "usage": &k.usage, "workflow_state": &k.workflowState,
+		"pr_filter": &k.prFilter, "unzip": &k.unzip, "delete_cache": &k.deleteCache,
+	}
+}
+
+// NewKeyMap returns the default key bindings changed by cfg. Errors name an
+// unknown preset or the unknown actions; the bindings returned with them
+// still have everything else of cfg applied, so the TUI starts anyway.
+func NewKeyMap(cfg config.Keys) (KeyMap, error) {
+	k := DefaultKeyMap()
+	err := k.applyPreset(cfg.Preset)
+	actions := k.actions()
+	var unknown []string
+	for action, keys := range cfg.Bindings {
+		if b, ok := actions[action]; ok {
+			rebind(b, keys...)
+		} else {
+			unknown = append(unknown, action)
+		}
+	}
+	if err == nil && len(unknown) > 0 {
+		slices.Sort(unknown)
+		err = fmt.Errorf("unknown actions in key bindings: %s", strings.Join(unknown, ", "))
+	}
+	return k, err
+}
+
+// WithKeys returns a copy of m using the key bindings k.
+func (m Model) WithKeys(k KeyMap) Model {
+	m.keys = k
+	return m
+}
+
+// relabel returns b described as desc, for views where it does something else.
+func relabel(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// keyGroups returns the bindings of the current view, grouped for the help.
+func (m Model) keyGroups() [][]key.Binding {
+	k := m.keys
+	nav := []key.Binding{k.up, k.down, k.pageUp, k.pageDown, k.home, k.end}
+	general := []key.Binding{k.refresh, k.help, k.quit}
+	switch {
+	case m.view == viewPullRequests && m.detail:
+		return [][]key.Binding{nav, {k.back, relabel(k.browser, "open check")}, general}
+	case m.view == viewPullRequests:
+		return [][]key.Binding{nav, {relabel(k.selectRow, "checks"), k.prFilter, k.browser,
+			relabel(k.pullRequests, "workflows")}, general}
+	case m.view == viewRunners:
+		return [][]key.Binding{nav, {relabel(k.runners, "workflows"), relabel(k.back, "workflows")}, general}
+	case m.view == viewDeployments:
+		return [][]key.Binding{nav, {relabel(k.browser, "open workflow run"), relabel(k.deployments, "workflows"),
+			relabel(k.back, "workflows")}, general}
+	case m.view == viewArtifacts:
+		return [][]key.Binding{nav, {relabel(k.selectRow, "download"), k.unzip, relabel(k.artifacts, "workflows"),
+			relabel(k.back, "workflows")}, general}
+	case m.view == viewFailure:
+		return [][]key.Binding{nav, {relabel(k.browser, "open run"), k.back}, general}
+	case m.view == viewUsage:
+		return [][]key.Binding{nav, {k.deleteCache, relabel(k.usage, "workflows"), relabel(k.back, "workflows")}, general}
+	}
+	return [][]key.Binding{
+		nav,
+		{k.search, k.failing, k.running, k.hideSuccessful, relabel(k.back, "clear filter"),
+			k.sort, k.reverse, k.group, k.collapseAll, k.expandAll},
+		{relabel(k.selectRow, "failure details/fold"), k.failure, k.browser, k.workflowState, k.changed, k.timing},
+		{k.pullRequests, k.runners, k.deployments, k.artifacts, k.usage},
+		{k.pause, k.faster, k.slower, k.adaptive},
+		general,
+	}
+}
+
+// footerKeys returns the bindings hinted at in the footer of the current view.
+func (m Model) footerKeys() []key.Binding {
+	k := m.keys
+	switch {
+	case m.view == viewPullRequests && m.detail:
+		return []key.Binding{k.quit, k.help, k.back, relabel(k.browser, "open check in browser")}
+	case m.view == viewPullRequests:
+		return []key.Binding{k.quit, k.help, k.refresh, relabel(k.pullRequests, "workflows"), k.prFilter,
+			relabel(k.selectRow, "checks"), k.browser}
+	case m.view == viewRunners:
+		return []key.Binding{k.quit, k.help, k.refresh, relabel(k.runners, "workflows")}
+	case m.view == viewDeployments:
+		return []key.Binding{k.quit, k.help, k.refresh, relabel(k.deployments, "workflows"), relabel(k.browser, "open workflow run")}
+	case m.view == viewArtifacts:
+		return []key.Binding{k.quit, k.help, k.refresh, relabel(k.artifacts, "workflows"), relabel(k.selectRow, "download"),
+			relabel(k.unzip, fmt.Sprintf("unzip [%s]", onOff(m.unzip)))}
+	case m.view == viewFailure:
+		return []key.Binding{k.quit, k.help, k.refresh, k.back, relabel(k.browser, "open run in browser")}
+	case m.view == viewUsage:
+		return []key.Binding{k.quit, k.help, k.refresh, relabel(k.usage, "workflows"), k.deleteCache}
+	case m.filter.on():
+		return []key.Binding{k.quit, k.help, k.refresh, relabel(k.back, "clear filter")}
+	}
+	keys := []key.Binding{k.quit, k.help, k.refresh, k.search, k.failing, k.running, k.hideSuccessful,
+		k.sort, k.group, k.timing, k.pullRequests, k.runners, k.deployments, k.artifacts, k.failure, k.usage, k.browser}
+	if m.grouped {
+		keys = append(keys, relabel(k.selectRow, "fold"), k.collapseAll, k.expandAll)
+	}
+	return keys
+}
+
+// footerHints lists the enabled bindings for the footer, each as "key: what",
+// e.g. "  q: quit   r: refresh".
+func footerHints(bindings []key.Binding) string {
+	var sb strings.Builder
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s: %s ", b.Help().Key, b.Help().Desc)
+	}
+	return strings.TrimSuffix(sb.String(), " ")
+}
+
+// updateHelp handles keys while the ? overlay is up: ?, esc or q close it
+// (q quits), and up and down scroll it when it does not fit.
+func (m Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.up):
+		m.vp.ScrollUp(1)
+	case key.Matches(msg, m.keys.down):
+		m.vp.ScrollDown(1)
+	case key.Matches(msg, m.keys.help, m.keys.back):
+		m.showHelp = false
+		m.render()
+	}
+	return m, nil
+}
+
+// helpContent lists every binding of the current view.
+func (m Model) helpContent() string {
+	h := help.New()
+	h.Width = m.width - 2
+	h.Styles.FullKey = colHeaderStyle.UnsetUnderline()
+	h.Styles.FullDesc = palette.text
+	h.Styles.FullSeparator = footerStyle
+	h.FullSeparator = "    "
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("  Key bindings"))
+	sb.WriteString(footerStyle.Render(fmt.Sprintf("   %s/%s: close", m.keys.help.Help().Key, m.keys.back.Help().Key)))
+	sb.WriteString("\n\n")
+	for _, line := range strings.Split(h.FullHelpView(m.keyGroups()), "\n") {
+		sb.WriteString("  " + line + "\n")
+	}
+	return sb.String()
+}
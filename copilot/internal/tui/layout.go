@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Rows of the workflow table start with a change marker and a space
+// (tableIndent) and leave columnGap spaces between cells. Cut-off text ends
+// in an ellipsis.
+const (
+	tableIndent = 2
+	columnGap   = 2
+	ellipsis    = "…"
+)
+
+// columnID names a column of the workflow table.
+type columnID int
+
+const (
+	colRepo columnID = iota
+	colWorkflow
+	colStatus
+	colHistory
+	colStarted
+	colDuration
+	colQueued
+	colUpdated
+	colChanged
+	colBranch
+	colSHA
+	colActor
+)
+
+// column is a column of the workflow table before it is laid out.
+//
+// A column is width wide unless it is one of these kinds:
+//   - fit: as wide as its widest cell or title, but no wider than width
+//     unless the terminal has space left over;
+//   - shrinkTo > 0: narrowed as far as shrinkTo when the table is too wide;
+//   - dropRank > 0: optional, left out when it has no cells to show, and
+//     dropped when the table is too wide, highest rank first.
+type column struct {
+	id    columnID
+	title string
+	field sortField // sortDefault if the column cannot be sorted by
+
+	width    int
+	fit      bool
+	shrinkTo int
+	dropRank int
+}
+
+// tableColumns returns the columns of the workflow table. The timing columns
+// and CHANGED are there only when switched on; HISTORY is historyW wide, or
+// empty if no run has history.
+func tableColumns(timing, changed bool, historyW int) []column {
+	cols := []column{
+		{id: colRepo, title: "REPOSITORY", field: sortRepo, width: 40, fit: true, shrinkTo: 12},
+		{id: colWorkflow, title: "WORKFLOW", field: sortWorkflow, width: 30, fit: true, shrinkTo: 12},
+		{id: colStatus, title: "STATUS", field: sortStatus, width: 17, fit: true},
+		{id: colHistory, title: "HISTORY", width: historyW, dropRank: 4},
+	}
+	if timing {
+		cols = append(cols,
+			column{id: colStarted, title: "STARTED", width: 8, dropRank: 6},
+			column{id: colDuration, title: "DURATION", field: sortDuration, width: 10, dropRank: 1},
+			column{id: colQueued, title: "QUEUED", width: 8, dropRank: 7},
+			column{id: colUpdated, title: "UPDATED", field: sortUpdated, width: 9, dropRank: 2},
+		)
+	}
+	if changed {
+		cols = append(cols, column{id: colChanged, title: "CHANGED", width: 8, dropRank: 3})
+	}
+	return append(cols,
+		column{id: colBranch, title: "BRANCH", width: 20, fit: true, shrinkTo: 8, dropRank: 5},
+		column{id: colSHA, title: "SHA", width: 7, dropRank: 8},
+		column{id: colActor, title: "ACTOR", width: 15, fit: true, dropRank: 9},
+	)
+}
+
+// placedColumn is a column with its final width, starting x cells from the
+// left edge of the line.
+type placedColumn struct {
+	column
+	x int
+}
+
+// layoutColumns sizes and places cols on a line width cells wide (unbounded
+// if width is 0). contentWidth returns the width of a column's widest cell.
+//
+// Fit columns first take the width of their content. While the table is too
+// wide, optional columns are dropped by rank and then shrinkable columns give
+// up a cell at a time, the widest first. Any space that remains goes to fit
+// columns whose content was cut.
+func layoutColumns(cols []column, width int, contentWidth func(columnID) int) []placedColumn {
+	full := make(map[columnID]int, len(cols))
+	cols = slices.DeleteFunc(slices.Clone(cols), func(c column) bool {
+		return c.dropRank > 0 && contentWidth(c.id) == 0
+	})
+	for i, c := range cols {
+		if c.fit {
+			full[c.id] = max(contentWidth(c.id), lipgloss.Width(c.title), c.shrinkTo)
+			cols[i].width = min(c.width, full[c.id])
+		}
+	}
+
+	if width > 0 {
+		for lineWidth(cols) > width {
+			i := nextToDrop(cols)
+			if i < 0 {
+				break
+			}
+			cols = slices.Delete(cols, i, i+1)
+		}
+		for lineWidth(cols) > width {
+			i := nextToShrink(cols)
+			if i < 0 {
+				break
+			}
+			cols[i].width--
+		}
+		left := width - lineWidth(cols)
+		for i := range cols {
+			if !cols[i].fit || left <= 0 {
+				continue
+			}
+			extra := min(left, full[cols[i].id]-cols[i].width)
+			cols[i].width += max(0, extra)
+			left -= max(0, extra)
+		}
+	}
+
+	placed := make([]placedColumn, 0, len(cols))
+	x := tableIndent
+	for _, c := range cols {
+		placed = append(placed, placedColumn{column: c, x: x})
+		x += c.width + columnGap
+	}
+	return placed
+}
+
+// lineWidth is how wide a row of cols is, indent and gaps included.
+func lineWidth(cols []column) int {
+	w := tableIndent
+	for i, c := range cols {
+		if i > 0 {
+			w += columnGap
+		}
+		w += c.width
+	}
+	return w
+}
+
+// nextToDrop returns the index of the optional column with the highest
+// drop rank, or -1 if every column is required.
+func nextToDrop(cols []column) int {
+	best := -1
+	for i, c := range cols {
+		if c.dropRank > 0 && (best < 0 || c.dropRank > cols[best].dropRank) {
+			best = i
+		}
+	}
+	return best
+}
+
+// nextToShrink returns the index of the widest column still wider than its
+// shrinkTo, or -1 if none can give up any more.
+func nextToShrink(cols []column) int {
+	best := -1
+	for i, c := range cols {
+		if c.shrinkTo > 0 && c.width > c.shrinkTo && (best < 0 || c.width > cols[best].width) {
+			best = i
+		}
+	}
+	return best
+}
+
+// tableWidth is the width of a laid-out table, from the left edge to the end
+// of its last column.
+func tableWidth(cols []placedColumn) int {
+	if len(cols) == 0 {
+		return tableIndent
+	}
+	last := cols[len(cols)-1]
+	return last.x + last.width
+}
+
+// truncate cuts s down to width terminal cells, ending it with an ellipsis
+// if anything was cut. Cells are counted as the terminal draws them, so a
+// CJK character or an emoji takes two.
+func truncate(s string, width int) string {
+	switch {
+	case lipgloss.Width(s) <= width:
+		return s
+	case width <= 0:
+		return ""
+	}
+	room := width - lipgloss.Width(ellipsis)
+	end := 0
+	for i, r := range s {
+		w := lipgloss.Width(string(r))
+		if w > room {
+			break
+		}
+		room -= w
+		end = i + len(string(r))
+	}
+	return s[:end] + ellipsis
+}
+
+// fitCell makes s exactly width cells wide, cutting it or padding it with
+// spaces on the right.
+func fitCell(s string, width int) string {
+	s = truncate(s, width)
+	if pad := width - lipgloss.Width(s); pad > 0 {
+		s += strings.Repeat(" ", pad)
+	}
+	return s
+}
@@ -3,63 +3,61 @@ package tui
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"ghamon/internal/config"
 	ghclient "ghamon/internal/github"
 )
 
 // ── Styles ────────────────────────────────────────────────────────────────────
 
+// Coloured styles are set by the theme (see theme.go).
 var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205"))
-
-	headerInfoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	colHeaderStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("33")).
-			Underline(true)
-
-	footerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	statusStyles = map[string]lipgloss.Style{
-		"success":      lipgloss.NewStyle().Foreground(lipgloss.Color("40")),
-		"failure":      lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
-		"in progress":  lipgloss.NewStyle().Foreground(lipgloss.Color("220")),
-		"queued":       lipgloss.NewStyle().Foreground(lipgloss.Color("220")),
-		"cancelled":    lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
-		"timed_out":    lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
-		"skipped":      lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
-		"no runs":      lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
-		"no workflows": lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
-		"error":        lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
-	}
-
-	defaultStatusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	changeMarkers = map[changeKind]string{
+		changeStatus:  "*",
+		changeAdded:   "+",
+		changeRemoved: "-",
+	}
+
+	selectedRowStyle = lipgloss.NewStyle().Reverse(true)
+	groupRowStyle    = lipgloss.NewStyle().Bold(true)
+
+	changedRowStyle = lipgloss.NewStyle().Bold(true)
+	removedRowStyle = lipgloss.NewStyle().Faint(true).Strikethrough(true)
 )
 
 const (
-	headerHeight = 4
+	headerHeight = 5
 	footerHeight = 1
 )
 
 // ── Messages ──────────────────────────────────────────────────────────────────
 
-type tickMsg time.Time
+type highlightExpiredMsg struct{}
 
 type fetchCompleteMsg struct {
-	runs []ghclient.WorkflowRun
-	err  error
+	runs  []ghclient.WorkflowRun
+	err   error
+	fetch int // the fetchID of the fetch
+}
+
+// RefreshMsg asks the model to fetch immediately. It is sent from outside the
+// program, e.g. when the daemon reports that a repository changed.
+type RefreshMsg struct{}
+
+// RunUpdateMsg carries a single workflow run pushed to ghamon, e.g. from a
+// webhook delivery. WorkflowFile is the run's workflow file name, if known.
+type RunUpdateMsg struct {
+	Run          ghclient.WorkflowRun
+	WorkflowFile string
 }
 
 // ── Model ─────────────────────────────────────────────────────────────────────
@@ -72,10 +70,146 @@ type Model struct {
 
 	client ghclient.Client
 
+	// ctx ends with the session, cancelling any request in flight.
+	// fetchCtx is cancelled when a fetch of the runs is superseded by the
+	// next, and fetchID tells the current fetch's result from those of
+	// earlier ones.
+	ctx         context.Context
+	fetchCtx    context.Context
+	cancelFetch context.CancelFunc
+	fetchID     int
+
 	runs     []ghclient.WorkflowRun
 	loading  bool
 	fetchErr error
 
+	// Health summary (see health.go): when every repository was last
+	// fetched without error, when the next fetch is due, and the window
+	// title last set.
+	lastRefresh time.Time
+	nextRefresh time.Time
+	title       string
+
+	// Scheduling (see schedule.go). tickID is the current schedule's
+	// generation; fetched and pending, keyed by repository, are its last
+	// fetch and whether an adaptive fetch of it is under way.
+	paused   bool
+	adaptive bool
+	tickID   int
+	fetched  map[string]time.Time
+	pending  map[string]bool
+
+	// Last known runs (see offline.go). They are saved to lastRunsPath
+	// after each refresh. A repository in stale shows runs from an earlier
+	// session or from before an outage, fetched at the time it maps to.
+	// offlineSince is set while GitHub cannot be reached.
+	lastRunsPath string
+	stale        map[string]time.Time
+	offlineSince time.Time
+
+	// Change tracking between snapshots (see diff.go).
+	changes     map[rowKey]rowChange
+	removed     []ghclient.WorkflowRun
+	seeded      bool
+	showChanged bool
+
+	// Selection and filtering (see filter.go).
+	cursor    int
+	selected  rowKey
+	filter    tableFilter
+	searching bool
+
+	// Sorting and grouping (see table.go).
+	sort      sortField
+	sortDesc  bool
+	grouped   bool
+	collapsed map[string]bool
+
+	// Timing columns (see timing.go).
+	timing     bool
+	slowFactor float64
+
+	// history is the number of runs kept in a pushed run's history (see
+	// history.go).
+	history int
+
+	// theme is the remembered theme name; SetTheme applies it.
+	theme string
+
+	// Pull request view (see pulls.go).
+	prClient     ghclient.PullRequestClient
+	view         viewMode
+	prs          []ghclient.PullRequest
+	prLoading    bool
+	prFetched    time.Time
+	prTickID     int
+	prErr        error
+	prFilter     prFilter
+	viewer       string
+	prCursor     int
+	detail       bool
+	detailCursor int
+
+	// Runner panel (see runners.go).
+	runnerClient   ghclient.RunnerClient
+	runners        []ghclient.Runner
+	runnersLoading bool
+	runnersErr     error
+	runnerCursor   int
+	stuck          map[int64][]string
+	queuedChecked  time.Time
+
+	// Deployment view (see deployments.go).
+	deployClient       ghclient.DeploymentClient
+	deployments        []ghclient.Deployment
+	deploymentsLoading bool
+	deploymentsErr     error
+	deployCursor       int
+
+	// Artifact browser (see artifacts.go).
+	artifactClient ghclient.ArtifactClient
+	artifactRun    ghclient.WorkflowRun
+	artifacts      []ghclient.Artifact
+	artifactsErr   error
+	artifactCursor int
+	unzip          bool
+	promptingDir   bool
+	downloadDir    string
+	download       *download
+
+	// Failure reasons (see failures.go).
+	failureClient   ghclient.FailureClient
+	failures        map[int64]failureResult
+	failuresPending map[int64]bool
+	expanded        map[rowKey]bool
+	failureRun      ghclient.WorkflowRun
+	failureCursor   int
+
+	// Enabling and disabling workflows (see workflows.go).
+	workflowClient ghclient.WorkflowClient
+
+	// Cache and billing usage panel (see usage.go).
+	usageClient  ghclient.UsageClient
+	usage        []ghclient.CacheUsage
+	minutes      []ghclient.WorkflowMinutes
+	usageErr     error
+	minutesErr   error
+	usageLoading bool
+	usageFetched time.Time
+	usageCursor  int
+	usageTickID  int
+
+	notice  string
+	confirm *confirmation
+
+	// Key bindings and the help overlay (see keys.go).
+	keys     KeyMap
+	showHelp bool
+
+	// When and where the previous click landed (see mouse.go).
+	lastClick     time.Time
+	lastClickLine int
+
 	prog progress.Model
 	vp   viewport.Model
 
@@ -86,13 +220,71 @@ type Model struct {
 
 // New creates a new Model.
 func New(repos []string, workflow string, rate int, client ghclient.Client) Model {
-	return Model{
-		repos:    repos,
-		Workflow: workflow,
-		Rate:     rate,
-		client:   client,
-		loading:  true,
-		prog:     progress.New(progress.WithDefaultGradient(), progress.WithoutPercentage()),
+	m := Model{
+		repos:     repos,
+		Workflow:  workflow,
+		Rate:      rate,
+		client:    client,
+		loading:   true,
+		prog:      newProgress(),
+		collapsed: make(map[string]bool),
+		keys:      DefaultKeyMap(),
+
+		failures:        make(map[int64]failureResult),
+		failuresPending: make(map[int64]bool),
+		expanded:        make(map[rowKey]bool),
+		fetched:         make(map[string]time.Time),
+		pending:         make(map[string]bool),
+		stale:           make(map[string]time.Time),
+		slowFactor:      config.DefaultSettings().SlowFactor,
+		history:         ghclient.DefaultHistory,
+		nextRefresh:     time.Now().Add(time.Duration(rate) * time.Second),
+	}
+	return m.WithContext(context.Background())
+}
+
+// WithContext returns a copy of m whose requests are made under ctx, so that
+// they are cancelled when it is, e.g. when the program ends.
+func (m Model) WithContext(ctx context.Context) Model {
+	m.ctx = ctx
+	m.fetchCtx, m.cancelFetch = context.WithCancel(ctx)
+	return m
+}
+
+// WithSettings returns a copy of m using the remembered preferences.
+func (m Model) WithSettings(s config.Settings) Model {
+	m.sort = parseSortField(s.Sort)
+	m.sortDesc = s.SortDesc
+	m.grouped = s.Grouped
+	m.collapsed = make(map[string]bool, len(s.Collapsed))
+	for _, repo := range s.Collapsed {
+		m.collapsed[repo] = true
+	}
+	m.timing = s.Timing
+	m.slowFactor = s.SlowFactor
+	m.theme = s.Theme
+	m.adaptive = s.Adaptive
+	return m
+}
+
+// Settings returns the preferences to remember for the next session.
+func (m Model) Settings() config.Settings {
+	var collapsed []string
+	for repo, c := range m.collapsed {
+		if c {
+			collapsed = append(collapsed, repo)
+		}
+	}
+	sort.Strings(collapsed)
+	return config.Settings{
+		Sort:       m.sort.String(),
+		SortDesc:   m.sortDesc,
+		Grouped:    m.grouped,
+		Collapsed:  collapsed,
+		Timing:     m.timing,
+		SlowFactor: m.slowFactor,
+		Theme:      m.theme,
+		Adaptive:   m.adaptive,
 	}
 }
 
@@ -102,37 +294,44 @@ func New(repos []string, workflow string, rate int, client ghclient.Client) Mode
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		tea.EnterAltScreen,
-		m.doFetch(),
+		m.fetchRuns(),
 		m.tick(),
+		clockTick(),
 	)
 }
 
+// tick is the first tick, one rate from start; schedule sends the rest.
 func (m Model) tick() tea.Cmd {
 	d := time.Duration(m.Rate) * time.Second
-	return tea.Tick(d, func(t time.Time) tea.Msg { return tickMsg(t) })
+	id := m.tickID
+	return tea.Tick(d, func(t time.Time) tea.Msg { return tickMsg{at: t, gen: id} })
+}
+
+// doFetch starts a new fetch of the runs, cancelling the one in flight, if
+// any.
+func (m *Model) doFetch() tea.Cmd {
+	m.cancelFetch()
+	m.fetchCtx, m.cancelFetch = context.WithCancel(m.ctx)
+	m.fetchID++
+	return m.fetchRuns()
 }
 
-func (m Model) doFetch() tea.Cmd {
+// fetchRuns fetches the runs of every repository under the current fetch.
+func (m Model) fetchRuns() tea.Cmd {
 	repos := m.repos
 	workflow := m.Workflow
 	client := m.client
+	ctx, id := m.fetchCtx, m.fetchID
 
 	return func() tea.Msg {
 		if len(repos) == 0 {
-			return fetchCompleteMsg{}
+			return fetchCompleteMsg{fetch: id}
 		}
-		ctx := context.Background()
 		var all []ghclient.WorkflowRun
 		for _, full := range repos {
-			parts := strings.SplitN(full, "/", 2)
-			runs, err := client.GetWorkflowStatuses(ctx, parts[0], parts[1], workflow)
-			if err != nil {
-				all = append(all, ghclient.WorkflowRun{Repo: full, Status: "error"})
-				continue
-			}
-			all = append(all, runs...)
+			all = append(all, fetchRepoRuns(ctx, client, full, workflow)...)
 		}
-		return fetchCompleteMsg{runs: all}
+		return fetchCompleteMsg{runs: all, fetch: id}
 	}
 }
 
@@ -158,34 +357,315 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.vp.Width = m.width
 			m.vp.Height = vpH
 		}
-		m.vp.SetContent(m.content())
+		m.render()
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "Q", "ctrl+c":
+		m.notice = ""
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		if m.promptingDir {
+			return m.updateDirPrompt(msg)
+		}
+		if m.confirm != nil {
+			return m.updateConfirm(msg)
+		}
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+		if key.Matches(msg, m.keys.help) {
+			m.showHelp = true
+			m.render()
+			m.vp.SetYOffset(0)
+			return m, nil
+		}
+		switch m.view {
+		case viewPullRequests:
+			return m.updatePullRequests(msg)
+		case viewRunners:
+			return m.updateRunners(msg)
+		case viewDeployments:
+			return m.updateDeployments(msg)
+		case viewArtifacts:
+			return m.updateArtifacts(msg)
+		case viewFailure:
+			return m.updateFailure(msg)
+		case viewUsage:
+			return m.updateUsage(msg)
+		}
+		k := m.keys
+		switch {
+		case key.Matches(msg, k.quit):
 			return m, tea.Quit
-		case "r", "R":
+		case key.Matches(msg, k.refresh):
 			m.loading = true
 			m.resetProgress()
 			cmds = append(cmds, m.doFetch())
+		case key.Matches(msg, k.pause):
+			m.paused = !m.paused
+			cmds = append(cmds, m.schedule(time.Now()))
+		case key.Matches(msg, k.faster):
+			m.Rate = stepRate(m.Rate, -1)
+			cmds = append(cmds, m.schedule(time.Now()))
+		case key.Matches(msg, k.slower):
+			m.Rate = stepRate(m.Rate, 1)
+			cmds = append(cmds, m.schedule(time.Now()))
+		case key.Matches(msg, k.adaptive):
+			m.adaptive = !m.adaptive
+			cmds = append(cmds, m.schedule(time.Now()))
+		case key.Matches(msg, k.changed):
+			m.showChanged = !m.showChanged
+		case key.Matches(msg, k.timing):
+			m.timing = !m.timing
+		case key.Matches(msg, k.pullRequests):
+			cmds = append(cmds, m.toggleView())
+		case key.Matches(msg, k.runners):
+			cmds = append(cmds, m.toggleRunners())
+		case key.Matches(msg, k.deployments):
+			cmds = append(cmds, m.toggleDeployments())
+		case key.Matches(msg, k.artifacts):
+			cmds = append(cmds, m.openArtifacts())
+		case key.Matches(msg, k.failure):
+			m.toggleFailureLine()
+		case key.Matches(msg, k.usage):
+			cmds = append(cmds, m.toggleUsage())
+		case key.Matches(msg, k.workflowState):
+			m.toggleWorkflow()
+		case key.Matches(msg, k.browser):
+			cmds = append(cmds, openInBrowser(m.selectedURL()))
+		case key.Matches(msg, k.up):
+			m.setCursor(m.cursor - 1)
+		case key.Matches(msg, k.down):
+			m.setCursor(m.cursor + 1)
+		case key.Matches(msg, k.pageUp):
+			m.setCursor(m.cursor - m.vp.Height)
+		case key.Matches(msg, k.pageDown):
+			m.setCursor(m.cursor + m.vp.Height)
+		case key.Matches(msg, k.home):
+			m.setCursor(0)
+		case key.Matches(msg, k.end):
+			m.setCursor(len(m.visibleRows()) - 1)
+		case key.Matches(msg, k.search):
+			m.searching = true
+		case key.Matches(msg, k.failing):
+			m.toggleQuickFilter(onlyFailing)
+		case key.Matches(msg, k.running):
+			m.toggleQuickFilter(onlyRunning)
+		case key.Matches(msg, k.hideSuccessful):
+			m.toggleQuickFilter(exceptSuccess)
+		case key.Matches(msg, k.back):
+			m.filter = tableFilter{}
+			m.followSelection()
+		case key.Matches(msg, k.sort):
+			m.sort = (m.sort + 1) % numSortFields
+			m.followSelection()
+		case key.Matches(msg, k.reverse):
+			m.sortDesc = !m.sortDesc
+			m.followSelection()
+		case key.Matches(msg, k.group):
+			m.grouped = !m.grouped
+			m.followSelection()
+		case key.Matches(msg, k.selectRow):
+			if !m.openFailure() && m.grouped {
+				m.toggleGroup()
+			}
+		case key.Matches(msg, k.collapseAll):
+			m.setAllCollapsed(true)
+		case key.Matches(msg, k.expandAll):
+			m.setAllCollapsed(false)
 		}
+		// Keys drive the selection, not the viewport directly.
+		m.render()
+		return m, tea.Batch(cmds...)
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 
 	case tickMsg:
+		if msg.gen != m.tickID {
+			return m, nil
+		}
+		// Only the table on screen is kept current.
+		switch m.view {
+		case viewPullRequests:
+			// Pull requests refresh on their own, slower schedule.
+			return m, m.schedule(msg.at)
+		case viewRunners:
+			cmds = append(cmds, m.refreshRunners(), m.schedule(msg.at))
+			return m, tea.Batch(cmds...)
+		case viewDeployments:
+			cmds = append(cmds, m.refreshDeployments(), m.schedule(msg.at))
+			return m, tea.Batch(cmds...)
+		case viewArtifacts, viewFailure:
+			// A run's artifacts and failure do not change once listed.
+			return m, m.schedule(msg.at)
+		case viewUsage:
+			// The usage panel refreshes on its own, slower schedule.
+			return m, m.schedule(msg.at)
+		}
+		if m.adaptive {
+			return m, m.fetchDue(msg.at)
+		}
+		if m.loading {
+			// Let a slow fetch finish rather than restart it.
+			return m, m.schedule(msg.at)
+		}
 		m.loading = true
 		m.resetProgress()
-		cmds = append(cmds, m.doFetch(), m.tick())
+		cmds = append(cmds, m.doFetch(), m.schedule(msg.at))
+
+	case repoFetchedMsg:
+		cmds = append(cmds, m.fetchedRepo(msg))
+		m.render()
+
+	case RefreshMsg:
+		if !m.loading {
+			m.loading = true
+			m.resetProgress()
+			cmds = append(cmds, m.doFetch())
+		}
 
 	case fetchCompleteMsg:
+		if msg.fetch != m.fetchID {
+			// From a fetch since cancelled.
+			break
+		}
+		m.cancelFetch()
 		m.loading = false
 		m.fetchErr = msg.err
 		if msg.err == nil {
-			m.runs = msg.runs
+			now := time.Now()
+			var runs []ghclient.WorkflowRun
+			answered := len(m.repos) == 0
+			for _, repo := range m.repos {
+				kept, ok := m.keepIfOffline(repo, runsOf(msg.runs, repo))
+				runs = append(runs, kept...)
+				answered = answered || ok
+				m.fetched[repo] = now
+			}
+			m.setOffline(answered, now)
+			cmds = append(cmds, m.setRuns(runs), m.checkQueued(runs), m.fetchFailures(runs))
+			if m.upToDate(runs) {
+				m.lastRefresh = now
+			}
+			cmds = append(cmds, m.updateTitle(), m.saveLastRuns())
+		}
+		cmds = append(cmds, m.finishProgress())
+		m.render()
+
+	case prFetchCompleteMsg:
+		m.prLoading = false
+		m.prFetched = time.Now()
+		m.prs = msg.prs
+		if m.prs == nil {
+			m.prs = []ghclient.PullRequest{}
+		}
+		m.viewer = msg.viewer
+		m.prErr = msg.err
+		m.setPRCursor(m.activeCursor())
+		cmds = append(cmds, m.finishProgress())
+		m.render()
+
+	case runnersMsg:
+		m.runnersLoading = false
+		m.runners = msg.runners
+		if m.runners == nil {
+			m.runners = []ghclient.Runner{}
+		}
+		m.runnersErr = msg.err
+		m.setRunnerCursor(m.runnerCursor)
+		cmds = append(cmds, m.finishProgress())
+		m.render()
+
+	case deploymentsMsg:
+		m.deploymentsLoading = false
+		m.deployments = msg.deployments
+		if m.deployments == nil {
+			m.deployments = []ghclient.Deployment{}
+		}
+		m.deploymentsErr = msg.err
+		m.setDeployCursor(m.deployCursor)
+		cmds = append(cmds, m.finishProgress())
+		m.render()
+
+	case artifactsMsg:
+		if msg.runID == m.artifactRun.ID {
+			m.artifacts = msg.artifacts
+			if m.artifacts == nil {
+				m.artifacts = []ghclient.Artifact{}
+			}
+			m.artifactsErr = msg.err
+			m.setArtifactCursor(m.artifactCursor)
+			m.render()
+		}
+
+	case usageMsg:
+		m.usageLoading = false
+		m.usage = msg.usage
+		if m.usage == nil {
+			m.usage = []ghclient.CacheUsage{}
+		}
+		m.minutes = msg.minutes
+		m.usageErr = msg.err
+		m.minutesErr = msg.minutesErr
+		m.usageFetched = msg.at
+		m.setUsageCursor(m.usageCursor)
+		cmds = append(cmds, m.finishProgress())
+		m.render()
+
+	case prTickMsg:
+		if msg.id == m.prTickID && m.view == viewPullRequests {
+			cmds = append(cmds, m.refreshPRs(), prTick(msg.id))
+		}
+
+	case usageTickMsg:
+		if msg.id == m.usageTickID && m.view == viewUsage {
+			cmds = append(cmds, m.refreshUsage(), usageTick(msg.id))
+		}
+
+	case workflowStateMsg:
+		cmds = append(cmds, m.updateWorkflowState(msg))
+
+	case cacheDeletedMsg:
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("deleting cache %s failed: %v", msg.key, msg.err)
+		} else {
+			m.notice = "deleted cache " + msg.key
+			cmds = append(cmds, m.refreshUsage())
 		}
-		cmds = append(cmds, m.prog.SetPercent(1.0))
-		if m.ready {
-			m.vp.SetContent(m.content())
+
+	case failureMsg:
+		delete(m.failuresPending, msg.runID)
+		m.failures[msg.runID] = msg.failureResult
+		m.render()
+
+	case downloadProgressMsg, downloadDoneMsg:
+		cmds = append(cmds, m.updateDownload(msg))
+
+	case stuckRunsMsg:
+		m.stuck = msg.stuck
+		m.render()
+
+	case noticeMsg:
+		m.notice = string(msg)
+
+	case RunUpdateMsg:
+		if runs, ok := m.applyRun(msg); ok {
+			cmds = append(cmds, m.setRuns(runs), m.checkQueued(runs), m.fetchFailures(runs), m.updateTitle())
+			m.render()
 		}
 
+	case clockMsg:
+		if m.timing {
+			m.render()
+		}
+		cmds = append(cmds, clockTick())
+
+	case highlightExpiredMsg:
+		m.changes, m.removed = expireChanges(m.changes, m.removed, time.Now())
+		m.followSelection()
+		m.render()
+
 	case progress.FrameMsg:
 		pm, cmd := m.prog.Update(msg)
 		m.prog = pm.(progress.Model)
@@ -201,6 +681,110 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// setRuns replaces the current snapshot, recording which rows changed. The
+// returned command clears the highlights once they expire.
+func (m *Model) setRuns(runs []ghclient.WorkflowRun) tea.Cmd {
+	m.changes, m.removed = diffSnapshot(m.runs, runs, m.changes, m.removed, !m.seeded, time.Now())
+	m.runs = runs
+	m.seeded = true
+	m.followSelection()
+	return tea.Tick(highlightDuration, func(time.Time) tea.Msg { return highlightExpiredMsg{} })
+}
+
+// applyRun merges a pushed run into a copy of the current snapshot and reports
+// whether anything changed. Runs for untracked repos or workflows are ignored.
+func (m Model) applyRun(msg RunUpdateMsg) ([]ghclient.WorkflowRun, bool) {
+	run := msg.Run
+	tracked := false
+	for _, r := range m.repos {
+		if strings.EqualFold(r, run.Repo) {
+			run.Repo = r
+			tracked = true
+			break
+		}
+	}
+	if !tracked {
+		return nil, false
+	}
+	if m.Workflow != "" {
+		if msg.WorkflowFile != m.Workflow && !m.tracksRun(run) {
+			return nil, false
+		}
+		run.Workflow = m.Workflow
+	}
+
+	runs := make([]ghclient.WorkflowRun, len(m.runs), len(m.runs)+1)
+	copy(runs, m.runs)
+	// A run whose default branch is not known counts as on it, so a failure
+	// elsewhere would pass for a failing default branch.
+	if run.DefaultBranch == "" {
+		for _, r := range runs {
+			if r.Repo == run.Repo && r.DefaultBranch != "" {
+				run.DefaultBranch = r.DefaultBranch
+				break
+			}
+		}
+	}
+	for i, r := range runs {
+		if r.Repo != run.Repo || r.Workflow != run.Workflow {
+			continue
+		}
+		// Deliveries can arrive out of order; keep the newest state.
+		if run.UpdatedAt.Before(r.UpdatedAt) {
+			return nil, false
+		}
+		// Pushed runs carry no history; keep the polled median and add the
+		// run to the polled statuses.
+		if run.MedianDuration == 0 {
+			run.MedianDuration = r.MedianDuration
+		}
+		if run.WorkflowID == 0 {
+			run.WorkflowID, run.WorkflowState = r.WorkflowID, r.WorkflowState
+		}
+		// Job deliveries do not say who triggered the run.
+		if run.ID == r.ID && run.Actor == "" {
+			run.Actor = r.Actor
+		}
+		run.History = pushHistory(r, run, m.history)
+		runs[i] = run
+		return runs, true
+	}
+	return append(runs, run), true
+}
+
+// tracksRun reports whether run is already shown, for deliveries that do not
+// say which workflow file they are for.
+func (m Model) tracksRun(run ghclient.WorkflowRun) bool {
+	for _, r := range m.runs {
+		if r.ID != 0 && r.ID == run.ID && r.Repo == run.Repo {
+			return true
+		}
+	}
+	return false
+}
+
+// render refreshes the viewport content and scrolls it so the selected row
+// stays visible.
+func (m *Model) render() {
+	if !m.ready {
+		return
+	}
+	if m.showHelp {
+		m.vp.SetContent(m.helpContent())
+		return
+	}
+	m.vp.SetContent(m.content())
+	line := m.cursorLine()
+	switch {
+	case m.activeCursor() == 0:
+		m.vp.SetYOffset(0)
+	case line < m.vp.YOffset:
+		m.vp.SetYOffset(line)
+	case line >= m.vp.YOffset+m.vp.Height:
+		m.vp.SetYOffset(line - m.vp.Height + 1)
+	}
+}
+
 // ── View ──────────────────────────────────────────────────────────────────────
 
 // View renders the full TUI.
@@ -217,63 +801,293 @@ func (m Model) header() string {
 		wf = "all"
 	}
 	title := titleStyle.Render("GHA Monitor (ghamon)")
-	info := headerInfoStyle.Render(fmt.Sprintf("Workflow: %-20s  Rate: %ds", wf, m.Rate))
-	return strings.Join([]string{title, info, m.prog.View()}, "\n")
+	info := headerInfoStyle.Render(fmt.Sprintf("Workflow: %-20s  Rate: %s", wf, m.rateLabel()))
+	switch m.view {
+	case viewPullRequests:
+		info = headerInfoStyle.Render(fmt.Sprintf("Pull requests: %-20s  Rate: %s", m.prFilter.describe(m.viewer), m.rateLabel()))
+	case viewRunners:
+		info = headerInfoStyle.Render(fmt.Sprintf("Runners: %-20s  Rate: %s", m.runnerSummary(), m.rateLabel()))
+	case viewDeployments:
+		info = headerInfoStyle.Render(fmt.Sprintf("Environments: %-20d  Rate: %s", len(m.deployments), m.rateLabel()))
+	case viewArtifacts:
+		run := m.artifactRun
+		info = headerInfoStyle.Render(fmt.Sprintf("Artifacts: %s / %s (run %d)", run.Repo, run.Workflow, run.ID))
+	case viewUsage:
+		info = headerInfoStyle.Render(fmt.Sprintf("Usage: %-20s  Refresh: %dm", m.usageSummary(), int(usageInterval.Minutes())))
+	case viewFailure:
+		run := m.failureRun
+		info = headerInfoStyle.Render(fmt.Sprintf("Failure: %s / %s (run %d)", run.Repo, run.Workflow, run.ID))
+		if run.Err != nil {
+			info = headerInfoStyle.Render("Error: " + run.Repo)
+		}
+	}
+	return strings.Join([]string{title, info, m.healthLine(time.Now()), m.prog.View()}, "\n")
+}
+
+// columns lays out the workflow table for the terminal width. Widths come
+// from all of rows, not just those scrolled into view, so the columns stay
+// put while scrolling.
+func (m Model) columns(rows []displayRow, now time.Time) []placedColumn {
+	cols := tableColumns(m.timing, m.showChanged, historyWidth(rows))
+	for i, c := range cols {
+		if c.field != sortDefault {
+			cols[i].title = m.columnTitle(c.title, c.field)
+		}
+	}
+	widths := map[columnID]int{colHistory: historyWidth(rows)}
+	for _, row := range rows {
+		if row.header {
+			continue
+		}
+		for _, c := range cols {
+			if c.id != colHistory {
+				widths[c.id] = max(widths[c.id], lipgloss.Width(m.cellText(c.id, row, now)))
+			}
+		}
+	}
+	return layoutColumns(cols, m.width, func(id columnID) int { return widths[id] })
+}
+
+// cellText returns the unstyled text of a row's cell in column id. Runs much
+// slower than their median are flagged in the duration column.
+func (m Model) cellText(id columnID, row displayRow, now time.Time) string {
+	r := row.run
+	switch id {
+	case colRepo:
+		if m.grouped {
+			return ""
+		}
+		return r.Repo
+	case colWorkflow:
+		if r.Err != nil {
+			return errorSummary(r)
+		}
+		if r.Workflow == "" {
+			return "-"
+		}
+		return r.Workflow
+	case colStatus:
+		return withIcon(r.DisplayStatus())
+	case colStarted:
+		return formatClock(r.StartedAt)
+	case colDuration:
+		if isSlow(r, m.slowFactor, now) {
+			return formatDuration(runDuration(r, now)) + " ⚠"
+		}
+		return formatDuration(runDuration(r, now))
+	case colQueued:
+		return formatDuration(queueWait(r))
+	case colUpdated:
+		return formatAge(r.UpdatedAt, now)
+	case colChanged:
+		if row.change.lastChanged.IsZero() {
+			return ""
+		}
+		return row.change.lastChanged.Local().Format("15:04:05")
+	case colBranch:
+		return r.HeadBranch
+	case colSHA:
+		return shortSHA(r.HeadSHA)
+	case colActor:
+		return r.Actor
+	}
+	return ""
+}
+
+// shortSHA is the first seven characters of sha, the SHA column's width.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
 }
 
 func (m Model) content() string {
 	if len(m.repos) == 0 {
 		return "  No repositories configured. Specify repos via -c or as arguments.\n"
 	}
+	switch m.view {
+	case viewPullRequests:
+		return m.prContent()
+	case viewRunners:
+		return m.runnerContent()
+	case viewDeployments:
+		return m.deploymentContent()
+	case viewArtifacts:
+		return m.artifactContent()
+	case viewFailure:
+		return m.failureContent()
+	case viewUsage:
+		return m.usageContent()
+	}
 	if m.fetchErr != nil {
 		return fmt.Sprintf("  Error: %v\n", m.fetchErr)
 	}
 	if len(m.runs) == 0 {
 		return "  Fetching data…\n"
 	}
-
-	repoW, wfW, statusW := 40, 30, 15
-	for _, r := range m.runs {
-		if len(r.Repo) > repoW {
-			repoW = len(r.Repo) + 2
-		}
-		if len(r.Workflow) > wfW {
-			wfW = len(r.Workflow) + 2
-		}
-	}
+	rows := m.visibleRows()
+	now := time.Now()
+	cols := m.columns(rows, now)
 
 	var sb strings.Builder
-	hdr := fmt.Sprintf("  %-*s  %-*s  %-*s", repoW, "REPOSITORY", wfW, "WORKFLOW", statusW, "STATUS")
-	sb.WriteString(colHeaderStyle.Render(hdr))
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = fitCell(c.title, c.width)
+	}
+	sb.WriteString(colHeaderStyle.Render(strings.Repeat(" ", tableIndent) + strings.Join(titles, strings.Repeat(" ", columnGap))))
 	sb.WriteByte('\n')
 
-	for _, r := range m.runs {
-		ds := r.DisplayStatus()
-		style, ok := statusStyles[ds]
-		if !ok {
-			style = defaultStatusStyle
+	if len(rows) == 0 {
+		sb.WriteString("  No workflows match the current filter.\n")
+	}
+
+	for i, row := range rows {
+		if row.header {
+			style := groupRowStyle
+			if i == m.cursor {
+				style = selectedRowStyle.Inherit(style)
+			}
+			fold := "▾"
+			if m.collapsed[row.run.Repo] {
+				fold = "▸"
+			}
+			title := fmt.Sprintf("%s %s", fold, row.run.Repo)
+			summary := "  " + row.summary
+			if m.width > 0 {
+				summary = truncate(summary, m.width-lipgloss.Width(title))
+			}
+			sb.WriteString(style.Render(title))
+			sb.WriteString(headerInfoStyle.Render(summary))
+			sb.WriteByte('\n')
+			continue
+		}
+
+		r := row.run
+		style := statusStyle(r.DisplayStatus())
+
+		// Every segment inherits the row style so the selection highlight
+		// spans the whole row.
+		rowStyle := lipgloss.NewStyle()
+		if i == m.cursor {
+			rowStyle = selectedRowStyle
+		}
+		marker, markerStyle := " ", rowStyle
+		cellStyle, statusStyle := rowStyle, style.Inherit(rowStyle)
+		if row.change.highlighted(now) {
+			marker = changeMarkers[row.change.kind]
+			markerStyle = changeMarkerStyles[row.change.kind].Inherit(rowStyle)
+			if row.change.kind == changeRemoved {
+				cellStyle = removedRowStyle.Inherit(rowStyle)
+				statusStyle = cellStyle
+			} else {
+				cellStyle = changedRowStyle.Inherit(rowStyle)
+				statusStyle = changedRowStyle.Inherit(statusStyle)
+			}
 		}
-		wf := r.Workflow
-		if wf == "" {
-			wf = "-"
+
+		sb.WriteString(markerStyle.Render(marker))
+		sb.WriteString(cellStyle.Render(strings.Repeat(" ", tableIndent-1)))
+		for j, c := range cols {
+			if j > 0 {
+				sb.WriteString(cellStyle.Render(strings.Repeat(" ", columnGap)))
+			}
+			text := fitCell(m.cellText(c.id, row, now), c.width)
+			switch {
+			case c.id == colStatus:
+				sb.WriteString(statusStyle.Render(text))
+			case c.id == colHistory:
+				sb.WriteString(sparkline(r.History, c.width, cellStyle) +
+					cellStyle.Render(strings.Repeat(" ", max(0, c.width-len(r.History)))))
+			case c.id == colDuration && isSlow(r, m.slowFactor, now):
+				sb.WriteString(slowStyle.Inherit(cellStyle).Render(text))
+			case c.id == colChanged:
+				sb.WriteString(rowStyle.Render(text))
+			default:
+				sb.WriteString(cellStyle.Render(text))
+			}
+		}
+		if jobs, ok := m.stuck[r.ID]; ok && r.Status == "queued" {
+			note := fmt.Sprintf("no runner available (%s)", strings.Join(jobs, ", "))
+			if m.width > 0 {
+				note = truncate(note, m.width-tableWidth(cols)-columnGap)
+			}
+			sb.WriteString(rowStyle.Render(strings.Repeat(" ", columnGap)))
+			sb.WriteString(palette.failure.Inherit(rowStyle).Render(note))
 		}
-		sb.WriteString(fmt.Sprintf("  %-*s  %-*s  ", repoW, r.Repo, wfW, wf))
-		sb.WriteString(style.Render(ds))
 		sb.WriteByte('\n')
+		if r.Failed() && m.expanded[keyOf(r)] {
+			sb.WriteString(m.failureLine(r))
+			sb.WriteByte('\n')
+		}
 	}
 	return sb.String()
 }
 
 // resetProgress replaces the progress model with a fresh one at 0%.
 // This avoids in-flight backward animation frames from a SetPercent(0) cmd
-// racing with the forward animation queued when the fetch completes.
+// racing with the forward animation queued when the fetch completes. While an
+// artifact downloads, the bar shows the download instead.
 func (m *Model) resetProgress() {
-	m.prog = progress.New(progress.WithDefaultGradient(), progress.WithoutPercentage())
+	if m.download != nil {
+		return
+	}
+	m.prog = newProgress()
 	m.prog.Width = m.width - 4
 }
 
+// finishProgress fills the progress bar once a fetch completes, unless it is
+// showing a download.
+func (m *Model) finishProgress() tea.Cmd {
+	if m.download != nil {
+		return nil
+	}
+	return m.prog.SetPercent(1.0)
+}
+
+// columnTitle adds the sort direction arrow to title when field is the
+// current sort.
+func (m Model) columnTitle(title string, field sortField) string {
+	if m.sort != field {
+		return title
+	}
+	return title + " " + m.sortArrow()
+}
+
+func (m Model) sortArrow() string {
+	if m.sortDesc {
+		return "▼"
+	}
+	return "▲"
+}
+
 func (m Model) footer() string {
-	hints := footerStyle.Render("  q: quit   r: refresh")
+	var hints string
+	switch {
+	case m.notice != "":
+		hints = "  " + m.notice
+	case m.promptingDir:
+		hints = "  save to: " + m.downloadDir + "█" + footerStyle.Render("   enter: download   esc: cancel   ctrl+u: clear")
+	case m.view == viewArtifacts && m.download != nil:
+		hints = footerStyle.Render("  downloading " + m.download.name + "…")
+	case m.confirm != nil:
+		hints = "  " + m.confirm.prompt + " " + footerStyle.Render("y: yes   any other key: no")
+	case m.showHelp:
+		hints = footerStyle.Render(footerHints([]key.Binding{m.keys.quit, relabel(m.keys.help, "close help")}))
+	case m.searching:
+		hints = "  /" + m.filter.search + "█" + footerStyle.Render("   enter: apply   esc: clear")
+	case m.view == viewWorkflows && m.filter.on():
+		hints = footerStyle.Render(footerHints(m.footerKeys())+"   ") + "[" + m.filter.label() + "]"
+	default:
+		hints = footerStyle.Render(footerHints(m.footerKeys()))
+	}
+	if m.view == viewWorkflows && (m.sort == sortUpdated || m.sort == sortDuration) {
+		hints += footerStyle.Render("   ") + fmt.Sprintf("[sort: %s %s]", m.sort, m.sortArrow())
+	}
+	if m.width > 0 && lipgloss.Width(hints) > m.width {
+		// Cut the hints short rather than wrap them.
+		return lipgloss.NewStyle().MaxWidth(m.width).Render(hints)
+	}
 	pad := m.width - lipgloss.Width(hints)
 	if pad < 0 {
 		pad = 0
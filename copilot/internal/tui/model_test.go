@@ -1,13 +1,24 @@
 package tui_test
 
 import (
+	"archive/zip"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"ghamon/internal/config"
 	ghclient "ghamon/internal/github"
 	"ghamon/internal/tui"
 )
@@ -23,6 +34,112 @@ func (m *MockGHClient) GetWorkflowStatuses(ctx context.Context, owner, repo, wor
 	return runs, args.Error(1)
 }
 
+// MockPRClient is a mock of ghclient.PullRequestClient.
+type MockPRClient struct {
+	mock.Mock
+}
+
+func (m *MockPRClient) Viewer(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPRClient) GetPullRequests(ctx context.Context, owner, repo string) ([]ghclient.PullRequest, error) {
+	args := m.Called(ctx, owner, repo)
+	prs, _ := args.Get(0).([]ghclient.PullRequest)
+	return prs, args.Error(1)
+}
+
+// MockRunnerClient is a mock of ghclient.RunnerClient.
+type MockRunnerClient struct {
+	mock.Mock
+}
+
+func (m *MockRunnerClient) GetRunners(ctx context.Context, owner, repo string) ([]ghclient.Runner, error) {
+	args := m.Called(ctx, owner, repo)
+	runners, _ := args.Get(0).([]ghclient.Runner)
+	return runners, args.Error(1)
+}
+
+func (m *MockRunnerClient) GetQueuedJobs(ctx context.Context, owner, repo string, runID int64) ([]ghclient.QueuedJob, error) {
+	args := m.Called(ctx, owner, repo, runID)
+	jobs, _ := args.Get(0).([]ghclient.QueuedJob)
+	return jobs, args.Error(1)
+}
+
+// MockDeploymentClient is a mock of ghclient.DeploymentClient.
+type MockDeploymentClient struct {
+	mock.Mock
+}
+
+func (m *MockDeploymentClient) GetDeployments(ctx context.Context, owner, repo string) ([]ghclient.Deployment, error) {
+	args := m.Called(ctx, owner, repo)
+	deps, _ := args.Get(0).([]ghclient.Deployment)
+	return deps, args.Error(1)
+}
+
+// MockArtifactClient is a mock of ghclient.ArtifactClient.
+type MockArtifactClient struct {
+	mock.Mock
+}
+
+func (m *MockArtifactClient) ListArtifacts(ctx context.Context, owner, repo string, runID int64) ([]ghclient.Artifact, error) {
+	args := m.Called(ctx, owner, repo, runID)
+	artifacts, _ := args.Get(0).([]ghclient.Artifact)
+	return artifacts, args.Error(1)
+}
+
+func (m *MockArtifactClient) DownloadArtifact(ctx context.Context, owner, repo string, id int64, w io.Writer, progress func(done, total int64)) error {
+	args := m.Called(ctx, owner, repo, id)
+	data, _ := args.Get(0).([]byte)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	progress(int64(len(data)), int64(len(data)))
+	return args.Error(1)
+}
+
+// MockFailureClient is a mock of ghclient.FailureClient.
+type MockFailureClient struct {
+	mock.Mock
+}
+
+func (m *MockFailureClient) GetFailure(ctx context.Context, owner, repo string, runID int64) (ghclient.Failure, error) {
+	args := m.Called(ctx, owner, repo, runID)
+	f, _ := args.Get(0).(ghclient.Failure)
+	return f, args.Error(1)
+}
+
+// MockUsageClient is a mock of ghclient.UsageClient.
+type MockUsageClient struct {
+	mock.Mock
+}
+
+func (m *MockUsageClient) GetCacheUsage(ctx context.Context, owner, repo string) (ghclient.CacheUsage, error) {
+	args := m.Called(ctx, owner, repo)
+	u, _ := args.Get(0).(ghclient.CacheUsage)
+	return u, args.Error(1)
+}
+
+func (m *MockUsageClient) GetBillableMinutes(ctx context.Context, owner, repo string) ([]ghclient.WorkflowMinutes, error) {
+	args := m.Called(ctx, owner, repo)
+	minutes, _ := args.Get(0).([]ghclient.WorkflowMinutes)
+	return minutes, args.Error(1)
+}
+
+func (m *MockUsageClient) DeleteCache(ctx context.Context, owner, repo string, id int64) error {
+	return m.Called(ctx, owner, repo, id).Error(0)
+}
+
+// MockWorkflowClient is a mock of ghclient.WorkflowClient.
+type MockWorkflowClient struct {
+	mock.Mock
+}
+
+func (m *MockWorkflowClient) SetWorkflowEnabled(ctx context.Context, owner, repo string, workflowID int64, enabled bool) error {
+	return m.Called(ctx, owner, repo, workflowID, enabled).Error(0)
+}
+
 func TestNew_FieldsSet(t *testing.T) {
 	repos := []string{"owner/repo1", "owner/repo2"}
 	m := tui.New(repos, "ci.yml", 30, &MockGHClient{})
@@ -50,3 +167,1124 @@ func TestModel_Update_QuitKey(t *testing.T) {
 	_, cmd := m2.(tui.Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
 	assert.NotNil(t, cmd)
 }
+
+func TestModel_Update_RunUpdate(t *testing.T) {
+	m := tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{})
+	m2, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	run := ghclient.WorkflowRun{Repo: "Owner/Repo", Workflow: "Deploy", Status: "in_progress"}
+	m3, _ := m2.(tui.Model).Update(tui.RunUpdateMsg{Run: run})
+	assert.Contains(t, m3.View(), "Deploy")
+	assert.Contains(t, m3.View(), "in progress")
+}
+
+func TestModel_Update_RunUpdate_UntrackedRepo(t *testing.T) {
+	m := tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{})
+	m2, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	run := ghclient.WorkflowRun{Repo: "other/repo", Workflow: "Deploy", Status: "in_progress"}
+	m3, _ := m2.(tui.Model).Update(tui.RunUpdateMsg{Run: run})
+	assert.NotContains(t, m3.View(), "Deploy")
+}
+
+func TestModel_Update_RunUpdate_JobForWatchedWorkflow(t *testing.T) {
+	var m tea.Model = tui.New([]string{"owner/repo"}, "deploy.yml", 30, &MockGHClient{})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 7, Repo: "owner/repo", Status: "queued"}, WorkflowFile: "deploy.yml"})
+
+	// Job deliveries carry no workflow file; one for the run already shown
+	// still updates it, and one for another run is dropped.
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 7, Repo: "owner/repo", Workflow: "Deploy", Status: "in_progress"}})
+	assert.Contains(t, m.View(), "in progress")
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 8, Repo: "owner/repo", Workflow: "Lint", Status: "queued"}})
+	assert.NotContains(t, m.View(), "queued")
+}
+
+func TestModel_Update_RunUpdate_HighlightsStatusChange(t *testing.T) {
+	m := tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{})
+	m2, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	run := ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Deploy", Status: "in_progress"}
+	m3, _ := m2.(tui.Model).Update(tui.RunUpdateMsg{Run: run})
+	assert.NotContains(t, m3.View(), "* owner/repo")
+
+	run.Status, run.Conclusion = "completed", "failure"
+	m4, cmd := m3.(tui.Model).Update(tui.RunUpdateMsg{Run: run})
+	assert.NotNil(t, cmd)
+	assert.Contains(t, m4.View(), "* owner/repo")
+}
+
+func TestModel_Update_RunUpdate_MarksNewWorkflow(t *testing.T) {
+	m := tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{})
+	m2, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	m3, _ := m2.(tui.Model).Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "queued"}})
+	m4, _ := m3.(tui.Model).Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Deploy", Status: "queued"}})
+	assert.Contains(t, m4.View(), "+ owner/repo")
+}
+
+func TestModel_Update_ToggleChangedColumn(t *testing.T) {
+	m := tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{})
+	m2, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m3, _ := m2.(tui.Model).Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "queued"}})
+	assert.NotContains(t, m3.View(), "CHANGED")
+
+	m4, _ := m3.(tui.Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	assert.Contains(t, m4.View(), "CHANGED")
+}
+
+func TestModel_View_NarrowTerminal(t *testing.T) {
+	var tm tea.Model = tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{})
+	assert.NotPanics(t, func() { tm.View() }, "before the first size")
+	for _, width := range []int{0, 10} {
+		tm, _ = tm.Update(tea.WindowSizeMsg{Width: width, Height: 20})
+		tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "queued"}})
+		assert.NotPanics(t, func() { tm.View() }, "width %d", width)
+	}
+}
+
+// readyWithRuns returns a sized model holding the given runs.
+func readyWithRuns(t *testing.T, runs ...ghclient.WorkflowRun) tui.Model {
+	t.Helper()
+	var m tea.Model = tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for _, r := range runs {
+		m, _ = m.Update(tui.RunUpdateMsg{Run: r})
+	}
+	return m.(tui.Model)
+}
+
+func sendKeys(m tui.Model, keys ...tea.KeyMsg) tui.Model {
+	var tm tea.Model = m
+	for _, k := range keys {
+		tm, _ = tm.Update(k)
+	}
+	return tm.(tui.Model)
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestModel_Search(t *testing.T) {
+	m := readyWithRuns(t,
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Deploy", Status: "completed", Conclusion: "failure"},
+	)
+
+	m = sendKeys(m, runes("/"), runes("d"), runes("p"), runes("l"))
+	v := m.View()
+	assert.Contains(t, v, "Deploy")
+	assert.NotContains(t, v, "CI ")
+	assert.Contains(t, v, "/dpl")
+
+	m = sendKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Contains(t, m.View(), "CI ")
+}
+
+func TestModel_QuickFilters(t *testing.T) {
+	m := readyWithRuns(t,
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Deploy", Status: "completed", Conclusion: "failure"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Nightly", Status: "in_progress"},
+	)
+
+	v := sendKeys(m, runes("f")).View()
+	assert.Contains(t, v, "Deploy")
+	assert.NotContains(t, v, "Nightly")
+	assert.Contains(t, v, "[failing only]")
+
+	v = sendKeys(m, runes("p")).View()
+	assert.Contains(t, v, "Nightly")
+	assert.NotContains(t, v, "Deploy")
+
+	v = sendKeys(m, runes("s")).View()
+	assert.Contains(t, v, "Nightly")
+	assert.Contains(t, v, "Deploy")
+	assert.NotContains(t, v, "CI ")
+
+	// Pressing the same quick filter again turns it off.
+	v = sendKeys(m, runes("f"), runes("f")).View()
+	assert.Contains(t, v, "CI ")
+}
+
+func TestModel_SortByStatus(t *testing.T) {
+	m := readyWithRuns(t,
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Deploy", Status: "completed", Conclusion: "failure"},
+	)
+	v := m.View()
+	assert.Less(t, strings.Index(v, "CI "), strings.Index(v, "Deploy"))
+
+	// default → repo → workflow → status
+	m = sendKeys(m, runes("o"), runes("o"), runes("o"))
+	v = m.View()
+	assert.Contains(t, v, "STATUS ▲")
+	assert.Less(t, strings.Index(v, "Deploy"), strings.Index(v, "CI "))
+	assert.Equal(t, config.Settings{Sort: "status", SlowFactor: 2}, m.Settings())
+
+	m = sendKeys(m, runes("O"))
+	v = m.View()
+	assert.Contains(t, v, "STATUS ▼")
+	assert.Less(t, strings.Index(v, "CI "), strings.Index(v, "Deploy"))
+}
+
+func TestModel_GroupByRepo(t *testing.T) {
+	var tm tea.Model = tui.New([]string{"owner/a", "owner/b"}, "", 30, &MockGHClient{})
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for _, r := range []ghclient.WorkflowRun{
+		{Repo: "owner/a", Workflow: "CI", Status: "completed", Conclusion: "success"},
+		{Repo: "owner/a", Workflow: "Deploy", Status: "completed", Conclusion: "failure"},
+		{Repo: "owner/b", Workflow: "Lint", Status: "completed", Conclusion: "success"},
+	} {
+		tm, _ = tm.Update(tui.RunUpdateMsg{Run: r})
+	}
+	m := sendKeys(tm.(tui.Model), runes("t"))
+	v := m.View()
+	assert.Contains(t, v, "▾ owner/a  1 ok, 1 failing")
+	assert.Contains(t, v, "▾ owner/b  1 ok")
+	assert.True(t, m.Settings().Grouped)
+
+	// Collapse all hides every workflow row.
+	v = sendKeys(m, runes("[")).View()
+	assert.Contains(t, v, "▸ owner/a")
+	assert.NotContains(t, v, "Deploy")
+
+	// Collapse only the selected group (the first one).
+	m = sendKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	v = m.View()
+	assert.Contains(t, v, "▸ owner/a")
+	assert.Contains(t, v, "Lint")
+	assert.NotContains(t, v, "Deploy")
+
+	// The collapsed groups are remembered.
+	assert.Equal(t, []string{"owner/a"}, m.Settings().Collapsed)
+	restored := tui.New([]string{"owner/a", "owner/b"}, "", 30, &MockGHClient{}).WithSettings(m.Settings())
+	assert.Equal(t, m.Settings(), restored.Settings())
+}
+
+func TestModel_TimingColumns(t *testing.T) {
+	now := time.Now()
+	m := readyWithRuns(t,
+		ghclient.WorkflowRun{
+			Repo: "owner/repo", Workflow: "CI", Status: "in_progress",
+			CreatedAt: now.Add(-13 * time.Minute), StartedAt: now.Add(-12 * time.Minute), UpdatedAt: now.Add(-4 * time.Minute),
+			MedianDuration: 5 * time.Minute,
+		},
+		ghclient.WorkflowRun{
+			Repo: "owner/repo", Workflow: "Lint", Status: "completed", Conclusion: "success",
+			CreatedAt: now.Add(-10 * time.Minute), StartedAt: now.Add(-10 * time.Minute), UpdatedAt: now.Add(-8 * time.Minute),
+			MedianDuration: 2 * time.Minute,
+		},
+	)
+	assert.NotContains(t, m.View(), "DURATION")
+
+	var tm tea.Model = sendKeys(m, runes("d"))
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	v := tm.View()
+	assert.Contains(t, v, "DURATION")
+	assert.Contains(t, v, "1m00s", "queue wait")
+	assert.Contains(t, v, "4m ago")
+	assert.Contains(t, v, "2m00s", "completed duration")
+	assert.Equal(t, 1, strings.Count(v, "⚠"), "only the run over twice its median is flagged")
+}
+
+func TestModel_RunUpdateKeepsMedian(t *testing.T) {
+	m := readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "queued", MedianDuration: time.Minute})
+	var tm tea.Model = sendKeys(m, runes("d"))
+	start := time.Now().Add(-5 * time.Minute)
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "in_progress", StartedAt: start, UpdatedAt: start}})
+	assert.Contains(t, tm.View(), "⚠")
+}
+
+func TestModel_UpdatedInFuture(t *testing.T) {
+	ahead := time.Now().Add(time.Minute) // GitHub's clock ahead of ours
+	m := readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success", UpdatedAt: ahead})
+	v := sendKeys(m, runes("d")).View()
+	assert.Contains(t, v, "just now")
+	assert.NotContains(t, v, "s ago")
+}
+
+func TestModel_HistorySparkline(t *testing.T) {
+	m := readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI"})
+	assert.NotContains(t, m.View(), "HISTORY")
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{
+		ID: 1, Repo: "owner/repo", Workflow: "Lint", Status: "in_progress",
+		History: []string{"success", "failure", "in progress"},
+	}})
+	v := tm.View()
+	assert.Contains(t, v, "HISTORY")
+	assert.Contains(t, v, "●●○")
+
+	// The same run finishing replaces the latest dot.
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 1, Repo: "owner/repo", Workflow: "Lint", Status: "completed", Conclusion: "success"}})
+	assert.Contains(t, tm.View(), "●●●")
+
+	// A new run adds a dot while the history is shorter than it may be.
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 2, Repo: "owner/repo", Workflow: "Lint", Status: "queued"}})
+	assert.Contains(t, tm.View(), "●●●○")
+}
+
+func TestModel_HistoryCapped(t *testing.T) {
+	var tm tea.Model = tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{}).WithHistory(3)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{
+		ID: 1, Repo: "owner/repo", Workflow: "Lint", Status: "completed", Conclusion: "failure",
+		History: []string{"success", "success", "failure"},
+	}})
+
+	// At the configured size, a new run drops the oldest dot.
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 2, Repo: "owner/repo", Workflow: "Lint", Status: "queued"}})
+	assert.Contains(t, tm.View(), "●●○ ")
+
+	// A history longer than the configured size, e.g. from a daemon keeping
+	// more runs, keeps its length.
+	tm = tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{}).WithHistory(3)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{
+		ID: 1, Repo: "owner/repo", Workflow: "Lint", Status: "completed", Conclusion: "success",
+		History: []string{"success", "success", "success", "success", "success"},
+	}})
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 2, Repo: "owner/repo", Workflow: "Lint", Status: "queued"}})
+	assert.Contains(t, tm.View(), "●●●●○ ")
+}
+
+func TestModel_HistoryOfRemovedRow(t *testing.T) {
+	client := &MockGHClient{}
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return([]ghclient.WorkflowRun{
+		{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success", History: []string{"success"}},
+		{Repo: "owner/repo", Workflow: "Nightly", Status: "completed", Conclusion: "failure",
+			History: []string{"success", "success", "success", "success", "success", "success", "success", "success", "failure"}},
+	}, nil).Once()
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return([]ghclient.WorkflowRun{
+		{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success", History: []string{"success"}},
+	}, nil)
+
+	var tm tea.Model = tui.New([]string{"owner/repo"}, "", 30, client)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	tm = refresh(tm)
+	tm = refresh(tm)
+
+	// The removed row is still shown, with a history longer than any run's.
+	v := tm.View()
+	assert.Contains(t, v, "Nightly")
+	assert.Contains(t, v, "●●●●●●●●●")
+}
+
+func TestModel_WithSettings(t *testing.T) {
+	s := config.Settings{Sort: "duration", SortDesc: true, Grouped: true, Timing: true, SlowFactor: 1.5}
+	m := tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{}).WithSettings(s)
+	assert.Equal(t, s, m.Settings())
+}
+
+func TestModel_PullRequestView(t *testing.T) {
+	prc := &MockPRClient{}
+	prc.On("Viewer", mock.Anything).Return("me", nil)
+	prc.On("GetPullRequests", mock.Anything, "owner", "repo").Return([]ghclient.PullRequest{
+		{
+			Repo: "owner/repo", Number: 7, Title: "Add feature", Author: "someone",
+			RequestedReviewers: []string{"me"}, MergeableState: "clean",
+			Checks: []ghclient.Check{{Name: "build", State: "success"}, {Name: "lint", State: "failure"}},
+		},
+		{
+			Repo: "owner/repo", Number: 8, Title: "Fix bug", Author: "me", MergeableState: "dirty",
+			Checks: []ghclient.Check{{Name: "build", State: "in progress"}},
+		},
+	}, nil)
+
+	var m tea.Model = readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "queued"})
+	m = m.(tui.Model).WithPullRequests(prc)
+	m, cmd := m.Update(runes("v"))
+	require.NotNil(t, cmd)
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+
+	v := m.View()
+	assert.Contains(t, v, "Pull requests: all open")
+	assert.Contains(t, v, "#7")
+	assert.Contains(t, v, "failure")
+	assert.Contains(t, v, "ready")
+	assert.Contains(t, v, "#8")
+	assert.Contains(t, v, "pending")
+	assert.Contains(t, v, "conflicts")
+
+	m = sendKeys(m.(tui.Model), runes("a"))
+	v = m.View()
+	assert.Contains(t, v, "authored by @me")
+	assert.NotContains(t, v, "#7")
+	assert.Contains(t, v, "#8")
+
+	m = sendKeys(m.(tui.Model), runes("a"))
+	v = m.View()
+	assert.Contains(t, v, "review requested from @me")
+	assert.Contains(t, v, "#7")
+	assert.NotContains(t, v, "#8")
+
+	// Drill down: failing checks are listed first.
+	v = sendKeys(m.(tui.Model), tea.KeyMsg{Type: tea.KeyEnter}).View()
+	assert.Contains(t, v, "2 checks, 1 failing")
+	assert.Less(t, strings.Index(v, "lint"), strings.Index(v, "build"))
+
+	// Back to the workflow view.
+	v = sendKeys(m.(tui.Model), tea.KeyMsg{Type: tea.KeyEsc}, runes("v")).View()
+	assert.Contains(t, v, "Workflow: all")
+	assert.Contains(t, v, "CI")
+	prc.AssertExpectations(t)
+}
+
+func TestModel_PullRequestErrors(t *testing.T) {
+	prc := &MockPRClient{}
+	prc.On("Viewer", mock.Anything).Return("me", nil)
+	prc.On("GetPullRequests", mock.Anything, "owner", "repo").Return([]ghclient.PullRequest{
+		{Repo: "owner/repo", Number: 7, Title: "Add feature", MergeableState: "clean",
+			Checks: []ghclient.Check{{Name: "build", State: "success"}}},
+		{Repo: "owner/repo", Number: 8, Title: "Fix bug", MergeableState: "clean",
+			Err: &ghclient.FetchError{StatusCode: 502, Message: "Bad Gateway", Retryable: true}},
+	}, nil).Once()
+
+	var m tea.Model = readyWithRuns(t).WithPullRequests(prc)
+	m, cmd := m.Update(runes("v"))
+	// A refresh while the pull requests are being fetched starts no other
+	// fetch.
+	m, again := m.Update(runes("r"))
+	assert.Nil(t, again)
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+
+	v := m.View()
+	assert.Regexp(t, `#7 .*success`, v)
+	assert.Regexp(t, `#8 .*error`, v)
+
+	v = sendKeys(m.(tui.Model), tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnter}).View()
+	assert.Contains(t, v, "Error: 502 Bad Gateway")
+	assert.Contains(t, v, "server error")
+	prc.AssertExpectations(t)
+}
+
+func TestModel_PullRequestViewNeedsClient(t *testing.T) {
+	m := sendKeys(readyWithRuns(t), runes("v"))
+	v := m.View()
+	assert.Contains(t, v, "needs GITHUB_TOKEN")
+	assert.Contains(t, v, "Workflow: all")
+}
+
+func TestModel_RunnerPanel(t *testing.T) {
+	rc := &MockRunnerClient{}
+	rc.On("GetRunners", mock.Anything, "owner", "repo").Return([]ghclient.Runner{
+		{Scope: "owner/repo", Name: "linux-1", OS: "Linux", Status: "online", Busy: true, Labels: []string{"self-hosted", "linux"}},
+		{Scope: "owner", Name: "gpu-1", OS: "Linux", Status: "offline", Labels: []string{"self-hosted", "gpu"}},
+	}, nil)
+	rc.On("GetQueuedJobs", mock.Anything, "owner", "repo", int64(42)).Return([]ghclient.QueuedJob{
+		{Name: "train", Labels: []string{"self-hosted", "gpu"}},
+	}, nil)
+
+	var m tea.Model = tui.New([]string{"owner/repo"}, "", 30, &MockGHClient{}).WithRunners(rc)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+
+	// A run queued only briefly is not looked into.
+	m, cmd := m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 41, Repo: "owner/repo", Workflow: "Lint", Status: "queued", CreatedAt: time.Now()}})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	rc.AssertNotCalled(t, "GetRunners", mock.Anything, "owner", "repo")
+
+	queuedAt := time.Now().Add(-10 * time.Minute)
+	m, cmd = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 42, Repo: "owner/repo", Workflow: "Train", Status: "queued", CreatedAt: queuedAt}})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	assert.Contains(t, m.View(), "no runner available (train)")
+
+	// Further updates soon after do not look again.
+	m, cmd = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 42, Repo: "owner/repo", Workflow: "Train", Status: "queued", CreatedAt: queuedAt, UpdatedAt: time.Now()}})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	rc.AssertNumberOfCalls(t, "GetRunners", 1)
+	assert.Contains(t, m.View(), "no runner available (train)")
+
+	// A later run of the workflow, queued just now, is not flagged by the
+	// earlier one's check.
+	m, cmd = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 43, Repo: "owner/repo", Workflow: "Train", Status: "queued", CreatedAt: time.Now(), UpdatedAt: time.Now()}})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	assert.NotContains(t, m.View(), "no runner available")
+
+	m, cmd = m.Update(runes("u"))
+	require.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+	v := m.View()
+	assert.Contains(t, v, "Runners: 0 online, 1 busy, 1 offline")
+	assert.Contains(t, v, "linux-1")
+	assert.Contains(t, v, "self-hosted, gpu")
+
+	v = sendKeys(m.(tui.Model), runes("u")).View()
+	assert.Contains(t, v, "Workflow: all")
+}
+
+// collect runs cmd and returns the messages it produces, expanding batches
+// and skipping timers.
+func collect(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			var msgs []tea.Msg
+			for _, c := range batch {
+				msgs = append(msgs, collect(c)...)
+			}
+			return msgs
+		}
+		if msg == nil {
+			return nil
+		}
+		return []tea.Msg{msg}
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestModel_DeploymentView(t *testing.T) {
+	dc := &MockDeploymentClient{}
+	dc.On("GetDeployments", mock.Anything, "owner", "repo").Return([]ghclient.Deployment{
+		{
+			Repo: "owner/repo", Environment: "production", State: "success", Ref: "main",
+			SHA: "0123456789abcdef", Creator: "deployer", UpdatedAt: time.Now().Add(-4 * time.Minute),
+		},
+		{Repo: "owner/repo", Environment: "staging", State: "no deployments"},
+	}, nil)
+
+	var m tea.Model = readyWithRuns(t).WithDeployments(dc)
+	m, cmd := m.Update(runes("e"))
+	require.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+
+	v := m.View()
+	assert.Contains(t, v, "Environments: 2")
+	assert.Contains(t, v, "production")
+	assert.Contains(t, v, "0123456 ")
+	assert.Contains(t, v, "deployer")
+	assert.Contains(t, v, "4m ago")
+	assert.Contains(t, v, "no deployments")
+
+	v = sendKeys(m.(tui.Model), tea.KeyMsg{Type: tea.KeyEsc}).View()
+	assert.Contains(t, v, "Workflow: all")
+}
+
+func TestModel_Artifacts(t *testing.T) {
+	var buf strings.Builder
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("logs/test.log")
+	require.NoError(t, err)
+	f.Write([]byte("FAIL TestSomething"))
+	require.NoError(t, zw.Close())
+
+	ac := &MockArtifactClient{}
+	ac.On("ListArtifacts", mock.Anything, "owner", "repo", int64(42)).Return([]ghclient.Artifact{
+		{ID: 7, Name: "test-logs", SizeInBytes: 1536, ExpiresAt: time.Now().Add(90 * time.Hour)},
+		{ID: 8, Name: "old-build", SizeInBytes: 10, Expired: true},
+	}, nil)
+	ac.On("DownloadArtifact", mock.Anything, "owner", "repo", int64(7)).Return([]byte(buf.String()), nil)
+
+	m := readyWithRuns(t, ghclient.WorkflowRun{ID: 42, Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure"})
+	var tm tea.Model = m.WithArtifacts(ac)
+	tm, cmd := tm.Update(runes("a"))
+	require.NotNil(t, cmd)
+	tm, _ = tm.Update(cmd())
+
+	v := tm.View()
+	assert.Contains(t, v, "Artifacts: owner/repo / CI (run 42)")
+	assert.Contains(t, v, "test-logs")
+	assert.Contains(t, v, "1.5 KB")
+	assert.Contains(t, v, "in 3d")
+	assert.Contains(t, v, "expired")
+
+	dir := filepath.Join(t.TempDir(), "out")
+	tm = sendKeys(tm.(tui.Model), runes("z"), tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyCtrlU}, runes(dir))
+	assert.Contains(t, tm.View(), "save to: "+dir)
+
+	tm, cmd = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for cmd != nil {
+		msgs := collect(cmd)
+		cmd = nil
+		for _, msg := range msgs {
+			var c tea.Cmd
+			tm, c = tm.Update(msg)
+			if c != nil {
+				cmd = tea.Batch(cmd, c)
+			}
+		}
+		if strings.Contains(tm.View(), "saved test-logs") {
+			break
+		}
+	}
+	assert.Contains(t, tm.View(), "saved test-logs to "+filepath.Join(dir, "test-logs"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "test-logs", "logs", "test.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "FAIL TestSomething", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "test-logs.zip"))
+
+	tm = sendKeys(tm.(tui.Model), runes("j"), tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, tm.View(), "old-build has expired")
+}
+
+func TestModel_ArtifactNameOutsideDirectory(t *testing.T) {
+	ac := &MockArtifactClient{}
+	ac.On("ListArtifacts", mock.Anything, "owner", "repo", int64(42)).Return([]ghclient.Artifact{
+		{ID: 7, Name: "..", SizeInBytes: 10, ExpiresAt: time.Now().Add(time.Hour)},
+	}, nil)
+
+	m := readyWithRuns(t, ghclient.WorkflowRun{ID: 42, Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure"})
+	var tm tea.Model = m.WithArtifacts(ac)
+	tm, cmd := tm.Update(runes("a"))
+	tm, _ = tm.Update(cmd())
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "out")
+	tm = sendKeys(tm.(tui.Model), tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyCtrlU}, runes(dir))
+	tm, cmd = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for cmd != nil && !strings.Contains(tm.View(), "failed") {
+		msgs := collect(cmd)
+		cmd = nil
+		for _, msg := range msgs {
+			var c tea.Cmd
+			tm, c = tm.Update(msg)
+			if c != nil {
+				cmd = tea.Batch(cmd, c)
+			}
+		}
+	}
+	assert.Contains(t, tm.View(), "not a valid file name")
+	ac.AssertNotCalled(t, "DownloadArtifact", mock.Anything, "owner", "repo", int64(7))
+	assert.NoFileExists(t, filepath.Join(parent, "...zip"))
+}
+
+func TestModel_ArtifactsNeedClient(t *testing.T) {
+	m := readyWithRuns(t, ghclient.WorkflowRun{ID: 42, Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure"})
+	v := sendKeys(m, runes("a")).View()
+	assert.Contains(t, v, "the artifact browser needs GITHUB_TOKEN")
+}
+
+func TestModel_FailureReason(t *testing.T) {
+	fc := &MockFailureClient{}
+	fc.On("GetFailure", mock.Anything, "owner", "repo", int64(42)).Return(ghclient.Failure{
+		Jobs: []ghclient.FailedJob{{Name: "test", Step: "Run go test"}},
+		Annotations: []ghclient.Annotation{
+			{Job: "test", Path: "ghamon/github_test.go", StartLine: 42, Level: "failure", Message: "expected 200"},
+			{Job: "test", Path: ".github", Level: "failure", Message: "Process completed with exit code 1."},
+		},
+	}, nil).Once()
+
+	var m tea.Model = readyWithRuns(t).WithFailures(fc)
+	m, cmd := m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{
+		ID: 42, Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure",
+	}})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	fc.AssertExpectations(t)
+	assert.NotContains(t, m.View(), "expected 200", "the reason is folded away")
+
+	m = sendKeys(m.(tui.Model), runes("x"))
+	assert.Contains(t, m.View(), "↳ test: ghamon/github_test.go:42 expected 200")
+
+	// The failure is fetched once per run.
+	m, cmd = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{
+		ID: 42, Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure",
+	}})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+
+	v := sendKeys(m.(tui.Model), tea.KeyMsg{Type: tea.KeyEnter}).View()
+	assert.Contains(t, v, "Failure: owner/repo / CI (run 42)")
+	assert.Contains(t, v, `Failed job test at step "Run go test"`)
+	assert.Contains(t, v, "ghamon/github_test.go:42")
+	assert.Contains(t, v, "Process completed with exit code 1.")
+}
+
+func TestModel_FailureReasonRetried(t *testing.T) {
+	fc := &MockFailureClient{}
+	fc.On("GetFailure", mock.Anything, "owner", "repo", int64(42)).Return(ghclient.Failure{}, errors.New("502 Bad Gateway")).Once()
+	fc.On("GetFailure", mock.Anything, "owner", "repo", int64(42)).Return(ghclient.Failure{
+		Jobs: []ghclient.FailedJob{{Name: "test", Step: "Run go test"}},
+	}, nil).Once()
+
+	run := ghclient.WorkflowRun{ID: 42, Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure"}
+	var m tea.Model = readyWithRuns(t).WithFailures(fc)
+	m, cmd := m.Update(tui.RunUpdateMsg{Run: run})
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+	m = sendKeys(m.(tui.Model), runes("x"))
+	assert.Contains(t, m.View(), "502 Bad Gateway")
+
+	// The next update tries again, and a fetched failure is kept.
+	for range 2 {
+		m, cmd = m.Update(tui.RunUpdateMsg{Run: run})
+		for _, msg := range collect(cmd) {
+			m, _ = m.Update(msg)
+		}
+	}
+	fc.AssertExpectations(t)
+	assert.Contains(t, m.View(), `test: step "Run go test" failed`)
+}
+
+func TestModel_UsagePanel(t *testing.T) {
+	uc := &MockUsageClient{}
+	uc.On("GetCacheUsage", mock.Anything, "owner", "repo").Return(ghclient.CacheUsage{
+		Repo: "owner/repo", Count: 2, SizeInBytes: 3 << 20,
+		Largest: []ghclient.Cache{
+			{ID: 1, Key: "go-mod-abc", Ref: "refs/heads/main", SizeInBytes: 2 << 20},
+			{ID: 2, Key: "node-modules-def", Ref: "refs/pull/7/merge", SizeInBytes: 1 << 20},
+		},
+	}, nil)
+	uc.On("GetBillableMinutes", mock.Anything, "owner", "repo").Return(nil, errors.New("403 Resource not accessible"))
+	uc.On("DeleteCache", mock.Anything, "owner", "repo", int64(1)).Return(nil)
+
+	var m tea.Model = readyWithRuns(t).WithUsage(uc)
+	m, cmd := m.Update(runes("m"))
+	for _, msg := range collect(cmd) {
+		m, _ = m.Update(msg)
+	}
+
+	v := m.View()
+	assert.Contains(t, v, "Usage: 2 caches, 3.0 MB")
+	assert.Contains(t, v, "go-mod-abc")
+	assert.Contains(t, v, "refs/pull/7/merge")
+	assert.Contains(t, v, "Billable minutes unavailable: 403 Resource not accessible")
+
+	m = sendKeys(m.(tui.Model), runes("D"))
+	assert.Contains(t, m.View(), "delete cache go-mod-abc from owner/repo?")
+
+	// Anything but y keeps the cache.
+	m, cmd = m.Update(runes("n"))
+	assert.Nil(t, cmd)
+	assert.NotContains(t, m.View(), "delete cache go-mod-abc")
+
+	m, cmd = sendKeys(m.(tui.Model), runes("D")).Update(runes("y"))
+	require.NotNil(t, cmd)
+	m, cmd = m.Update(cmd())
+	uc.AssertCalled(t, "DeleteCache", mock.Anything, "owner", "repo", int64(1))
+	assert.Contains(t, m.View(), "deleted cache go-mod-abc")
+	assert.NotNil(t, cmd, "the panel refreshes after a delete")
+}
+
+func TestModel_EnableWorkflow(t *testing.T) {
+	wc := &MockWorkflowClient{}
+	wc.On("SetWorkflowEnabled", mock.Anything, "owner", "repo", int64(5), true).Return(nil)
+
+	m := readyWithRuns(t, ghclient.WorkflowRun{
+		Repo: "owner/repo", Workflow: "Nightly", Status: "completed", Conclusion: "failure",
+		WorkflowID: 5, WorkflowState: ghclient.StateDisabledManually,
+	}).WithWorkflows(wc)
+	assert.Regexp(t, `Nightly +disabled`, m.View(), "the stale run's failure is not shown")
+
+	m = sendKeys(m, runes("w"))
+	assert.Contains(t, m.View(), "enable workflow Nightly in owner/repo?")
+
+	var tm tea.Model
+	tm, cmd := m.Update(runes("y"))
+	require.NotNil(t, cmd)
+	tm, cmd = tm.Update(cmd())
+	wc.AssertExpectations(t)
+	assert.Contains(t, tm.View(), "enabled Nightly in owner/repo")
+	assert.NotNil(t, cmd, "the workflows are refreshed")
+}
+
+func TestModel_HelpOverlay(t *testing.T) {
+	m := readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"})
+
+	m = sendKeys(m, runes("?"))
+	v := m.View()
+	assert.Contains(t, v, "Key bindings")
+	assert.Contains(t, v, "failing only")
+	assert.Contains(t, v, "pull requests")
+	assert.NotContains(t, v, "delete cache", "only the current view's bindings are listed")
+
+	m = sendKeys(m, runes("?"))
+	assert.NotContains(t, m.View(), "Key bindings")
+}
+
+func TestModel_RemappedKeys(t *testing.T) {
+	keys, err := tui.NewKeyMap(config.Keys{Preset: "emacs", Bindings: map[string][]string{"failing": {"F"}}})
+	require.NoError(t, err)
+	m := readyWithRuns(t,
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Deploy", Status: "completed", Conclusion: "failure"},
+	).WithKeys(keys)
+
+	m = sendKeys(m, runes("f"))
+	assert.Contains(t, m.View(), "CI", "f no longer filters")
+
+	m = sendKeys(m, runes("F"))
+	assert.NotContains(t, m.View(), "CI")
+	assert.Contains(t, m.View(), "Deploy")
+
+	m = sendKeys(m, runes("?"))
+	assert.Contains(t, m.View(), "ctrl+n")
+}
+
+func TestNewKeyMap_Errors(t *testing.T) {
+	keys, err := tui.NewKeyMap(config.Keys{Preset: "nano", Bindings: map[string][]string{"failing": {}}})
+	assert.ErrorContains(t, err, `unknown key preset "nano"`)
+	m := readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"}).WithKeys(keys)
+	assert.NotContains(t, sendKeys(m, runes("f")).View(), "failing only", "the bindings still apply")
+
+	_, err = tui.NewKeyMap(config.Keys{Bindings: map[string][]string{"explode": {"x"}}})
+	assert.ErrorContains(t, err, `unknown actions in key bindings: explode`)
+}
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+}
+
+func TestModel_MouseClick(t *testing.T) {
+	fc := &MockFailureClient{}
+	fc.On("GetFailure", mock.Anything, "owner", "repo", int64(42)).Return(ghclient.Failure{}, nil)
+
+	var m tea.Model = readyWithRuns(t).WithFailures(fc)
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Build", Status: "completed", Conclusion: "success"}})
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{
+		ID: 42, Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure",
+	}})
+
+	// The header takes four lines, then the column header, then the rows.
+	m, _ = m.Update(click(10, 6))
+	m, _ = m.Update(click(10, 6))
+	assert.Contains(t, m.View(), "Failure: owner/repo / CI (run 42)", "double-clicking a failed run opens its failure")
+
+	m = sendKeys(m.(tui.Model), tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.Update(click(10, 5))
+	m, _ = m.Update(click(10, 5))
+	assert.NotContains(t, m.View(), "Failure:", "a successful run has no failure details")
+}
+
+func TestModel_MouseSortByHeader(t *testing.T) {
+	m := readyWithRuns(t,
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Lint", Status: "completed", Conclusion: "success"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Build", Status: "completed", Conclusion: "success"},
+	)
+	var tm tea.Model = m
+	tm, _ = tm.Update(click(18, 4)) // WORKFLOW
+	v := tm.View()
+	assert.Contains(t, v, "WORKFLOW ▲")
+	assert.Less(t, strings.Index(v, "Build"), strings.Index(v, "Lint"))
+
+	tm, _ = tm.Update(click(18, 4))
+	v = tm.View()
+	assert.Contains(t, v, "WORKFLOW ▼", "clicking the sorted column reverses the order")
+	assert.Less(t, strings.Index(v, "Lint"), strings.Index(v, "Build"))
+}
+
+func TestModel_MouseWheel(t *testing.T) {
+	var runs []ghclient.WorkflowRun
+	for i := range 60 {
+		runs = append(runs, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: fmt.Sprintf("wf-%02d", i), Status: "completed", Conclusion: "success"})
+	}
+	var m tea.Model = readyWithRuns(t, runs...)
+	assert.Contains(t, m.View(), "wf-00")
+
+	m, _ = m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	v := m.View()
+	assert.NotContains(t, v, "wf-00")
+	assert.Contains(t, v, "wf-03")
+
+	m, _ = m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	assert.Contains(t, m.View(), "wf-00")
+}
+
+func TestModel_ResponsiveColumns(t *testing.T) {
+	var m tea.Model = readyWithRuns(t,
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "a very long workflow name that would wrap the table",
+			Status: "completed", Conclusion: "success", HeadBranch: "main", HeadSHA: "0123456789abcdef", Actor: "octocat"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "ビルドとテスト", Status: "in_progress", HeadBranch: "feature/x"},
+	)
+	v := m.View()
+	assert.Contains(t, v, "a very long workflow name that would wrap the table")
+	assert.Contains(t, v, "BRANCH")
+	assert.Contains(t, v, "0123456")
+	assert.Contains(t, v, "octocat")
+
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 40})
+	v = m.View()
+	for _, line := range strings.Split(v, "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 60, line)
+	}
+	assert.Contains(t, v, "a very long workflow name th…")
+	assert.Contains(t, v, "ビルドとテスト")
+	assert.NotContains(t, v, "octocat", "optional columns are hidden first")
+	assert.NotContains(t, v, "0123456")
+}
+
+func TestModel_RunUpdateKeepsActor(t *testing.T) {
+	var m tea.Model = readyWithRuns(t, ghclient.WorkflowRun{ID: 7, Repo: "owner/repo", Workflow: "Deploy", Status: "queued",
+		HeadBranch: "main", HeadSHA: "0123456789abcdef", Actor: "octocat"})
+
+	// A job delivery for the same run knows its commit but not its actor.
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 7, Repo: "owner/repo", Workflow: "Deploy", Status: "in_progress",
+		HeadBranch: "main", HeadSHA: "0123456789abcdef"}})
+	assert.Contains(t, m.View(), "octocat")
+
+	// A new run's actor is not the previous run's.
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{ID: 8, Repo: "owner/repo", Workflow: "Deploy", Status: "queued",
+		HeadBranch: "main", HeadSHA: "fedcba9876543210"}})
+	v := m.View()
+	assert.Contains(t, v, "fedcba9")
+	assert.NotContains(t, v, "octocat")
+}
+
+func TestModel_RunUpdateKeepsDefaultBranch(t *testing.T) {
+	var m tea.Model = readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success",
+		HeadBranch: "main", DefaultBranch: "main"})
+
+	// A delivery without the repository's default branch, for the row
+	// shown and for a workflow not shown yet.
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure",
+		HeadBranch: "feature"}})
+	m, _ = m.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Lint", Status: "completed", Conclusion: "failure",
+		HeadBranch: "feature"}})
+	v := m.View()
+	assert.Contains(t, v, "! WARNING", "failures off the default branch only warn")
+	assert.NotContains(t, v, "FAILING")
+}
+
+func TestModel_HealthHeader(t *testing.T) {
+	m := readyWithRuns(t,
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Lint", Status: "completed", Conclusion: "success"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure",
+			HeadBranch: "feature", DefaultBranch: "main"},
+		ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Deploy", Status: "queued"},
+	)
+	v := m.View()
+	assert.Contains(t, v, "! WARNING", "failures off the default branch only warn")
+	assert.Contains(t, v, "1 ok · 1 failing · 1 queued")
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "Lint", Status: "completed",
+		Conclusion: "failure", HeadBranch: "main", DefaultBranch: "main"}})
+	v = tm.View()
+	assert.Contains(t, v, "✗ FAILING")
+	assert.Contains(t, v, "2 failing")
+	assert.Contains(t, v, "not refreshed yet")
+}
+
+func TestModel_PauseAndRate(t *testing.T) {
+	m := readyWithRuns(t, ghclient.WorkflowRun{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"})
+	assert.Contains(t, m.View(), "Rate: 30s")
+
+	m = sendKeys(m, runes("P"))
+	assert.Contains(t, m.View(), "Rate: paused")
+	m = sendKeys(m, runes("P"))
+	assert.Contains(t, m.View(), "Rate: 30s")
+
+	m = sendKeys(m, runes("-"))
+	assert.Contains(t, m.View(), "Rate: 60s")
+	m = sendKeys(m, runes("+"), runes("+"))
+	assert.Contains(t, m.View(), "Rate: 15s")
+	m = sendKeys(m, runes("+"), runes("+"), runes("+"))
+	assert.Contains(t, m.View(), "Rate: 5s", "the rate stops at the fastest step")
+
+	m = sendKeys(m, runes("~"))
+	assert.Contains(t, m.View(), "Rate: 5s adaptive")
+	assert.True(t, m.Settings().Adaptive)
+}
+
+func TestModel_AdaptiveSchedule(t *testing.T) {
+	client := &MockGHClient{}
+	for _, repo := range []string{"one", "two"} {
+		client.On("GetWorkflowStatuses", mock.Anything, "owner", repo, "").Return([]ghclient.WorkflowRun{
+			{Repo: "owner/" + repo, Workflow: "CI", Status: "completed", Conclusion: "success"},
+		}, nil)
+	}
+	var tm tea.Model = tui.New([]string{"owner/one", "owner/two"}, "", 30, client)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	tm, cmd := tm.Update(runes("r"))
+	for _, msg := range collect(cmd) {
+		tm, _ = tm.Update(msg)
+	}
+	require.Contains(t, tm.View(), "2 ok")
+
+	// With nothing running, both repositories are next due ten times later.
+	tm, _ = tm.Update(runes("~"))
+	assert.Contains(t, tm.View(), "next in 5m00s")
+
+	tm, _ = tm.Update(tui.RunUpdateMsg{Run: ghclient.WorkflowRun{Repo: "owner/two", Workflow: "CI", Status: "in_progress",
+		UpdatedAt: time.Now()}})
+	tm, _ = tm.Update(runes("~"))
+	tm, _ = tm.Update(runes("~"))
+	assert.Contains(t, tm.View(), "next in 30s", "a repository with a run in progress is due at the rate")
+}
+
+func TestModel_ErrorRows(t *testing.T) {
+	client := &MockGHClient{}
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "ok", "").Return([]ghclient.WorkflowRun{
+		{Repo: "owner/ok", Workflow: "CI", Status: "completed", Conclusion: "success"},
+	}, nil)
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "gone", "").
+		Return(nil, &ghclient.FetchError{StatusCode: 404, Message: "Not Found"})
+	var tm tea.Model = tui.New([]string{"owner/gone", "owner/ok"}, "", 30, client)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	tm, cmd := tm.Update(runes("r"))
+	for _, msg := range collect(cmd) {
+		tm, _ = tm.Update(msg)
+	}
+
+	v := tm.View()
+	assert.Contains(t, v, "owner/ok", "the other repositories are still shown")
+	assert.Contains(t, v, "404 Not Found", "the error is shown inline")
+	assert.Contains(t, v, "1 ok · 1 error")
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	v = tm.View()
+	assert.Contains(t, v, "Error: owner/gone")
+	assert.Contains(t, v, "HTTP status: 404 Not Found")
+	assert.Contains(t, v, "Retried:     no")
+	assert.Contains(t, v, "the token cannot see it")
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Contains(t, tm.View(), "REPOSITORY")
+}
+
+func TestModel_RefreshCancelsFetch(t *testing.T) {
+	started := make(chan struct{})
+	client := &MockGHClient{}
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Run(func(args mock.Arguments) {
+		close(started)
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.Canceled).Once()
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return([]ghclient.WorkflowRun{
+		{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"},
+	}, nil)
+	var tm tea.Model = tui.New([]string{"owner/repo"}, "", 30, client)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	tm, cmd := tm.Update(runes("r"))
+	first := make(chan tea.Msg, 1)
+	go func() { first <- cmd() }()
+	<-started
+
+	tm, cmd = tm.Update(runes("r"))
+	select {
+	case msg := <-first:
+		tm, _ = tm.Update(msg)
+	case <-time.After(time.Second):
+		t.Fatal("refreshing again did not cancel the fetch in flight")
+	}
+	assert.NotContains(t, tm.View(), "context canceled", "the cancelled fetch is dropped")
+
+	for _, msg := range collect(cmd) {
+		tm, _ = tm.Update(msg)
+	}
+	assert.Contains(t, tm.View(), "1 ok")
+}
+
+func TestModel_WithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &MockGHClient{}
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.Canceled)
+	m := tui.New([]string{"owner/repo"}, "", 30, client).WithContext(ctx)
+
+	_, cmd := m.Update(runes("r"))
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ending the session did not cancel the fetch in flight")
+	}
+}
+
+// refresh presses r and feeds the results of the fetch back to m.
+func refresh(m tea.Model) tea.Model {
+	m, cmd := m.Update(runes("r"))
+	for _, msg := range collect(cmd) {
+		var next tea.Cmd
+		m, next = m.Update(msg)
+		collect(next)
+	}
+	return m
+}
+
+func TestModel_LastRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last_runs.json")
+	last := config.LastRuns{Repos: map[string]config.RepoRuns{
+		"owner/repo": {FetchedAt: time.Now().Add(-2 * time.Hour), Runs: []ghclient.WorkflowRun{
+			{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "failure"},
+		}},
+		"owner/other": {FetchedAt: time.Now(), Runs: []ghclient.WorkflowRun{
+			{Repo: "owner/other", Workflow: "Other", Status: "completed", Conclusion: "success"},
+		}},
+	}}
+	require.NoError(t, config.SaveLastRuns(path, last.Repos))
+	client := &MockGHClient{}
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return([]ghclient.WorkflowRun{
+		{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"},
+	}, nil)
+	var tm tea.Model = tui.New([]string{"owner/repo"}, "", 30, client).WithLastRuns(path, last)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	v := tm.View()
+	assert.NotContains(t, v, "Fetching data")
+	assert.Contains(t, v, "CI", "the runs of the last session are shown")
+	assert.NotContains(t, v, "Other", "only for the repositories monitored")
+	assert.Contains(t, v, "stale data from 2h00m ago")
+
+	tm = refresh(tm)
+	v = tm.View()
+	assert.Contains(t, v, "1 ok")
+	assert.NotContains(t, v, "stale")
+	assert.Contains(t, v, "refreshed ")
+
+	saved, err := config.LoadLastRuns(path)
+	require.NoError(t, err)
+	require.Len(t, saved.Repos["owner/repo"].Runs, 1)
+	assert.Equal(t, "success", saved.Repos["owner/repo"].Runs[0].Conclusion, "each refresh is saved")
+	assert.Contains(t, saved.Repos, "owner/other", "the other repositories are kept")
+}
+
+func TestModel_Offline(t *testing.T) {
+	unreachable := errors.New("dial tcp: lookup api.github.com: no such host")
+	client := &MockGHClient{}
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return([]ghclient.WorkflowRun{
+		{Repo: "owner/repo", Workflow: "CI", Status: "completed", Conclusion: "success"},
+	}, nil).Once()
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return(nil, unreachable).Twice()
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "gone", "").Return(nil, unreachable)
+	client.On("GetWorkflowStatuses", mock.Anything, "owner", "repo", "").Return(nil, &ghclient.FetchError{StatusCode: 404, Message: "Not Found"})
+
+	var tm tea.Model = tui.New([]string{"owner/repo", "owner/gone"}, "", 30, client)
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	tm = refresh(tm)
+	require.Contains(t, tm.View(), "1 ok · 1 error")
+
+	tm = refresh(tm)
+	v := tm.View()
+	assert.Contains(t, v, "1 ok · 1 error", "the runs are kept while offline")
+	assert.Contains(t, v, "no such host", "with no runs to keep the error is shown")
+	assert.Contains(t, v, "offline since")
+	assert.Contains(t, v, "stale data from")
+
+	tm = refresh(tm)
+	assert.Contains(t, tm.View(), "offline since", "still offline")
+
+	tm = refresh(tm)
+	v = tm.View()
+	assert.NotContains(t, v, "offline", "an answer from GitHub ends the offline state")
+	assert.Contains(t, v, "404 Not Found")
+	assert.NotContains(t, v, "stale")
+}
@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Two clicks on the same line within clickInterval open the row, like
+// enter. Each step of the wheel moves the view by scrollStep lines.
+const (
+	clickInterval = 400 * time.Millisecond
+	scrollStep    = 3
+)
+
+// updateMouse scrolls with the wheel, selects the clicked row and opens it on
+// a second click. Clicking a heading of the workflow table sorts by that
+// column. Mouse input is ignored while a prompt or the search has the keyboard.
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if !m.ready || m.searching || m.promptingDir || m.confirm != nil {
+		return m, nil
+	}
+	if m.showHelp {
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.vp.ScrollUp(scrollStep)
+		case tea.MouseButtonWheelDown:
+			m.vp.ScrollDown(scrollStep)
+		}
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.scroll(-scrollStep)
+		return m, nil
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.scroll(scrollStep)
+		return m, nil
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		line, ok := m.contentLineAt(msg.Y)
+		if !ok {
+			return m, nil
+		}
+		m.notice = ""
+		if line == 0 && m.view == viewWorkflows {
+			m.sortByHeading(msg.X)
+			break
+		}
+		i, ok := m.rowAt(line)
+		if !ok {
+			return m, nil
+		}
+		now := time.Now()
+		double := i == m.activeCursor() && line == m.lastClickLine && now.Sub(m.lastClick) <= clickInterval
+		m.setActiveCursor(i)
+		if double {
+			m.lastClick = time.Time{}
+			m.openDetail()
+		} else {
+			m.lastClick, m.lastClickLine = now, line
+		}
+	default:
+		return m, nil
+	}
+	m.render()
+	return m, nil
+}
+
+// contentLineAt returns the content line shown on screen row y, if y is
+// inside the viewport. The viewport starts below the header, whatever its
+// height.
+func (m Model) contentLineAt(y int) (int, bool) {
+	top := lipgloss.Height(m.header())
+	if y < top || y >= top+m.vp.Height {
+		return 0, false
+	}
+	return y - top + m.vp.YOffset, true
+}
+
+// rowAt returns the row of the current table shown on content line line.
+func (m Model) rowAt(line int) (int, bool) {
+	for i := range m.rowCount() {
+		if m.lineOf(i) == line {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// scroll moves the viewport by n lines, keeping the cursor on screen. Unlike
+// render, it leaves the viewport where the wheel put it.
+func (m *Model) scroll(n int) {
+	if n < 0 {
+		m.vp.ScrollUp(-n)
+	} else {
+		m.vp.ScrollDown(n)
+	}
+	first, last := m.vp.YOffset, m.vp.YOffset+m.vp.Height-1
+	line := m.cursorLine()
+	target := -1
+	for i := range m.rowCount() {
+		l := m.lineOf(i)
+		if line < first && l >= first {
+			target = i // the first row on screen
+			break
+		}
+		if line > last && l <= last {
+			target = i // the last row on screen, so far
+		}
+	}
+	if target >= 0 {
+		m.setActiveCursor(target)
+		m.vp.SetContent(m.content())
+	}
+}
+
+// openDetail opens the details of the selected row, as enter does.
+func (m *Model) openDetail() {
+	switch m.view {
+	case viewWorkflows:
+		if !m.openFailure() && m.grouped {
+			m.toggleGroup()
+		}
+	case viewPullRequests:
+		if _, ok := m.selectedPR(); ok && !m.detail {
+			m.detail = true
+			m.detailCursor = 0
+		}
+	}
+}
+
+// sortByHeading sorts the workflow table by the column under x. A second
+// click on the same heading flips the direction.
+func (m *Model) sortByHeading(x int) {
+	cols := m.columns(m.visibleRows(), time.Now())
+	i := slices.IndexFunc(cols, func(c placedColumn) bool {
+		return c.field != sortDefault && x >= c.x && x < c.x+c.width
+	})
+	if i < 0 {
+		return
+	}
+	field := cols[i].field
+	m.sortDesc = m.sort == field && !m.sortDesc
+	m.sort = field
+	m.followSelection()
+}
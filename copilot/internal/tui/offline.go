@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"ghamon/internal/config"
+	ghclient "ghamon/internal/github"
+)
+
+// WithLastRuns returns a copy of m showing the runs of the last session,
+// marked as stale, until their repositories are fetched again, and saving
+// its own to path after each refresh. An empty path saves nothing.
+func (m Model) WithLastRuns(path string, last config.LastRuns) Model {
+	m.lastRunsPath = path
+	var runs []ghclient.WorkflowRun
+	for _, repo := range m.repos {
+		r, ok := last.Repos[config.LastRunsKey(repo, m.Workflow)]
+		if !ok || len(r.Runs) == 0 {
+			continue
+		}
+		runs = append(runs, r.Runs...)
+		m.stale[repo] = r.FetchedAt
+	}
+	m.runs = runs
+	m.followSelection()
+	return m
+}
+
+// runsOf returns the runs of repository repo.
+func runsOf(runs []ghclient.WorkflowRun, repo string) []ghclient.WorkflowRun {
+	var of []ghclient.WorkflowRun
+	for _, r := range runs {
+		if r.Repo == repo {
+			of = append(of, r)
+		}
+	}
+	return of
+}
+
+// unreachable reports whether a repository's fetch came back as a single
+// error row without any answer from GitHub, e.g. because the network is down.
+func unreachable(runs []ghclient.WorkflowRun) bool {
+	if len(runs) != 1 || runs[0].Err == nil {
+		return false
+	}
+	e := runs[0].Err
+	return e.StatusCode == 0 && e.Retryable
+}
+
+// realRuns reports whether runs hold at least one run and no error rows, so
+// they are worth keeping while offline or saving for the next session.
+func realRuns(runs []ghclient.WorkflowRun) bool {
+	return len(runs) > 0 && !slices.ContainsFunc(runs, func(r ghclient.WorkflowRun) bool {
+		return r.Err != nil
+	})
+}
+
+// keepIfOffline returns the runs to show for repository repo given those just
+// fetched, and whether GitHub answered. While it cannot be reached, the runs
+// shown before are kept, as stale, rather than replaced by the error row. It
+// must be called before the repository's fetch time is updated.
+func (m *Model) keepIfOffline(repo string, runs []ghclient.WorkflowRun) ([]ghclient.WorkflowRun, bool) {
+	if !unreachable(runs) {
+		delete(m.stale, repo)
+		return runs, true
+	}
+	shown := runsOf(m.runs, repo)
+	if !realRuns(shown) {
+		return runs, false
+	}
+	if _, ok := m.stale[repo]; !ok {
+		m.stale[repo] = m.fetched[repo]
+	}
+	return shown, false
+}
+
+// setOffline records whether GitHub answered a fetch made at now.
+func (m *Model) setOffline(answered bool, now time.Time) {
+	switch {
+	case answered:
+		m.offlineSince = time.Time{}
+	case m.offlineSince.IsZero():
+		m.offlineSince = now
+	}
+}
+
+// staleSince is the fetch time of the oldest runs on screen that were kept
+// from before, from an earlier session or through an outage. It is the zero
+// time when nothing shown is stale.
+func (m Model) staleSince() time.Time {
+	var times []time.Time
+	for _, t := range m.stale {
+		if !t.IsZero() {
+			times = append(times, t)
+		}
+	}
+	if len(times) == 0 {
+		return time.Time{}
+	}
+	return slices.MinFunc(times, time.Time.Compare)
+}
+
+// upToDate reports whether runs, about to be shown, were all fetched without
+// error just now.
+func (m Model) upToDate(runs []ghclient.WorkflowRun) bool {
+	return countRuns(runs).byOutcome[outcomeError] == 0 && len(m.stale) == 0
+}
+
+// saveLastRuns returns a command saving the runs of the repositories fetched
+// without error, or nil if there is nothing to save or nowhere to save it.
+func (m Model) saveLastRuns() tea.Cmd {
+	if m.lastRunsPath == "" {
+		return nil
+	}
+	repos := make(map[string]config.RepoRuns)
+	for _, repo := range m.repos {
+		_, stale := m.stale[repo]
+		runs := runsOf(m.runs, repo)
+		if !stale && !m.fetched[repo].IsZero() && realRuns(runs) {
+			repos[config.LastRunsKey(repo, m.Workflow)] = config.RepoRuns{FetchedAt: m.fetched[repo], Runs: runs}
+		}
+	}
+	if len(repos) == 0 {
+		return nil
+	}
+	path := m.lastRunsPath
+	return func() tea.Msg {
+		// Saving is best effort: without the file, the next session just
+		// starts empty and fetches as before.
+		_ = config.SaveLastRuns(path, repos)
+		return nil
+	}
+}
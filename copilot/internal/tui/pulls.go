@@ -0,0 +1,475 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	ghclient "ghamon/internal/github"
+)
+
+// viewMode selects what the table lists.
+type viewMode int
+
+const (
+	viewWorkflows viewMode = iota
+	viewPullRequests
+	viewRunners
+	viewDeployments
+	viewArtifacts
+	viewFailure
+	viewUsage
+)
+
+// prFilter restricts the pull request view to the token user's pull requests.
+type prFilter int
+
+const (
+	prShowAll prFilter = iota
+	prAuthored
+	prReviewRequested
+	numPRFilters
+)
+
+var (
+	mergeLabels = map[string]string{
+		"clean":     "ready",
+		"has_hooks": "ready",
+		"dirty":     "conflicts",
+		"blocked":   "blocked",
+		"behind":    "behind",
+		"unstable":  "unstable",
+		"draft":     "draft",
+	}
+)
+
+// prInterval is how often the pull request view refreshes. Each pull request
+// costs at least one request per refresh, so the view is kept off the main
+// rate.
+const prInterval = 2 * time.Minute
+
+// prTickMsg asks the pull request view to refresh. id tells apart the tick
+// chains of successive visits to the view, so only the latest one runs.
+type prTickMsg struct {
+	id int
+}
+
+type prFetchCompleteMsg struct {
+	prs    []ghclient.PullRequest
+	viewer string
+	err    error
+}
+
+// describe returns the filter's label for the header.
+func (f prFilter) describe(viewer string) string {
+	if viewer == "" {
+		viewer = "(unknown user)"
+	} else {
+		viewer = "@" + viewer
+	}
+	switch f {
+	case prAuthored:
+		return "authored by " + viewer
+	case prReviewRequested:
+		return "review requested from " + viewer
+	}
+	return "all open"
+}
+
+func (f prFilter) matches(pr ghclient.PullRequest, viewer string) bool {
+	switch f {
+	case prAuthored:
+		return viewer != "" && strings.EqualFold(pr.Author, viewer)
+	case prReviewRequested:
+		return viewer != "" && slices.ContainsFunc(pr.RequestedReviewers, func(r string) bool {
+			return strings.EqualFold(r, viewer)
+		})
+	}
+	return true
+}
+
+// mergeLabel describes a pull request's mergeability.
+func mergeLabel(pr ghclient.PullRequest) string {
+	if pr.Draft {
+		return "draft"
+	}
+	if l, ok := mergeLabels[pr.MergeableState]; ok {
+		return l
+	}
+	return "unknown"
+}
+
+// WithPullRequests returns a copy of m that can switch to the pull request
+// view, fetching from client.
+func (m Model) WithPullRequests(client ghclient.PullRequestClient) Model {
+	m.prClient = client
+	return m
+}
+
+func (m Model) doFetchPRs() tea.Cmd {
+	repos := m.repos
+	client := m.prClient
+	ctx := m.ctx
+	viewer := m.viewer
+
+	return func() tea.Msg {
+		var errs []error
+		if viewer == "" {
+			v, err := client.Viewer(ctx)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			viewer = v
+		}
+		var all []ghclient.PullRequest
+		for _, full := range repos {
+			parts := strings.SplitN(full, "/", 2)
+			prs, err := client.GetPullRequests(ctx, parts[0], parts[1])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			all = append(all, prs...)
+		}
+		return prFetchCompleteMsg{prs: all, viewer: viewer, err: errors.Join(errs...)}
+	}
+}
+
+func prTick(id int) tea.Cmd {
+	return tea.Tick(prInterval, func(time.Time) tea.Msg { return prTickMsg{id: id} })
+}
+
+// toggleView switches between the workflow and pull request views. The pull
+// requests are refreshed on showing them if they are older than prInterval.
+func (m *Model) toggleView() tea.Cmd {
+	if m.view == viewPullRequests {
+		m.view = viewWorkflows
+		m.detail = false
+		return nil
+	}
+	if m.prClient == nil {
+		m.notice = "the pull request view needs GITHUB_TOKEN"
+		return nil
+	}
+	m.view = viewPullRequests
+	m.prTickID++
+	cmds := []tea.Cmd{prTick(m.prTickID)}
+	if time.Since(m.prFetched) >= prInterval {
+		cmds = append(cmds, m.refreshPRs())
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshPRs fetches the pull requests unless a fetch is still running.
+func (m *Model) refreshPRs() tea.Cmd {
+	if m.prLoading {
+		return nil
+	}
+	m.prLoading = true
+	m.resetProgress()
+	return m.doFetchPRs()
+}
+
+// visiblePRs returns the pull requests passing the active filter.
+func (m Model) visiblePRs() []ghclient.PullRequest {
+	var prs []ghclient.PullRequest
+	for _, pr := range m.prs {
+		if m.prFilter.matches(pr, m.viewer) {
+			prs = append(prs, pr)
+		}
+	}
+	return prs
+}
+
+// selectedPR returns the pull request under the cursor.
+func (m Model) selectedPR() (ghclient.PullRequest, bool) {
+	prs := m.visiblePRs()
+	if m.prCursor >= len(prs) {
+		return ghclient.PullRequest{}, false
+	}
+	return prs[m.prCursor], true
+}
+
+// detailChecks returns the selected pull request's checks, failing ones first.
+func (m Model) detailChecks() []ghclient.Check {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return nil
+	}
+	checks := slices.Clone(pr.Checks)
+	slices.SortStableFunc(checks, func(a, b ghclient.Check) int {
+		switch {
+		case a.Failing() && !b.Failing():
+			return -1
+		case !a.Failing() && b.Failing():
+			return 1
+		}
+		return 0
+	})
+	return checks
+}
+
+// setPRCursor moves the cursor to row i of the visible pull requests, or of
+// the checks when drilled down.
+func (m *Model) setPRCursor(i int) {
+	n := len(m.visiblePRs())
+	if m.detail {
+		n = len(m.detailChecks())
+	}
+	i = max(0, min(i, n-1))
+	if m.detail {
+		m.detailCursor = i
+		return
+	}
+	m.prCursor = i
+}
+
+// updatePullRequests handles key presses in the pull request view.
+func (m Model) updatePullRequests(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.refresh):
+		cmd = m.refreshPRs()
+	case key.Matches(msg, k.pullRequests):
+		cmd = m.toggleView()
+	case key.Matches(msg, k.up):
+		m.setPRCursor(m.activeCursor() - 1)
+	case key.Matches(msg, k.down):
+		m.setPRCursor(m.activeCursor() + 1)
+	case key.Matches(msg, k.pageUp):
+		m.setPRCursor(m.activeCursor() - m.vp.Height)
+	case key.Matches(msg, k.pageDown):
+		m.setPRCursor(m.activeCursor() + m.vp.Height)
+	case key.Matches(msg, k.home):
+		m.setPRCursor(0)
+	case key.Matches(msg, k.end):
+		m.setPRCursor(len(m.prs))
+	case key.Matches(msg, k.prFilter):
+		if !m.detail {
+			m.prFilter = (m.prFilter + 1) % numPRFilters
+			m.setPRCursor(0)
+		}
+	case key.Matches(msg, k.selectRow):
+		if _, ok := m.selectedPR(); ok && !m.detail {
+			m.detail = true
+			m.detailCursor = 0
+		} else {
+			m.detail = false
+		}
+	case key.Matches(msg, k.back):
+		m.detail = false
+	case key.Matches(msg, k.browser):
+		cmd = openInBrowser(m.selectedURL())
+	}
+	m.render()
+	return m, cmd
+}
+
+// selectedURL returns the link of the row under the cursor.
+func (m Model) selectedURL() string {
+	switch {
+	case m.view == viewWorkflows:
+		rows := m.visibleRows()
+		if m.cursor < len(rows) && !rows[m.cursor].header {
+			return rows[m.cursor].run.URL
+		}
+	case m.view == viewDeployments:
+		if m.deployCursor < len(m.deployments) {
+			return m.deployments[m.deployCursor].RunURL
+		}
+	case m.detail:
+		checks := m.detailChecks()
+		if m.detailCursor < len(checks) {
+			return checks[m.detailCursor].URL
+		}
+	default:
+		if pr, ok := m.selectedPR(); ok {
+			return pr.URL
+		}
+	}
+	return ""
+}
+
+// activeCursor returns the cursor position in the current table.
+func (m Model) activeCursor() int {
+	switch {
+	case m.view == viewWorkflows:
+		return m.cursor
+	case m.view == viewRunners:
+		return m.runnerCursor
+	case m.view == viewDeployments:
+		return m.deployCursor
+	case m.view == viewArtifacts:
+		return m.artifactCursor
+	case m.view == viewFailure:
+		return m.failureCursor
+	case m.view == viewUsage:
+		return m.usageCursor
+	case m.detail:
+		return m.detailCursor
+	}
+	return m.prCursor
+}
+
+// cursorLine returns the content line holding the cursor.
+func (m Model) cursorLine() int {
+	return m.lineOf(m.activeCursor())
+}
+
+// lineOf returns the content line holding row i of the current table.
+func (m Model) lineOf(i int) int {
+	switch {
+	case m.view == viewPullRequests && m.detail:
+		return i + 2 // below the title and column header
+	case m.view == viewFailure:
+		return i + m.failureHeight()
+	case m.view == viewUsage:
+		return i + m.usageTableHeight()
+	case m.view == viewWorkflows:
+		return i + 1 + m.failureLinesBefore(m.visibleRows(), i)
+	}
+	return i + 1 // below the column header
+}
+
+// rowCount returns the number of rows in the current table.
+func (m Model) rowCount() int {
+	switch {
+	case m.view == viewWorkflows:
+		return len(m.visibleRows())
+	case m.view == viewRunners:
+		return len(m.runners)
+	case m.view == viewDeployments:
+		return len(m.deployments)
+	case m.view == viewArtifacts:
+		return len(m.artifacts)
+	case m.view == viewFailure:
+		return len(m.failures[m.failureRun.ID].failure.Annotations)
+	case m.view == viewUsage:
+		return len(m.cacheRows())
+	case m.detail:
+		return len(m.detailChecks())
+	}
+	return len(m.visiblePRs())
+}
+
+// setActiveCursor moves the cursor of the current table to row i.
+func (m *Model) setActiveCursor(i int) {
+	switch {
+	case m.view == viewWorkflows:
+		m.setCursor(i)
+	case m.view == viewRunners:
+		m.setRunnerCursor(i)
+	case m.view == viewDeployments:
+		m.setDeployCursor(i)
+	case m.view == viewArtifacts:
+		m.setArtifactCursor(i)
+	case m.view == viewFailure:
+		m.failureCursor = max(0, min(i, m.rowCount()-1))
+	case m.view == viewUsage:
+		m.setUsageCursor(i)
+	default:
+		m.setPRCursor(i)
+	}
+}
+
+func (m Model) prContent() string {
+	if m.detail {
+		return m.checksContent()
+	}
+	if m.prs == nil {
+		if m.prErr != nil {
+			return fmt.Sprintf("  Error: %v\n", m.prErr)
+		}
+		return "  Fetching pull requests…\n"
+	}
+	prs := m.visiblePRs()
+
+	repoW, titleW, authorW, checksW := 24, 40, 12, 10
+	for _, pr := range m.prs {
+		repoW = max(repoW, len(pr.Repo)+2)
+	}
+
+	var sb strings.Builder
+	hdr := fmt.Sprintf("  %-*s  %6s  %-*s  %-*s  %-*s  %s", repoW, "REPOSITORY", "PR", titleW, "TITLE",
+		authorW, "AUTHOR", checksW, "CHECKS", "MERGE")
+	sb.WriteString(colHeaderStyle.Render(hdr))
+	sb.WriteByte('\n')
+
+	if len(prs) == 0 {
+		sb.WriteString("  No open pull requests match the current filter.\n")
+	}
+	for i, pr := range prs {
+		rowStyle := lipgloss.NewStyle()
+		if i == m.prCursor {
+			rowStyle = selectedRowStyle
+		}
+		state := pr.CheckState()
+		merge := mergeLabel(pr)
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %6s  %-*s  %-*s  ", repoW, pr.Repo,
+			fmt.Sprintf("#%d", pr.Number), titleW, truncate(pr.Title, titleW), authorW, truncate(pr.Author, authorW))))
+		sb.WriteString(statusStyle(state).Inherit(rowStyle).Render(fmt.Sprintf("%-*s", checksW, withIcon(state))))
+		sb.WriteString(rowStyle.Render("  "))
+		mergeStyle, ok := mergeStyles[merge]
+		if !ok {
+			mergeStyle = palette.text
+		}
+		sb.WriteString(mergeStyle.Inherit(rowStyle).Render(merge))
+		sb.WriteByte('\n')
+	}
+	if m.prErr != nil {
+		sb.WriteString(fmt.Sprintf("\n  Error: %v\n", m.prErr))
+	}
+	return sb.String()
+}
+
+// checksContent lists the checks of the selected pull request.
+func (m Model) checksContent() string {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return "  The pull request is no longer open.\n"
+	}
+	checks := m.detailChecks()
+
+	var sb strings.Builder
+	if pr.Err != nil {
+		sb.WriteString(fmt.Sprintf("  Error: %v\n", pr.Err))
+		if hint := pr.Err.Hint(); hint != "" {
+			sb.WriteString("  " + failureLineStyle.Render(hint) + "\n")
+		}
+		sb.WriteByte('\n')
+	}
+
+	nameW := 40
+	for _, c := range checks {
+		nameW = max(nameW, len(c.Name)+2)
+	}
+
+	failing := len(pr.FailingChecks())
+	sb.WriteString(titleStyle.Render(fmt.Sprintf("  %s#%d %s", pr.Repo, pr.Number, pr.Title)))
+	sb.WriteString(headerInfoStyle.Render(fmt.Sprintf("  %d checks, %d failing", len(checks), failing)))
+	sb.WriteByte('\n')
+	sb.WriteString(colHeaderStyle.Render(fmt.Sprintf("  %-*s  %s", nameW, "CHECK", "STATUS")))
+	sb.WriteByte('\n')
+	if len(checks) == 0 {
+		sb.WriteString("  No checks reported.\n")
+	}
+	for i, c := range checks {
+		rowStyle := lipgloss.NewStyle()
+		if i == m.detailCursor {
+			rowStyle = selectedRowStyle
+		}
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  ", nameW, c.Name)))
+		sb.WriteString(statusStyle(c.State).Inherit(rowStyle).Render(withIcon(c.State)))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
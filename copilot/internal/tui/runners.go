@@ -0,0 +1,229 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	ghclient "ghamon/internal/github"
+)
+
+const (
+	// queuedThreshold is how long a run is queued before its jobs are
+	// looked up: most runs are picked up well within it.
+	queuedThreshold = 2 * time.Minute
+
+	// queuedCheckInterval is the least time between two lookups, however
+	// often the runs are refreshed or pushed.
+	queuedCheckInterval = time.Minute
+)
+
+type runnersMsg struct {
+	runners []ghclient.Runner
+	err     error
+}
+
+// stuckRunsMsg reports the queued runs with jobs no runner can pick up, by
+// run ID, with the names of those jobs.
+type stuckRunsMsg struct {
+	stuck map[int64][]string
+}
+
+// WithRunners returns a copy of m that shows the runner panel and flags queued
+// runs no runner can serve, fetching from client.
+func (m Model) WithRunners(client ghclient.RunnerClient) Model {
+	m.runnerClient = client
+	return m
+}
+
+// doFetchRunners fetches the runners of every monitored repository. Runners
+// shared through an organization are listed once.
+func (m Model) doFetchRunners() tea.Cmd {
+	repos := m.repos
+	client := m.runnerClient
+	ctx := m.ctx
+
+	return func() tea.Msg {
+		seen := make(map[string]bool)
+		var all []ghclient.Runner
+		var errs []error
+		for _, full := range repos {
+			parts := strings.SplitN(full, "/", 2)
+			runners, err := client.GetRunners(ctx, parts[0], parts[1])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, r := range runners {
+				if k := r.Scope + "\x00" + r.Name; !seen[k] {
+					seen[k] = true
+					all = append(all, r)
+				}
+			}
+		}
+		return runnersMsg{runners: all, err: errors.Join(errs...)}
+	}
+}
+
+// checkQueued looks up the queued jobs of the runs in runs queued for longer
+// than queuedThreshold and reports those needing a self-hosted runner that no
+// online runner can serve. It costs nothing while no run has been queued that
+// long, and looks up at most once per queuedCheckInterval. Runs are flagged by
+// ID, so a later run of the same workflow starts unflagged.
+func (m *Model) checkQueued(runs []ghclient.WorkflowRun) tea.Cmd {
+	client := m.runnerClient
+	if client == nil {
+		return nil
+	}
+	now := time.Now()
+	byRepo := make(map[string][]ghclient.WorkflowRun)
+	for _, r := range runs {
+		queuedAt := r.CreatedAt
+		if queuedAt.IsZero() {
+			queuedAt = r.UpdatedAt
+		}
+		if r.Status == "queued" && r.ID != 0 && !queuedAt.IsZero() && now.Sub(queuedAt) >= queuedThreshold {
+			byRepo[r.Repo] = append(byRepo[r.Repo], r)
+		}
+	}
+	if len(byRepo) == 0 {
+		// Nothing is queued too long any more, so nothing is stuck.
+		m.stuck = nil
+		return nil
+	}
+	if now.Sub(m.queuedChecked) < queuedCheckInterval {
+		return nil
+	}
+	m.queuedChecked = now
+	ctx := m.ctx
+
+	return func() tea.Msg {
+		stuck := make(map[int64][]string)
+		for repo, queued := range byRepo {
+			parts := strings.SplitN(repo, "/", 2)
+			runners, err := client.GetRunners(ctx, parts[0], parts[1])
+			if err != nil {
+				// Without the runner list nothing can be concluded.
+				continue
+			}
+			for _, r := range queued {
+				jobs, err := client.GetQueuedJobs(ctx, parts[0], parts[1], r.ID)
+				if err != nil {
+					continue
+				}
+				for _, j := range ghclient.UnservedJobs(jobs, runners) {
+					stuck[r.ID] = append(stuck[r.ID], j.Name)
+				}
+			}
+		}
+		return stuckRunsMsg{stuck: stuck}
+	}
+}
+
+// toggleRunners switches between the workflow view and the runner panel.
+func (m *Model) toggleRunners() tea.Cmd {
+	if m.view == viewRunners {
+		m.view = viewWorkflows
+		return nil
+	}
+	if m.runnerClient == nil {
+		m.notice = "the runner panel needs GITHUB_TOKEN"
+		return nil
+	}
+	m.view = viewRunners
+	m.detail = false
+	if m.runners == nil && !m.runnersLoading {
+		return m.refreshRunners()
+	}
+	return nil
+}
+
+func (m *Model) refreshRunners() tea.Cmd {
+	m.runnersLoading = true
+	m.resetProgress()
+	return m.doFetchRunners()
+}
+
+// updateRunners handles key presses in the runner panel.
+func (m Model) updateRunners(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.refresh):
+		cmd = m.refreshRunners()
+	case key.Matches(msg, k.runners, k.back):
+		cmd = m.toggleRunners()
+	case key.Matches(msg, k.up):
+		m.setRunnerCursor(m.runnerCursor - 1)
+	case key.Matches(msg, k.down):
+		m.setRunnerCursor(m.runnerCursor + 1)
+	case key.Matches(msg, k.home):
+		m.setRunnerCursor(0)
+	case key.Matches(msg, k.end):
+		m.setRunnerCursor(len(m.runners) - 1)
+	}
+	m.render()
+	return m, cmd
+}
+
+func (m *Model) setRunnerCursor(i int) {
+	m.runnerCursor = max(0, min(i, len(m.runners)-1))
+}
+
+// runnerSummary counts runners by state for the header.
+func (m Model) runnerSummary() string {
+	counts := make(map[string]int)
+	for _, r := range m.runners {
+		counts[r.State()]++
+	}
+	return fmt.Sprintf("%d online, %d busy, %d offline", counts["online"], counts["busy"], counts["offline"])
+}
+
+func (m Model) runnerContent() string {
+	if m.runners == nil {
+		if m.runnersErr != nil {
+			return fmt.Sprintf("  Error: %v\n", m.runnersErr)
+		}
+		return "  Fetching runners…\n"
+	}
+
+	scopeW, nameW, stateW, osW := 24, 24, 8, 8
+	for _, r := range m.runners {
+		scopeW = max(scopeW, len(r.Scope)+2)
+		nameW = max(nameW, len(r.Name)+2)
+	}
+
+	var sb strings.Builder
+	hdr := fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s  %s", scopeW, "SCOPE", nameW, "RUNNER", stateW, "STATE", osW, "OS", "LABELS")
+	sb.WriteString(colHeaderStyle.Render(hdr))
+	sb.WriteByte('\n')
+	if len(m.runners) == 0 {
+		sb.WriteString("  No self-hosted runners.\n")
+	}
+	for i, r := range m.runners {
+		rowStyle := lipgloss.NewStyle()
+		if i == m.runnerCursor {
+			rowStyle = selectedRowStyle
+		}
+		state := r.State()
+		stateStyle, ok := runnerStateStyles[state]
+		if !ok {
+			stateStyle = palette.text
+		}
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %-*s  ", scopeW, r.Scope, nameW, r.Name)))
+		sb.WriteString(stateStyle.Inherit(rowStyle).Render(fmt.Sprintf("%-*s", stateW, state)))
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %s", osW, r.OS, strings.Join(r.Labels, ", "))))
+		sb.WriteByte('\n')
+	}
+	if m.runnersErr != nil {
+		sb.WriteString(fmt.Sprintf("\n  Error: %v\n", m.runnersErr))
+	}
+	return sb.String()
+}
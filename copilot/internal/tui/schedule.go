@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	ghclient "ghamon/internal/github"
+)
+
+// refreshRates are the rates, in seconds, that + and - move between.
+var refreshRates = []int{5, 10, 15, 30, 60, 120, 300, 600}
+
+// In adaptive mode a repository where nothing is running or queued is
+// fetched idleSlowdown times less often than the rate, but at least every
+// idleCap. Ticks are never closer together than tickFloor, however many
+// repositories fall due at once.
+const (
+	idleSlowdown = 10
+	idleCap      = 10 * time.Minute
+	tickFloor    = time.Second
+)
+
+// tickMsg asks the model to refresh whatever is due. gen is the schedule
+// that sent it: pausing or changing the rate starts a new schedule, and
+// ticks from an old one are ignored.
+type tickMsg struct {
+	at  time.Time
+	gen int
+}
+
+// repoFetchedMsg carries the runs of a single repository fetched on its own
+// schedule in adaptive mode.
+type repoFetchedMsg struct {
+	repo string
+	runs []ghclient.WorkflowRun
+}
+
+// stepRate moves rate by steps places in refreshRates: a negative step is
+// faster, a positive one slower. A rate that is not in the list moves to the
+// nearest listed one in that direction; at either end it stays as it is.
+func stepRate(rate, steps int) int {
+	i, found := slices.BinarySearch(refreshRates, rate)
+	switch {
+	case steps > 0 && found:
+		i += steps
+	case steps > 0:
+		i += steps - 1
+	default:
+		i += steps
+	}
+	if i < 0 || i >= len(refreshRates) {
+		return rate
+	}
+	return refreshRates[i]
+}
+
+// fetchRepoRuns fetches the runs of repository full, or a single error row
+// saying why if that fails.
+func fetchRepoRuns(ctx context.Context, client ghclient.Client, full, workflow string) []ghclient.WorkflowRun {
+	parts := strings.SplitN(full, "/", 2)
+	runs, err := client.GetWorkflowStatuses(ctx, parts[0], parts[1], workflow)
+	if err != nil {
+		return []ghclient.WorkflowRun{{Repo: full, Workflow: workflow, Status: "error", Err: ghclient.NewFetchError(err)}}
+	}
+	return runs
+}
+
+// fetchRepo fetches repository repo on its own.
+func (m Model) fetchRepo(repo string) tea.Cmd {
+	workflow := m.Workflow
+	client := m.client
+	ctx := m.ctx
+	return func() tea.Msg {
+		return repoFetchedMsg{repo: repo, runs: fetchRepoRuns(ctx, client, repo, workflow)}
+	}
+}
+
+// adaptiveNow reports whether the workflow table is refreshed adaptively. The
+// other views keep refreshing at the rate.
+func (m Model) adaptiveNow() bool {
+	return m.adaptive && m.view == viewWorkflows
+}
+
+// busy reports whether repository repo should be fetched at the full rate in
+// adaptive mode: it has a run in progress or queued, or its last fetch hit an
+// error that may go away by itself.
+func (m Model) busy(repo string) bool {
+	return slices.ContainsFunc(m.runs, func(r ghclient.WorkflowRun) bool {
+		if r.Repo != repo {
+			return false
+		}
+		return outcomeOf(r.DisplayStatus()).active() || r.Err != nil && r.Err.Retryable
+	})
+}
+
+// interval is the time between two fetches of repository repo.
+func (m Model) interval(repo string) time.Duration {
+	rate := time.Duration(m.Rate) * time.Second
+	if !m.adaptive || m.busy(repo) {
+		return rate
+	}
+	return max(rate, min(idleSlowdown*rate, idleCap))
+}
+
+// due is when repository repo falls due in adaptive mode.
+func (m Model) due(repo string) time.Time {
+	return m.fetched[repo].Add(m.interval(repo))
+}
+
+// nextDue returns the earliest time a repository not already being fetched
+// falls due, and false if every one of them is being fetched.
+func (m Model) nextDue() (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, repo := range m.repos {
+		if m.pending[repo] {
+			continue
+		}
+		if d := m.due(repo); !found || d.Before(next) {
+			next, found = d, true
+		}
+	}
+	return next, found
+}
+
+// schedule replaces the refresh schedule with one starting at now and
+// returns its first tick, or nil while paused. The tick is one rate away,
+// or in adaptive mode on the workflow table, when the next repository falls
+// due.
+func (m *Model) schedule(now time.Time) tea.Cmd {
+	m.tickID++
+	if m.paused {
+		m.nextRefresh = time.Time{}
+		return nil
+	}
+	m.nextRefresh = now.Add(time.Duration(m.Rate) * time.Second)
+	if m.adaptiveNow() {
+		if next, ok := m.nextDue(); ok {
+			m.nextRefresh = next
+		}
+		m.nextRefresh = later(m.nextRefresh, now.Add(tickFloor))
+	}
+	gen := m.tickID
+	return tea.Tick(m.nextRefresh.Sub(now), func(t time.Time) tea.Msg {
+		return tickMsg{at: t, gen: gen}
+	})
+}
+
+func later(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return b
+	}
+	return a
+}
+
+// fetchDue handles an adaptive tick: each repository that has fallen due is
+// fetched by itself, unless a full refresh is already under way, and the
+// next tick is scheduled.
+func (m *Model) fetchDue(now time.Time) tea.Cmd {
+	cmds := []tea.Cmd{}
+	for _, repo := range m.repos {
+		if m.loading || m.pending[repo] || now.Before(m.due(repo)) {
+			continue
+		}
+		m.pending[repo] = true
+		cmds = append(cmds, m.fetchRepo(repo))
+	}
+	return tea.Batch(append(cmds, m.schedule(now))...)
+}
+
+// fetchedRepo merges the runs of a repository fetched on its own into the
+// snapshot. They are dropped if a full refresh is running, as it fetches the
+// repository again.
+func (m *Model) fetchedRepo(msg repoFetchedMsg) tea.Cmd {
+	delete(m.pending, msg.repo)
+	if m.loading {
+		return nil
+	}
+	now := time.Now()
+	kept, answered := m.keepIfOffline(msg.repo, msg.runs)
+	m.setOffline(answered, now)
+	m.fetched[msg.repo] = now
+	runs := m.mergeRepo(msg.repo, kept)
+	if m.upToDate(runs) {
+		m.lastRefresh = now
+	}
+	return tea.Batch(m.setRuns(runs), m.checkQueued(runs), m.fetchFailures(runs), m.updateTitle(), m.saveLastRuns())
+}
+
+// mergeRepo returns a copy of the snapshot with the runs of repository repo
+// replaced by runs, keeping the repositories in order.
+func (m Model) mergeRepo(repo string, runs []ghclient.WorkflowRun) []ghclient.WorkflowRun {
+	merged := make([]ghclient.WorkflowRun, 0, len(m.runs)+len(runs))
+	for _, r := range m.repos {
+		if r == repo {
+			merged = append(merged, runs...)
+			continue
+		}
+		for _, run := range m.runs {
+			if run.Repo == r {
+				merged = append(merged, run)
+			}
+		}
+	}
+	return merged
+}
+
+// rateLabel is the refresh rate as shown in the header: "30s", "30s
+// adaptive", or "paused".
+func (m Model) rateLabel() string {
+	if m.paused {
+		return "paused"
+	}
+	label := fmt.Sprintf("%ds", m.Rate)
+	if m.adaptive {
+		label += " adaptive"
+	}
+	return label
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	ghclient "ghamon/internal/github"
+)
+
+// sortField is an order the workflow table can be shown in; o cycles through
+// them and the choice is saved in the settings by name.
+type sortField int
+
+const (
+	sortDefault sortField = iota // configuration order, then API order
+	sortRepo
+	sortWorkflow
+	sortStatus
+	sortUpdated
+	sortDuration
+	numSortFields
+)
+
+// sortOrders name each sortField and compare two runs by it. The default
+// order has no comparison: rows stay as fetched.
+var sortOrders = [numSortFields]struct {
+	name    string
+	compare func(a, b ghclient.WorkflowRun, now time.Time) int
+}{
+	sortDefault: {name: "default"},
+	sortRepo: {"repo", func(a, b ghclient.WorkflowRun, _ time.Time) int {
+		return cmp.Or(compareFold(a.Repo, b.Repo), compareFold(a.Workflow, b.Workflow))
+	}},
+	sortWorkflow: {"workflow", func(a, b ghclient.WorkflowRun, _ time.Time) int {
+		return compareFold(a.Workflow, b.Workflow)
+	}},
+	sortStatus: {"status", func(a, b ghclient.WorkflowRun, _ time.Time) int {
+		return cmp.Compare(attention(a.DisplayStatus()), attention(b.DisplayStatus()))
+	}},
+	sortUpdated: {"updated", func(a, b ghclient.WorkflowRun, _ time.Time) int {
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}},
+	sortDuration: {"duration", func(a, b ghclient.WorkflowRun, now time.Time) int {
+		return cmp.Compare(runDuration(a, now), runDuration(b, now))
+	}},
+}
+
+func (f sortField) String() string {
+	return sortOrders[f].name
+}
+
+// parseSortField returns the sortField saved as name. Unknown names, e.g.
+// from a newer version, fall back to the default order.
+func parseSortField(name string) sortField {
+	for f, o := range sortOrders {
+		if o.name == name {
+			return sortField(f)
+		}
+	}
+	return sortDefault
+}
+
+func compareFold(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// attention ranks a display status for the status order: failures and
+// errors first, then runs that were stopped, runs not finished yet, anything
+// unusual, workflows with nothing to show and successes last.
+func attention(displayStatus string) int {
+	switch o := outcomeOf(displayStatus); {
+	case o.failing():
+		return 0
+	case displayStatus == "cancelled", displayStatus == "action_required":
+		return 1
+	case o.active():
+		return 2
+	case o == outcomeSuccess:
+		return 5
+	case displayStatus == "skipped", displayStatus == "no runs", displayStatus == "no workflows":
+		return 4
+	}
+	return 3
+}
+
+// runDuration is the time from a run's start to its last update, or to now
+// while it is not completed. A run that has not started has none.
+func runDuration(r ghclient.WorkflowRun, now time.Time) time.Duration {
+	switch {
+	case r.StartedAt.IsZero():
+		return 0
+	case r.Status == "completed":
+		return r.UpdatedAt.Sub(r.StartedAt)
+	}
+	return now.Sub(r.StartedAt)
+}
+
+// sortRows orders rows by field, reversed if desc. The sort is stable, so
+// rows that compare equal stay in fetch order.
+func sortRows(rows []displayRow, field sortField, desc bool, now time.Time) {
+	compare := sortOrders[field].compare
+	if compare == nil {
+		return
+	}
+	slices.SortStableFunc(rows, func(a, b displayRow) int {
+		if desc {
+			return compare(b.run, a.run, now)
+		}
+		return compare(a.run, b.run, now)
+	})
+}
+
+// groupRows puts a header above the rows of each repository, keeping the
+// repositories in the order their first row had in rows. A collapsed
+// repository shows only its header. The header sums up all of the
+// repository's runs, including those the filter hides.
+func groupRows(rows []displayRow, all []ghclient.WorkflowRun, collapsed map[string]bool) []displayRow {
+	rank := make(map[string]int)
+	for _, r := range rows {
+		if _, ok := rank[r.run.Repo]; !ok {
+			rank[r.run.Repo] = len(rank)
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b displayRow) int {
+		return cmp.Compare(rank[a.run.Repo], rank[b.run.Repo])
+	})
+
+	grouped := make([]displayRow, 0, len(rows)+len(rank))
+	for i, r := range rows {
+		repo := r.run.Repo
+		if i == 0 || repo != rows[i-1].run.Repo {
+			grouped = append(grouped, displayRow{
+				run:     ghclient.WorkflowRun{Repo: repo},
+				header:  true,
+				summary: summarize(repo, all),
+			})
+		}
+		if !collapsed[repo] {
+			grouped = append(grouped, r)
+		}
+	}
+	return grouped
+}
+
+// summarize counts the runs of repo in all for its group header, e.g.
+// "3 ok, 1 failing". Fetch errors count as failing and queued runs as
+// running.
+func summarize(repo string, all []ghclient.WorkflowRun) string {
+	labels := [...]string{"ok", "failing", "running", "other"}
+	var counts [len(labels)]int
+	for _, r := range all {
+		if r.Repo != repo {
+			continue
+		}
+		switch o := outcomeOf(r.DisplayStatus()); {
+		case o == outcomeSuccess:
+			counts[0]++
+		case o.failing():
+			counts[1]++
+		case o.active():
+			counts[2]++
+		default:
+			counts[3]++
+		}
+	}
+	var parts []string
+	for i, n := range counts {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, labels[i]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+
+	"ghamon/internal/config"
+)
+
+// palette is the current theme as plain foreground styles; the styles
+// below, and statusStyle, are built from it.
+var palette struct {
+	title, muted, accent, text         lipgloss.Style
+	success, failure, running, warning lipgloss.Style
+}
+
+// Styles derived from the theme. SetTheme assigns them.
+var (
+	titleStyle      lipgloss.Style
+	headerInfoStyle lipgloss.Style
+	colHeaderStyle  lipgloss.Style
+	footerStyle     lipgloss.Style
+
+	changeMarkerStyles map[changeKind]lipgloss.Style
+	slowStyle          lipgloss.Style
+
+	mergeStyles       map[string]lipgloss.Style
+	runnerStateStyles map[string]lipgloss.Style
+	annotationStyles  map[string]lipgloss.Style
+	failureLineStyle  lipgloss.Style
+
+	progressColor string
+	showIcons     bool
+)
+
+func init() {
+	t, _ := config.BuiltinTheme(config.DefaultTheme)
+	SetTheme(t)
+}
+
+// foreground returns a style in colour c, or an unstyled one if c is "".
+func foreground(c string) lipgloss.Style {
+	if c == "" {
+		return lipgloss.NewStyle()
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+}
+
+// SetTheme draws the TUI in theme t from now on. main calls it once, before
+// the program starts.
+func SetTheme(t config.Theme) {
+	p := &palette
+	p.title, p.muted, p.accent, p.text = foreground(t.Title), foreground(t.Muted), foreground(t.Accent), foreground(t.Text)
+	p.success, p.failure, p.running, p.warning = foreground(t.Success), foreground(t.Failure), foreground(t.Running), foreground(t.Warning)
+
+	titleStyle = p.title.Bold(true)
+	headerInfoStyle = p.muted
+	colHeaderStyle = p.accent.Bold(true).Underline(true)
+	footerStyle = p.muted
+
+	changeMarkerStyles = map[changeKind]lipgloss.Style{
+		changeStatus:  p.running.Bold(true),
+		changeAdded:   p.success.Bold(true),
+		changeRemoved: p.failure.Bold(true),
+	}
+	slowStyle = p.warning.Bold(true)
+
+	mergeStyles = map[string]lipgloss.Style{
+		"ready":     p.success,
+		"conflicts": p.failure,
+		"blocked":   p.warning,
+		"behind":    p.running,
+		"unstable":  p.running,
+	}
+	runnerStateStyles = map[string]lipgloss.Style{
+		"online":  p.success,
+		"busy":    p.running,
+		"offline": p.failure,
+	}
+	annotationStyles = map[string]lipgloss.Style{
+		"failure": p.failure,
+		"warning": p.running,
+		"notice":  p.muted,
+	}
+	failureLineStyle = p.failure
+
+	progressColor = t.Accent
+	showIcons = t.Icons
+}
+
+// statusStyle returns the colour of a display status, or of a check or
+// deployment state, which share the same names.
+func statusStyle(status string) lipgloss.Style {
+	switch o := outcomeOf(status); {
+	case o == outcomeSuccess:
+		return palette.success
+	case o.failing():
+		return palette.failure
+	case o.active():
+		return palette.running
+	}
+	switch status {
+	case "cancelled":
+		return palette.warning
+	case "skipped", "no runs", "no workflows", "none", "inactive":
+		return palette.muted
+	case "disabled", "auto-disabled":
+		return palette.muted.Italic(true)
+	}
+	return palette.text
+}
+
+// newProgress returns an empty progress bar in the theme's accent colour.
+func newProgress() progress.Model {
+	return progress.New(progress.WithSolidFill(progressColor), progress.WithoutPercentage())
+}
+
+// statusIcon returns the mark of a display status's outcome, or a space if
+// it has none, so statuses line up whether or not they have one.
+func statusIcon(status string) string {
+	switch o := outcomeOf(status); {
+	case o == outcomeSuccess:
+		return "✓"
+	case o.failing():
+		return "✗"
+	case o == outcomeRunning:
+		return "●"
+	case o == outcomeWaiting:
+		return "○"
+	}
+	return " "
+}
+
+// withIcon returns status as shown in a cell: after its icon, if the theme
+// shows icons.
+func withIcon(status string) string {
+	if !showIcons {
+		return status
+	}
+	return statusIcon(status) + " " + status
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	ghclient "ghamon/internal/github"
+)
+
+// clockInterval is how often the timing columns are redrawn so running
+// durations and ages stay current between refreshes.
+const clockInterval = time.Second
+
+type clockMsg struct{}
+
+func clockTick() tea.Cmd {
+	return tea.Tick(clockInterval, func(time.Time) tea.Msg { return clockMsg{} })
+}
+
+// queueWait is the time a run spent queued, from its creation until a runner
+// started it; zero until it has started.
+func queueWait(r ghclient.WorkflowRun) time.Duration {
+	if r.CreatedAt.IsZero() || r.StartedAt.IsZero() {
+		return 0
+	}
+	return r.StartedAt.Sub(r.CreatedAt)
+}
+
+// isSlow reports whether run r has been going for more than factor times the
+// median of its workflow's recent successful runs. Without a median, or with
+// the slow flag turned off (factor <= 0), no run is slow.
+func isSlow(r ghclient.WorkflowRun, factor float64, now time.Time) bool {
+	if factor <= 0 || r.MedianDuration <= 0 {
+		return false
+	}
+	return runDuration(r, now) > time.Duration(factor*float64(r.MedianDuration))
+}
+
+// formatDuration shows d to the second in its two largest units, e.g.
+// "45s", "4m12s" or "1h02m", and "-" for none.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d <= 0 {
+		return "-"
+	}
+	h, m, sec := int(d/time.Hour), int(d/time.Minute)%60, int(d/time.Second)%60
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh%02dm", h, m)
+	case m > 0:
+		return fmt.Sprintf("%dm%02ds", m, sec)
+	}
+	return fmt.Sprintf("%ds", sec)
+}
+
+// ageUnits are the units an age is given in, largest first.
+var ageUnits = []struct {
+	size time.Duration
+	unit string
+}{{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}}
+
+// formatAge shows the time since t in its largest whole unit, e.g. "4m ago"
+// or "3d ago", and "-" if t is unknown. Under a second, or a t ahead of now
+// as GitHub's clock may be, is "just now".
+func formatAge(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := now.Sub(t)
+	if d < time.Second {
+		return "just now"
+	}
+	for _, u := range ageUnits[:len(ageUnits)-1] {
+		if d >= u.size {
+			return fmt.Sprintf("%d%s ago", d/u.size, u.unit)
+		}
+	}
+	return fmt.Sprintf("%ds ago", d/time.Second)
+}
+
+// formatClock shows the local time of day of t, e.g. "14:03:11".
+func formatClock(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.TimeOnly)
+}
@@ -0,0 +1,261 @@
+package tui
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	ghclient "ghamon/internal/github"
+)
+
+// usageInterval is how often the usage panel refreshes. Usage changes slowly
+// and billing costs a request per workflow, so it is kept off the main rate.
+const usageInterval = 5 * time.Minute
+
+type usageMsg struct {
+	usage      []ghclient.CacheUsage
+	minutes    []ghclient.WorkflowMinutes
+	err        error
+	minutesErr error
+	at         time.Time
+}
+
+// usageTickMsg asks the usage panel to refresh. id tells apart the tick
+// chains of successive visits to the panel, so only the latest one runs.
+type usageTickMsg struct {
+	id int
+}
+
+type cacheDeletedMsg struct {
+	key string
+	err error
+}
+
+// cacheRow is a cache entry listed in the usage panel.
+type cacheRow struct {
+	repo string
+	ghclient.Cache
+}
+
+// WithUsage returns a copy of m that shows the cache and billing usage panel,
+// fetching from client.
+func (m Model) WithUsage(client ghclient.UsageClient) Model {
+	m.usageClient = client
+	return m
+}
+
+func (m Model) doFetchUsage() tea.Cmd {
+	repos := m.repos
+	client := m.usageClient
+	ctx := m.ctx
+
+	return func() tea.Msg {
+		msg := usageMsg{at: time.Now()}
+		var errs, minutesErrs []error
+		for _, full := range repos {
+			parts := strings.SplitN(full, "/", 2)
+			u, err := client.GetCacheUsage(ctx, parts[0], parts[1])
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				msg.usage = append(msg.usage, u)
+			}
+			// Billing needs more access than the caches; without it only
+			// the minutes are missing.
+			minutes, err := client.GetBillableMinutes(ctx, parts[0], parts[1])
+			if err != nil {
+				minutesErrs = append(minutesErrs, err)
+				continue
+			}
+			msg.minutes = append(msg.minutes, minutes...)
+		}
+		msg.err = errors.Join(errs...)
+		msg.minutesErr = errors.Join(minutesErrs...)
+		return msg
+	}
+}
+
+func usageTick(id int) tea.Cmd {
+	return tea.Tick(usageInterval, func(time.Time) tea.Msg { return usageTickMsg{id: id} })
+}
+
+// toggleUsage switches between the workflow view and the usage panel. The
+// panel is refreshed on opening if its data is older than usageInterval.
+func (m *Model) toggleUsage() tea.Cmd {
+	if m.view == viewUsage {
+		m.view = viewWorkflows
+		return nil
+	}
+	if m.usageClient == nil {
+		m.notice = "the usage panel needs GITHUB_TOKEN"
+		return nil
+	}
+	m.view = viewUsage
+	m.usageTickID++
+	cmds := []tea.Cmd{usageTick(m.usageTickID)}
+	if !m.usageLoading && time.Since(m.usageFetched) >= usageInterval {
+		cmds = append(cmds, m.refreshUsage())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) refreshUsage() tea.Cmd {
+	m.usageLoading = true
+	m.resetProgress()
+	return m.doFetchUsage()
+}
+
+// updateUsage handles key presses in the usage panel.
+func (m Model) updateUsage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	caches := m.cacheRows()
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.refresh):
+		cmd = m.refreshUsage()
+	case key.Matches(msg, k.usage, k.back):
+		cmd = m.toggleUsage()
+	case key.Matches(msg, k.up):
+		m.setUsageCursor(m.usageCursor - 1)
+	case key.Matches(msg, k.down):
+		m.setUsageCursor(m.usageCursor + 1)
+	case key.Matches(msg, k.home):
+		m.setUsageCursor(0)
+	case key.Matches(msg, k.end):
+		m.setUsageCursor(len(caches) - 1)
+	case key.Matches(msg, k.deleteCache):
+		if m.usageCursor < len(caches) {
+			c := caches[m.usageCursor]
+			m.confirm = &confirmation{
+				prompt: fmt.Sprintf("delete cache %s from %s?", c.Key, c.repo),
+				action: m.deleteCache(c),
+			}
+		}
+	}
+	m.render()
+	return m, cmd
+}
+
+func (m Model) deleteCache(c cacheRow) tea.Cmd {
+	client := m.usageClient
+	ctx := m.ctx
+	return func() tea.Msg {
+		parts := strings.SplitN(c.repo, "/", 2)
+		return cacheDeletedMsg{key: c.Key, err: client.DeleteCache(ctx, parts[0], parts[1], c.ID)}
+	}
+}
+
+func (m *Model) setUsageCursor(i int) {
+	m.usageCursor = max(0, min(i, len(m.cacheRows())-1))
+}
+
+// cacheRows returns the listed caches of every repository, largest first.
+func (m Model) cacheRows() []cacheRow {
+	var rows []cacheRow
+	for _, u := range m.usage {
+		for _, c := range u.Largest {
+			rows = append(rows, cacheRow{repo: u.Repo, Cache: c})
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b cacheRow) int { return cmp.Compare(b.SizeInBytes, a.SizeInBytes) })
+	return rows
+}
+
+// usageSummary totals the caches of every repository for the header.
+func (m Model) usageSummary() string {
+	count, size := 0, int64(0)
+	for _, u := range m.usage {
+		count += u.Count
+		size += u.SizeInBytes
+	}
+	return fmt.Sprintf("%d caches, %s", count, formatSize(size))
+}
+
+// usageTableHeight is the number of lines above the cache table's rows.
+func (m Model) usageTableHeight() int {
+	return len(m.usage) + 3 // repository header, blank line and cache header
+}
+
+// formatMinutes renders billable time in whole minutes, rounding up as
+// GitHub bills.
+func formatMinutes(d time.Duration) string {
+	return fmt.Sprintf("%d min", int((d+time.Minute-1)/time.Minute))
+}
+
+func (m Model) usageContent() string {
+	if m.usage == nil {
+		if m.usageErr != nil {
+			return fmt.Sprintf("  Error: %v\n", m.usageErr)
+		}
+		return "  Fetching usage…\n"
+	}
+
+	repoW := 24
+	for _, u := range m.usage {
+		repoW = max(repoW, len(u.Repo)+2)
+	}
+	for _, w := range m.minutes {
+		repoW = max(repoW, len(w.Repo)+2)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(colHeaderStyle.Render(fmt.Sprintf("  %-*s  %-8s  %s", repoW, "REPOSITORY", "CACHES", "SIZE")))
+	sb.WriteByte('\n')
+	for _, u := range m.usage {
+		sb.WriteString(fmt.Sprintf("  %-*s  %-8d  %s\n", repoW, u.Repo, u.Count, formatSize(u.SizeInBytes)))
+	}
+
+	caches := m.cacheRows()
+	keyW, refW := 40, 24
+	sb.WriteByte('\n')
+	sb.WriteString(colHeaderStyle.Render(fmt.Sprintf("  %-*s  %-*s  %-*s  %-10s  %s", repoW, "REPOSITORY", keyW, "LARGEST CACHES",
+		refW, "REF", "SIZE", "LAST USED")))
+	sb.WriteByte('\n')
+	if len(caches) == 0 {
+		sb.WriteString("  No caches.\n")
+	}
+	now := time.Now()
+	for i, c := range caches {
+		rowStyle := lipgloss.NewStyle()
+		if i == m.usageCursor {
+			rowStyle = selectedRowStyle
+		}
+		sb.WriteString(rowStyle.Render(fmt.Sprintf("  %-*s  %-*s  %-*s  %-10s  %s", repoW, c.repo, keyW, truncate(c.Key, keyW),
+			refW, truncate(c.Ref, refW), formatSize(c.SizeInBytes), formatAge(c.LastAccessedAt, now))))
+		sb.WriteByte('\n')
+	}
+
+	sb.WriteByte('\n')
+	wfW := 30
+	sb.WriteString(colHeaderStyle.Render(fmt.Sprintf("  %-*s  %-*s  %-10s  %s", repoW, "REPOSITORY", wfW, "BILLABLE THIS CYCLE",
+		"TOTAL", "BY RUNNER OS")))
+	sb.WriteByte('\n')
+	if len(m.minutes) == 0 && m.minutesErr == nil {
+		sb.WriteString("  No billable minutes.\n")
+	}
+	for _, w := range m.minutes {
+		var byOS []string
+		for runnerOS, d := range w.ByOS {
+			byOS = append(byOS, runnerOS+" "+formatMinutes(d))
+		}
+		slices.Sort(byOS)
+		sb.WriteString(fmt.Sprintf("  %-*s  %-*s  %-10s  %s\n", repoW, w.Repo, wfW, truncate(w.Workflow, wfW),
+			formatMinutes(w.Total()), strings.Join(byOS, ", ")))
+	}
+	if m.minutesErr != nil {
+		sb.WriteString(fmt.Sprintf("  Billable minutes unavailable: %v\n", m.minutesErr))
+	}
+	if m.usageErr != nil {
+		sb.WriteString(fmt.Sprintf("\n  Error: %v\n", m.usageErr))
+	}
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	ghclient "ghamon/internal/github"
+)
+
+type workflowStateMsg struct {
+	run     ghclient.WorkflowRun
+	enabled bool
+	err     error
+}
+
+// WithWorkflows returns a copy of m that can enable and disable workflows
+// through client.
+func (m Model) WithWorkflows(client ghclient.WorkflowClient) Model {
+	m.workflowClient = client
+	return m
+}
+
+// toggleWorkflow asks to disable the workflow of the selected run, or to
+// enable it if it is disabled.
+func (m *Model) toggleWorkflow() {
+	if m.workflowClient == nil {
+		m.notice = "enabling and disabling workflows needs GITHUB_TOKEN"
+		return
+	}
+	r, ok := m.selectedRun()
+	if !ok || r.WorkflowID == 0 {
+		m.notice = "select a workflow to enable or disable it"
+		return
+	}
+	enable := r.Disabled()
+	m.confirm = &confirmation{
+		prompt: fmt.Sprintf("%s workflow %s in %s?", enableVerb(enable), r.Workflow, r.Repo),
+		action: m.setWorkflowEnabled(r, enable),
+	}
+}
+
+func (m Model) setWorkflowEnabled(r ghclient.WorkflowRun, enabled bool) tea.Cmd {
+	client := m.workflowClient
+	ctx := m.ctx
+	return func() tea.Msg {
+		parts := strings.SplitN(r.Repo, "/", 2)
+		err := client.SetWorkflowEnabled(ctx, parts[0], parts[1], r.WorkflowID, enabled)
+		return workflowStateMsg{run: r, enabled: enabled, err: err}
+	}
+}
+
+// updateWorkflowState reports the outcome of enabling or disabling a workflow
+// and, on success, refreshes to show its new state.
+func (m *Model) updateWorkflowState(msg workflowStateMsg) tea.Cmd {
+	if msg.err != nil {
+		m.notice = fmt.Sprintf("could not %s %s: %v", enableVerb(msg.enabled), msg.run.Workflow, msg.err)
+		return nil
+	}
+	m.notice = fmt.Sprintf("%sd %s in %s", enableVerb(msg.enabled), msg.run.Workflow, msg.run.Repo)
+	m.loading = true
+	m.resetProgress()
+	return m.doFetch()
+}
+
+func enableVerb(enable bool) string {
+	if enable {
+		return "enable"
+	}
+	return "disable"
+}
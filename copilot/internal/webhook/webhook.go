@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	gogithub "github.com/google/go-github/v68/github"
+
+	ghclient "ghamon/internal/github"
+)
+
+// maxPayloadSize is the largest delivery GitHub sends (25 MB).
+const maxPayloadSize = 25 << 20
+
+// Event is a workflow status change received from a webhook delivery.
+type Event struct {
+	// Run holds the new state of the workflow run.
+	Run ghclient.WorkflowRun
+	// WorkflowFile is the workflow's file name (e.g. "ci.yml"), when known.
+	WorkflowFile string
+}
+
+// Handler receives GitHub webhook deliveries and passes workflow_run and
+// workflow_job events to a sink. Deliveries must be signed with the shared
+// secret in the X-Hub-Signature-256 header.
+type Handler struct {
+	secret []byte
+	sink   func(Event)
+}
+
+// NewHandler creates a Handler that verifies deliveries against secret and
+// calls sink for each workflow event.
+func NewHandler(secret string, sink func(Event)) *Handler {
+	return &Handler{secret: []byte(secret), sink: sink}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+
+	sig := r.Header.Get(gogithub.SHA256SignatureHeader)
+	if sig == "" {
+		http.Error(w, "missing "+gogithub.SHA256SignatureHeader, http.StatusUnauthorized)
+		return
+	}
+	if err := gogithub.ValidateSignature(sig, body, h.secret); err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	ev, ok, err := Parse(gogithub.WebHookType(r), body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ok {
+		h.sink(ev)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Parse converts a webhook payload into an Event. It reports false for event
+// types that do not affect workflow status.
+func Parse(eventType string, payload []byte) (Event, bool, error) {
+	switch eventType {
+	case "workflow_run", "workflow_job":
+	default:
+		return Event{}, false, nil
+	}
+
+	raw, err := gogithub.ParseWebHook(eventType, payload)
+	if err != nil {
+		return Event{}, false, fmt.Errorf("parsing %s payload: %w", eventType, err)
+	}
+
+	switch e := raw.(type) {
+	case *gogithub.WorkflowRunEvent:
+		return fromWorkflowRun(e)
+	case *gogithub.WorkflowJobEvent:
+		return fromWorkflowJob(e)
+	}
+	return Event{}, false, nil
+}
+
+func fromWorkflowRun(e *gogithub.WorkflowRunEvent) (Event, bool, error) {
+	r := e.GetWorkflowRun()
+	if r == nil || e.GetRepo().GetFullName() == "" {
+		return Event{}, false, fmt.Errorf("workflow_run payload missing run or repository")
+	}
+	name := r.GetName()
+	if name == "" {
+		name = e.GetWorkflow().GetName()
+	}
+	ev := Event{
+		Run: ghclient.WorkflowRun{
+			ID:         r.GetID(),
+			Repo:       e.GetRepo().GetFullName(),
+			Workflow:   name,
+			Status:     r.GetStatus(),
+			Conclusion: r.GetConclusion(),
+			CreatedAt:  r.GetCreatedAt().Time,
+			StartedAt:  r.GetRunStartedAt().Time,
+			UpdatedAt:  r.GetUpdatedAt().Time,
+			URL:        r.GetHTMLURL(),
+			HeadBranch: r.GetHeadBranch(),
+			HeadSHA:    r.GetHeadSHA(),
+			Actor:      r.GetActor().GetLogin(),
+
+			DefaultBranch: e.GetRepo().GetDefaultBranch(),
+		},
+	}
+	if p := r.GetPath(); p != "" {
+		ev.WorkflowFile = path.Base(p)
+	}
+	return ev, true, nil
+}
+
+// fromWorkflowJob maps a job event onto its run. A job being queued or picked
+// up means the run is queued or in progress; completed jobs are ignored since
+// the run's own conclusion arrives in a workflow_run delivery.
+func fromWorkflowJob(e *gogithub.WorkflowJobEvent) (Event, bool, error) {
+	j := e.GetWorkflowJob()
+	if j == nil || e.GetRepo().GetFullName() == "" {
+		return Event{}, false, fmt.Errorf("workflow_job payload missing job or repository")
+	}
+	if j.GetStatus() == "completed" {
+		return Event{}, false, nil
+	}
+	updated := j.GetStartedAt().Time
+	if updated.IsZero() {
+		updated = j.GetCreatedAt().Time
+	}
+	return Event{
+		Run: ghclient.WorkflowRun{
+			ID:         j.GetRunID(),
+			Repo:       e.GetRepo().GetFullName(),
+			Workflow:   j.GetWorkflowName(),
+			Status:     j.GetStatus(),
+			UpdatedAt:  updated,
+			URL:        j.GetHTMLURL(),
+			HeadBranch: j.GetHeadBranch(),
+			HeadSHA:    j.GetHeadSHA(),
+
+			DefaultBranch: e.GetRepo().GetDefaultBranch(),
+		},
+	}, true, nil
+}
+
+// Sign returns the X-Hub-Signature-256 value for payload.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// EventType guesses the X-GitHub-Event type of a recorded payload.
+func EventType(payload []byte) string {
+	var probe struct {
+		WorkflowJob json.RawMessage `json:"workflow_job"`
+		WorkflowRun json.RawMessage `json:"workflow_run"`
+	}
+	if json.Unmarshal(payload, &probe) != nil {
+		return ""
+	}
+	switch {
+	case probe.WorkflowJob != nil:
+		return "workflow_job"
+	case probe.WorkflowRun != nil:
+		return "workflow_run"
+	}
+	return ""
+}
+
+// Replay signs a recorded payload and delivers it to a webhook listener, as
+// GitHub would.
+func Replay(ctx context.Context, url, secret string, payload []byte) error {
+	eventType := EventType(payload)
+	if eventType == "" {
+		return fmt.Errorf("payload is not a workflow_run or workflow_job delivery")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(gogithub.EventTypeHeader, eventType)
+	req.Header.Set(gogithub.SHA256SignatureHeader, Sign(secret, payload))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("delivering %s: %w", eventType, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("listener returned %d for %s", resp.StatusCode, eventType)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ghamon/internal/webhook"
+)
+
+const testSecret = "It's a Secret to Everybody"
+
+// recorder collects the events delivered to a Handler.
+type recorder struct {
+	mu     sync.Mutex
+	events []webhook.Event
+}
+
+func (r *recorder) sink(ev webhook.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func loadPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return b
+}
+
+func TestSign(t *testing.T) {
+	// Example from GitHub's "Validating webhook deliveries" documentation.
+	assert.Equal(t,
+		"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+		webhook.Sign(testSecret, []byte("Hello, World!")))
+}
+
+func TestReplay_RecordedPayloads(t *testing.T) {
+	tests := []struct {
+		file       string
+		repo       string
+		workflow   string
+		wfFile     string
+		status     string
+		conclusion string
+		sha        string
+		actor      string
+	}{
+		{"workflow_run_completed.json", "octo-org/octo-repo", "CI", "ci.yml", "completed", "failure", "9f2c1d7e4b3a8c6d5e0f1a2b3c4d5e6f7a8b9c0d", "octocat"},
+		{"workflow_run_requested.json", "octo-org/octo-repo", "Deploy", "deploy.yml", "queued", "", "1a2b3c4d5e6f7a8b9c0d9f2c1d7e4b3a8c6d5e0f", "hubot"},
+		{"workflow_job_in_progress.json", "octo-org/octo-repo", "Deploy", "", "in_progress", "", "1a2b3c4d5e6f7a8b9c0d9f2c1d7e4b3a8c6d5e0f", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			rec := &recorder{}
+			srv := httptest.NewServer(webhook.NewHandler(testSecret, rec.sink))
+			defer srv.Close()
+
+			require.NoError(t, webhook.Replay(context.Background(), srv.URL, testSecret, loadPayload(t, tt.file)))
+			require.Len(t, rec.events, 1)
+			ev := rec.events[0]
+			assert.Equal(t, tt.repo, ev.Run.Repo)
+			assert.Equal(t, tt.workflow, ev.Run.Workflow)
+			assert.Equal(t, tt.wfFile, ev.WorkflowFile)
+			assert.Equal(t, tt.status, ev.Run.Status)
+			assert.Equal(t, tt.conclusion, ev.Run.Conclusion)
+			assert.Equal(t, "main", ev.Run.HeadBranch)
+			assert.Equal(t, "main", ev.Run.DefaultBranch)
+			assert.Equal(t, tt.sha, ev.Run.HeadSHA)
+			assert.Equal(t, tt.actor, ev.Run.Actor)
+			assert.False(t, ev.Run.UpdatedAt.IsZero())
+		})
+	}
+}
+
+func TestReplay_CompletedJobIgnored(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(webhook.NewHandler(testSecret, rec.sink))
+	defer srv.Close()
+
+	require.NoError(t, webhook.Replay(context.Background(), srv.URL, testSecret, loadPayload(t, "workflow_job_completed.json")))
+	assert.Empty(t, rec.events)
+}
+
+func TestHandler_RejectsBadSignature(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(webhook.NewHandler(testSecret, rec.sink))
+	defer srv.Close()
+
+	err := webhook.Replay(context.Background(), srv.URL, "wrong secret", loadPayload(t, "workflow_run_completed.json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Empty(t, rec.events)
+}
+
+func TestHandler_RejectsMissingSignature(t *testing.T) {
+	rec := &recorder{}
+	h := webhook.NewHandler(testSecret, rec.sink)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(loadPayload(t, "workflow_run_completed.json")))
+	req.Header.Set("X-GitHub-Event", "workflow_run")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, rec.events)
+}
+
+func TestHandler_IgnoresOtherEvents(t *testing.T) {
+	rec := &recorder{}
+	h := webhook.NewHandler(testSecret, rec.sink)
+
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "ping")
+	req.Header.Set("X-Hub-Signature-256", webhook.Sign(testSecret, body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, rec.events)
+}
+
+func TestHandler_RejectsGet(t *testing.T) {
+	h := webhook.NewHandler(testSecret, func(webhook.Event) {})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestEventType(t *testing.T) {
+	assert.Equal(t, "workflow_run", webhook.EventType(loadPayload(t, "workflow_run_completed.json")))
+	assert.Equal(t, "workflow_job", webhook.EventType(loadPayload(t, "workflow_job_in_progress.json")))
+	assert.Equal(t, "", webhook.EventType([]byte(`{"zen":"hi"}`)))
+}
@@ -1,19 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	flag "github.com/spf13/pflag"
 
 	"ghamon/internal/config"
+	"ghamon/internal/daemon"
 	ghclient "ghamon/internal/github"
 	"ghamon/internal/tui"
+	"ghamon/internal/webhook"
 )
 
-const defaultRate = 30
+const (
+	defaultRate      = 30
+	defaultReconcile = 300
+)
 
 func main() {
 	if err := run(os.Args[1:]); err != nil {
@@ -23,19 +35,39 @@ func main() {
 }
 
 func run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "daemon":
+			return runDaemon(args[1:])
+		case "replay":
+			return runReplay(args[1:])
+		}
+	}
+
 	fs := flag.NewFlagSet("ghamon", flag.ContinueOnError)
 	fs.SetOutput(os.Stdout)
 
 	var (
-		configPath string
-		rate       int
-		workflow   string
-		showHelp   bool
+		configPath  string
+		rate        int
+		workflow    string
+		socketPath  string
+		webhookAddr string
+		reconcile   int
+		history     int
+		themeName   string
+		showHelp    bool
 	)
 
 	fs.StringVarP(&configPath, "config", "c", config.DefaultConfigPath(), "Path to configuration file")
 	fs.IntVarP(&rate, "rate", "r", defaultRate, "Refresh rate in seconds")
 	fs.StringVarP(&workflow, "workflow", "w", "", "GitHub Actions workflow to monitor (default: all workflows)")
+	fs.StringVar(&socketPath, "socket", daemon.DefaultSocketPath(), "Daemon socket to use if a daemon is running")
+	fs.StringVar(&webhookAddr, "webhook-addr", "", "Listen for GitHub webhook deliveries on this address (e.g. :8080)")
+	fs.IntVar(&reconcile, "reconcile", defaultReconcile, "Refresh rate in seconds when receiving webhooks")
+	fs.IntVar(&history, "history", ghclient.DefaultHistory, "Number of recent runs shown in the status history (0 to hide)")
+	fs.StringVar(&themeName, "theme", "", fmt.Sprintf("Colour theme: %s or a file in ~/.ghamon/themes (default: %s)",
+		strings.Join(config.ThemeNames(), ", "), config.DefaultTheme))
 	fs.BoolVarP(&showHelp, "help", "h", false, "Show help message and exit")
 
 	if err := fs.Parse(args); err != nil {
@@ -47,6 +79,15 @@ func run(args []string) error {
 		return nil
 	}
 
+	secret := os.Getenv("GHAMON_WEBHOOK_SECRET")
+	if webhookAddr != "" {
+		if secret == "" {
+			return fmt.Errorf("GHAMON_WEBHOOK_SECRET environment variable must be set with --webhook-addr")
+		}
+		// Deliveries keep the view current; polling only reconciles missed ones.
+		rate = reconcile
+	}
+
 	cfgRepos, err := config.Load(configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not load config: %v\n", err)
@@ -54,6 +95,224 @@ func run(args []string) error {
 
 	repos := dedupe(append(cfgRepos, fs.Args()...))
 
+	if rate < 1 {
+		rate = defaultRate
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Prefer a running daemon; fall back to polling GitHub directly.
+	var client ghclient.Client
+	token := os.Getenv("GITHUB_TOKEN")
+	var dc *daemon.Client
+	if socketPath != "" {
+		dc, _ = daemon.Dial(ctx, socketPath)
+	}
+	if dc != nil {
+		client = dc
+	} else {
+		if token == "" {
+			return fmt.Errorf("GITHUB_TOKEN environment variable is not set")
+		}
+		client = ghclient.New(token, history)
+	}
+	// Enabling or disabling a workflow through the polling client itself
+	// shows the new state on the next refresh rather than when the cached
+	// one expires.
+	workflows, ok := client.(ghclient.WorkflowClient)
+	if !ok && token != "" {
+		workflows = ghclient.NewWorkflowClient(token)
+	}
+
+	settingsPath := config.DefaultSettingsPath()
+	settings := config.DefaultSettings()
+	if settingsPath == "" {
+		fmt.Fprintln(os.Stderr, "Warning: no home directory; settings will not be remembered")
+	} else if settings, err = config.LoadSettings(settingsPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load settings: %v\n", err)
+	}
+
+	if themeName == "" {
+		themeName = settings.Theme
+	}
+	theme, err := config.LoadTheme(config.DefaultThemesDir(), themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		theme = config.NoColorTheme()
+	}
+	tui.SetTheme(theme)
+
+	keyCfg, err := config.LoadKeys(config.DefaultKeysPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	keys, err := tui.NewKeyMap(keyCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	lastRunsPath := config.DefaultLastRunsPath()
+	lastRuns, err := config.LoadLastRuns(lastRunsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// The runs of the last session are shown until fetched again, and
+	// requests still in flight when the program ends are cancelled.
+	model := tui.New(repos, workflow, rate, client).WithSettings(settings).WithKeys(keys).WithHistory(history).
+		WithLastRuns(lastRunsPath, lastRuns).WithContext(ctx)
+	if token != "" {
+		// Pull requests, runners and deployments are not served by the
+		// daemon and need a token.
+		model = model.WithPullRequests(ghclient.NewPullRequestClient(token)).
+			WithRunners(ghclient.NewRunnerClient(token)).
+			WithDeployments(ghclient.NewDeploymentClient(token)).
+			WithArtifacts(ghclient.NewArtifactClient(token)).
+			WithFailures(ghclient.NewFailureClient(token)).
+			WithUsage(ghclient.NewUsageClient(token)).
+			WithWorkflows(workflows)
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+
+	if dc != nil {
+		events, err := dc.Subscribe(ctx, repos, workflow)
+		if err == nil {
+			go func() {
+				for range events {
+					p.Send(tui.RefreshMsg{})
+				}
+			}()
+		}
+	}
+
+	listenErr := make(chan error, 1)
+	if webhookAddr != "" {
+		var runWorkflows ghclient.RunWorkflowClient
+		if token != "" {
+			runWorkflows = ghclient.NewRunWorkflowClient(token)
+		}
+		srv := &http.Server{
+			Addr: webhookAddr,
+			Handler: webhook.NewHandler(secret, func(ev webhook.Event) {
+				if ev.WorkflowFile == "" && workflow != "" && runWorkflows != nil {
+					// Job deliveries name their workflow but not its file.
+					owner, repo, _ := strings.Cut(ev.Run.Repo, "/")
+					ev.WorkflowFile, _ = runWorkflows.WorkflowFile(ctx, owner, repo, ev.Run.ID)
+				}
+				p.Send(tui.RunUpdateMsg{Run: ev.Run, WorkflowFile: ev.WorkflowFile})
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				listenErr <- err
+				p.Quit()
+			}
+		}()
+		defer srv.Close()
+	}
+
+	final, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("running TUI: %w", err)
+	}
+	if fm, ok := final.(tui.Model); ok && settingsPath != "" {
+		if err := config.SaveSettings(settingsPath, fm.Settings()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save settings: %v\n", err)
+		}
+	}
+	select {
+	case err := <-listenErr:
+		return fmt.Errorf("webhook listener: %w", err)
+	default:
+		return nil
+	}
+}
+
+// runReplay delivers recorded webhook payloads to a running listener.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("ghamon replay", flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+
+	var (
+		url      string
+		showHelp bool
+	)
+
+	fs.StringVar(&url, "url", "http://localhost:8080/", "Webhook listener URL")
+	fs.BoolVarP(&showHelp, "help", "h", false, "Show help message and exit")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if showHelp || fs.NArg() == 0 {
+		fmt.Println("Usage: ghamon replay [options] payload.json...")
+		fmt.Println()
+		fmt.Println("Signs recorded workflow_run/workflow_job payloads with GHAMON_WEBHOOK_SECRET")
+		fmt.Println("and delivers them to a ghamon webhook listener.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		return nil
+	}
+
+	secret := os.Getenv("GHAMON_WEBHOOK_SECRET")
+	if secret == "" {
+		return fmt.Errorf("GHAMON_WEBHOOK_SECRET environment variable is not set")
+	}
+
+	for _, path := range fs.Args() {
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := webhook.Replay(context.Background(), url, secret, payload); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Println("delivered", path)
+	}
+	return nil
+}
+
+// runDaemon runs the shared polling daemon until interrupted.
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("ghamon daemon", flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+
+	var (
+		rate        int
+		socketPath  string
+		socketGroup string
+		socketMode  string
+		history     int
+		showHelp    bool
+	)
+
+	fs.IntVarP(&rate, "rate", "r", defaultRate, "Refresh rate in seconds")
+	fs.StringVar(&socketPath, "socket", daemon.DefaultSocketPath(), "Path of the Unix socket to listen on")
+	fs.StringVar(&socketGroup, "socket-group", "", "Group allowed to connect to the socket, e.g. to share the daemon with a team")
+	fs.StringVar(&socketMode, "socket-mode", "", "Octal permissions of the socket (default 600, or 660 with --socket-group)")
+	fs.IntVar(&history, "history", ghclient.DefaultHistory, "Number of recent runs kept in the status history")
+	fs.BoolVarP(&showHelp, "help", "h", false, "Show help message and exit")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if showHelp {
+		fmt.Println("Usage: ghamon daemon [options]")
+		fmt.Println()
+		fmt.Println("Polls GitHub on behalf of ghamon clients and serves cached results over a Unix socket.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		return nil
+	}
+
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
 		return fmt.Errorf("GITHUB_TOKEN environment variable is not set")
@@ -63,24 +322,45 @@ func run(args []string) error {
 		rate = defaultRate
 	}
 
-	client := ghclient.New(token)
-	model := tui.New(repos, workflow, rate, client)
+	access := daemon.SocketAccess{Group: socketGroup}
+	if socketMode != "" {
+		mode, err := strconv.ParseUint(socketMode, 8, 32)
+		if err != nil || mode > 0o777 || mode&0o600 != 0o600 {
+			return fmt.Errorf("invalid --socket-mode %q: want octal permissions such as 660 that let the owner read and write", socketMode)
+		}
+		access.Mode = os.FileMode(mode)
+	}
 
-	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		return fmt.Errorf("running TUI: %w", err)
+	ln, err := daemon.Listen(socketPath, access)
+	if err != nil {
+		return err
 	}
-	return nil
+	defer os.Remove(socketPath)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := daemon.NewServer(ghclient.New(token, history), time.Duration(rate)*time.Second)
+	fmt.Printf("ghamon daemon listening on %s (refresh %ds)\n", socketPath, rate)
+	if socketGroup != "" || socketMode != "" {
+		fmt.Println("Everyone allowed to connect sees runs fetched with this GITHUB_TOKEN.")
+	}
+	return srv.Serve(ctx, ln)
 }
 
 func printUsage(fs *flag.FlagSet) {
 	fmt.Println("Usage: ghamon [options] [repo]...")
+	fmt.Println("       ghamon daemon [options]")
 	fmt.Println()
 	fmt.Println("GHA Monitor monitors GitHub Actions workflows for one or more repositories.")
 	fmt.Println()
 	fmt.Println("Arguments:")
 	fmt.Println("  repo    GitHub repository in the format owner/repo (multiple allowed)")
 	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  daemon  Poll GitHub once for all local ghamon clients (see ghamon daemon --help)")
+	fmt.Println("  replay  Deliver recorded webhook payloads to a listener (see ghamon replay --help)")
+	fmt.Println()
 	fmt.Println("Options:")
 	fs.PrintDefaults()
 }